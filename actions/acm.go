@@ -0,0 +1,19 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// AWS Certificate Manager actions
+const (
+	ACMAll                  = "acm:*"
+	ACMAddTagsToCertificate = "acm:AddTagsToCertificate"
+	ACMDeleteCertificate    = "acm:DeleteCertificate"
+	ACMDescribeCertificate  = "acm:DescribeCertificate"
+	ACMGetCertificate       = "acm:GetCertificate"
+	ACMListCertificates     = "acm:ListCertificates"
+	ACMRequestCertificate   = "acm:RequestCertificate"
+)
@@ -0,0 +1,20 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// Amazon Athena actions
+const (
+	AthenaAll                    = "athena:*"
+	AthenaBatchGetQueryExecution = "athena:BatchGetQueryExecution"
+	AthenaGetQueryExecution      = "athena:GetQueryExecution"
+	AthenaGetQueryResults        = "athena:GetQueryResults"
+	AthenaGetWorkGroup           = "athena:GetWorkGroup"
+	AthenaListQueryExecutions    = "athena:ListQueryExecutions"
+	AthenaStartQueryExecution    = "athena:StartQueryExecution"
+	AthenaStopQueryExecution     = "athena:StopQueryExecution"
+)
@@ -0,0 +1,18 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package actions defines constants for commonly used IAM actions, so typos
+// in action names are caught by the compiler. The constants are untyped and
+// can be passed anywhere an action string is expected, e.g.
+//
+//	stmt.AddAction(actions.S3GetObject)
+//
+// Constants are named after the service prefix and the action name.
+package actions
+
+// All matches every action of every service
+const All = "*"
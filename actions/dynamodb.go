@@ -0,0 +1,28 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// Amazon DynamoDB actions
+const (
+	DynamoDBAll                      = "dynamodb:*"
+	DynamoDBBatchGetItem             = "dynamodb:BatchGetItem"
+	DynamoDBBatchWriteItem           = "dynamodb:BatchWriteItem"
+	DynamoDBConditionCheckItem       = "dynamodb:ConditionCheckItem"
+	DynamoDBDeleteItem               = "dynamodb:DeleteItem"
+	DynamoDBDescribeTable            = "dynamodb:DescribeTable"
+	DynamoDBExportTableToPointInTime = "dynamodb:ExportTableToPointInTime"
+	DynamoDBGetItem                  = "dynamodb:GetItem"
+	DynamoDBGetRecords               = "dynamodb:GetRecords"
+	DynamoDBPartiQLDelete            = "dynamodb:PartiQLDelete"
+	DynamoDBPartiQLSelect            = "dynamodb:PartiQLSelect"
+	DynamoDBPartiQLUpdate            = "dynamodb:PartiQLUpdate"
+	DynamoDBPutItem                  = "dynamodb:PutItem"
+	DynamoDBQuery                    = "dynamodb:Query"
+	DynamoDBScan                     = "dynamodb:Scan"
+	DynamoDBUpdateItem               = "dynamodb:UpdateItem"
+)
@@ -0,0 +1,21 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// Amazon EC2 actions
+const (
+	EC2All                           = "ec2:*"
+	EC2AuthorizeSecurityGroupIngress = "ec2:AuthorizeSecurityGroupIngress"
+	EC2CreateTags                    = "ec2:CreateTags"
+	EC2DescribeInstances             = "ec2:DescribeInstances"
+	EC2DescribeSecurityGroups        = "ec2:DescribeSecurityGroups"
+	EC2RunInstances                  = "ec2:RunInstances"
+	EC2StartInstances                = "ec2:StartInstances"
+	EC2StopInstances                 = "ec2:StopInstances"
+	EC2TerminateInstances            = "ec2:TerminateInstances"
+)
@@ -0,0 +1,20 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// AWS Glue actions
+const (
+	GlueAll               = "glue:*"
+	GlueBatchGetPartition = "glue:BatchGetPartition"
+	GlueGetDatabase       = "glue:GetDatabase"
+	GlueGetDatabases      = "glue:GetDatabases"
+	GlueGetPartition      = "glue:GetPartition"
+	GlueGetPartitions     = "glue:GetPartitions"
+	GlueGetTable          = "glue:GetTable"
+	GlueGetTables         = "glue:GetTables"
+)
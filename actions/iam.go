@@ -0,0 +1,26 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// AWS IAM actions
+const (
+	IAMAll                           = "iam:*"
+	IAMAttachRolePolicy              = "iam:AttachRolePolicy"
+	IAMCreateAccessKey               = "iam:CreateAccessKey"
+	IAMCreatePolicyVersion           = "iam:CreatePolicyVersion"
+	IAMCreateRole                    = "iam:CreateRole"
+	IAMCreateUser                    = "iam:CreateUser"
+	IAMDeleteRole                    = "iam:DeleteRole"
+	IAMDeleteRolePermissionsBoundary = "iam:DeleteRolePermissionsBoundary"
+	IAMGetRole                       = "iam:GetRole"
+	IAMListRoles                     = "iam:ListRoles"
+	IAMPassRole                      = "iam:PassRole"
+	IAMPutRolePermissionsBoundary    = "iam:PutRolePermissionsBoundary"
+	IAMPutRolePolicy                 = "iam:PutRolePolicy"
+	IAMUpdateAssumeRolePolicy        = "iam:UpdateAssumeRolePolicy"
+)
@@ -0,0 +1,27 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// AWS KMS actions
+const (
+	KMSAll                             = "kms:*"
+	KMSCreateGrant                     = "kms:CreateGrant"
+	KMSDecrypt                         = "kms:Decrypt"
+	KMSDescribeKey                     = "kms:DescribeKey"
+	KMSEncrypt                         = "kms:Encrypt"
+	KMSGenerateDataKey                 = "kms:GenerateDataKey"
+	KMSGenerateDataKeyWithoutPlaintext = "kms:GenerateDataKeyWithoutPlaintext"
+	KMSListGrants                      = "kms:ListGrants"
+	KMSReEncryptFrom                   = "kms:ReEncryptFrom"
+	KMSReEncryptTo                     = "kms:ReEncryptTo"
+	KMSRetireGrant                     = "kms:RetireGrant"
+	KMSRevokeGrant                     = "kms:RevokeGrant"
+	KMSScheduleKeyDeletion             = "kms:ScheduleKeyDeletion"
+	KMSSign                            = "kms:Sign"
+	KMSVerify                          = "kms:Verify"
+)
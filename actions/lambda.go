@@ -0,0 +1,19 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// AWS Lambda actions
+const (
+	LambdaAll                = "lambda:*"
+	LambdaAddPermission      = "lambda:AddPermission"
+	LambdaCreateFunction     = "lambda:CreateFunction"
+	LambdaGetFunction        = "lambda:GetFunction"
+	LambdaInvokeFunction     = "lambda:InvokeFunction"
+	LambdaInvokeFunctionUrl  = "lambda:InvokeFunctionUrl"
+	LambdaUpdateFunctionCode = "lambda:UpdateFunctionCode"
+)
@@ -0,0 +1,17 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// Amazon CloudWatch Logs actions
+const (
+	LogsAll                = "logs:*"
+	LogsCreateLogGroup     = "logs:CreateLogGroup"
+	LogsCreateLogStream    = "logs:CreateLogStream"
+	LogsDescribeLogStreams = "logs:DescribeLogStreams"
+	LogsPutLogEvents       = "logs:PutLogEvents"
+)
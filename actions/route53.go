@@ -0,0 +1,18 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// Amazon Route 53 actions
+const (
+	Route53All                      = "route53:*"
+	Route53ChangeResourceRecordSets = "route53:ChangeResourceRecordSets"
+	Route53GetChange                = "route53:GetChange"
+	Route53ListHostedZones          = "route53:ListHostedZones"
+	Route53ListHostedZonesByName    = "route53:ListHostedZonesByName"
+	Route53ListResourceRecordSets   = "route53:ListResourceRecordSets"
+)
@@ -0,0 +1,41 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// Amazon S3 actions
+const (
+	S3All                        = "s3:*"
+	S3AbortMultipartUpload       = "s3:AbortMultipartUpload"
+	S3BypassGovernanceRetention  = "s3:BypassGovernanceRetention"
+	S3CreateBucket               = "s3:CreateBucket"
+	S3DeleteBucket               = "s3:DeleteBucket"
+	S3DeleteBucketPolicy         = "s3:DeleteBucketPolicy"
+	S3DeleteObject               = "s3:DeleteObject"
+	S3DeleteObjectVersion        = "s3:DeleteObjectVersion"
+	S3GetBucketLocation          = "s3:GetBucketLocation"
+	S3GetBucketPolicy            = "s3:GetBucketPolicy"
+	S3GetBucketVersioning        = "s3:GetBucketVersioning"
+	S3GetObject                  = "s3:GetObject"
+	S3GetObjectAcl               = "s3:GetObjectAcl"
+	S3GetObjectRetention         = "s3:GetObjectRetention"
+	S3GetObjectTagging           = "s3:GetObjectTagging"
+	S3GetObjectVersion           = "s3:GetObjectVersion"
+	S3ListAllMyBuckets           = "s3:ListAllMyBuckets"
+	S3ListBucket                 = "s3:ListBucket"
+	S3ListBucketMultipartUploads = "s3:ListBucketMultipartUploads"
+	S3ListBucketVersions         = "s3:ListBucketVersions"
+	S3ListMultipartUploadParts   = "s3:ListMultipartUploadParts"
+	S3PutBucketPolicy            = "s3:PutBucketPolicy"
+	S3PutBucketVersioning        = "s3:PutBucketVersioning"
+	S3PutObject                  = "s3:PutObject"
+	S3PutObjectAcl               = "s3:PutObjectAcl"
+	S3PutObjectLegalHold         = "s3:PutObjectLegalHold"
+	S3PutObjectRetention         = "s3:PutObjectRetention"
+	S3PutObjectTagging           = "s3:PutObjectTagging"
+	S3RestoreObject              = "s3:RestoreObject"
+)
@@ -0,0 +1,18 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// Amazon SNS actions
+const (
+	SNSAll                = "sns:*"
+	SNSGetTopicAttributes = "sns:GetTopicAttributes"
+	SNSPublish            = "sns:Publish"
+	SNSSetTopicAttributes = "sns:SetTopicAttributes"
+	SNSSubscribe          = "sns:Subscribe"
+	SNSUnsubscribe        = "sns:Unsubscribe"
+)
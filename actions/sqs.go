@@ -0,0 +1,20 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// Amazon SQS actions
+const (
+	SQSAll                     = "sqs:*"
+	SQSChangeMessageVisibility = "sqs:ChangeMessageVisibility"
+	SQSDeleteMessage           = "sqs:DeleteMessage"
+	SQSGetQueueAttributes      = "sqs:GetQueueAttributes"
+	SQSGetQueueUrl             = "sqs:GetQueueUrl"
+	SQSPurgeQueue              = "sqs:PurgeQueue"
+	SQSReceiveMessage          = "sqs:ReceiveMessage"
+	SQSSendMessage             = "sqs:SendMessage"
+)
@@ -0,0 +1,21 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package actions
+
+// AWS STS actions
+const (
+	STSAll                       = "sts:*"
+	STSAssumeRole                = "sts:AssumeRole"
+	STSAssumeRoleWithSAML        = "sts:AssumeRoleWithSAML"
+	STSAssumeRoleWithWebIdentity = "sts:AssumeRoleWithWebIdentity"
+	STSGetCallerIdentity         = "sts:GetCallerIdentity"
+	STSGetFederationToken        = "sts:GetFederationToken"
+	STSGetSessionToken           = "sts:GetSessionToken"
+	STSSetSourceIdentity         = "sts:SetSourceIdentity"
+	STSTagSession                = "sts:TagSession"
+)
@@ -0,0 +1,219 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package athenapolicy generates the policy a role needs to query Glue
+// catalog tables with Athena. A query touches Athena, the Glue catalog, the
+// S3 locations of the data and the query results and the KMS keys encrypting
+// them, a grant missing in any of these makes the query fail.
+package athenapolicy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+var (
+	// ErrNoDatabase is returned when a query policy is requested without a
+	// Glue database
+	ErrNoDatabase = errors.New("No Glue database given")
+	// ErrNoDataLocation is returned when a query policy is requested without
+	// the S3 location of the table data
+	ErrNoDataLocation = errors.New("No data location given")
+	// ErrNoResultsLocation is returned when a query policy is requested
+	// without the S3 location for query results
+	ErrNoResultsLocation = errors.New("No query results location given")
+)
+
+// Validation error when a location is not an S3 URL
+type InvalidLocationError string
+
+func (e InvalidLocationError) Error() string {
+	return fmt.Sprintf("Invalid S3 location %q", string(e))
+}
+
+// The workgroup used when none is given
+const DefaultWorkGroup = "primary"
+
+// Query describes the tables a role queries
+type Query struct {
+	Region  string
+	Account string
+	// Athena workgroup the queries run in, defaults to DefaultWorkGroup
+	WorkGroup string
+	// Glue database holding the tables
+	Database string
+	// Tables that may be queried, all tables in the database when empty
+	Tables []string
+	// S3 locations of the table data, e.g. s3://bucket/prefix/
+	DataLocations []string
+	// S3 location query results are written to
+	ResultsLocation string
+	// ARNs of the KMS keys the data and results are encrypted with, if
+	// encrypted with customer managed keys
+	KMSKeyArns []string
+}
+
+// location is a parsed S3 URL
+type location struct {
+	bucket string
+	prefix string
+}
+
+func parseLocation(s string) (location, error) {
+	rest, ok := strings.CutPrefix(s, "s3://")
+	if !ok {
+		return location{}, InvalidLocationError(s)
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" || strings.ContainsAny(s, "*?") {
+		return location{}, InvalidLocationError(s)
+	}
+	return location{bucket, prefix}, nil
+}
+
+func (l location) bucketArn() string {
+	return "arn:aws:s3:::" + l.bucket
+}
+
+func (l location) objectsArn() string {
+	return l.bucketArn() + "/" + l.prefix + "*"
+}
+
+// listStatement allows listing the location. GetBucketLocation requests have
+// no s3:prefix, so it is allowed separately by NewQueryPolicy.
+func (l location) listStatement() *policy.Statement {
+	stmt := policy.NewStatement(
+		policy.WithEffect(policy.Allow),
+		policy.WithActions(actions.S3ListBucket),
+		policy.WithResources(l.bucketArn()),
+	)
+	if l.prefix != "" {
+		stmt.AddCondition(policy.ConditionStringLike, conditionkeys.S3Prefix, l.prefix+"*")
+	}
+	return stmt
+}
+
+// NewQueryPolicy creates a policy allowing queries on the tables, reading
+// their data and writing the results. The query is checked as a whole, all
+// locations must be valid S3 locations before any statement is generated.
+func NewQueryPolicy(q Query) (*policy.Policy, error) {
+	if q.Database == "" {
+		return nil, ErrNoDatabase
+	}
+	if len(q.DataLocations) == 0 {
+		return nil, ErrNoDataLocation
+	}
+	if q.ResultsLocation == "" {
+		return nil, ErrNoResultsLocation
+	}
+	data := make([]location, len(q.DataLocations))
+	for i, s := range q.DataLocations {
+		l, err := parseLocation(s)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = l
+	}
+	results, err := parseLocation(q.ResultsLocation)
+	if err != nil {
+		return nil, err
+	}
+	workGroup := q.WorkGroup
+	if workGroup == "" {
+		workGroup = DefaultWorkGroup
+	}
+
+	p := policy.NewPolicy()
+	stmt := p.AddIdentityStatement()
+	stmt.SetSid("RunQueries")
+	stmt.Effect = policy.Allow
+	stmt.AddActions(
+		actions.AthenaStartQueryExecution, actions.AthenaStopQueryExecution,
+		actions.AthenaGetQueryExecution, actions.AthenaGetQueryResults, actions.AthenaGetWorkGroup,
+	)
+	stmt.AddResource(fmt.Sprintf("arn:aws:athena:%s:%s:workgroup/%s", q.Region, q.Account, workGroup))
+
+	glue := fmt.Sprintf("arn:aws:glue:%s:%s:", q.Region, q.Account)
+	stmt = p.AddIdentityStatement()
+	stmt.SetSid("ReadCatalog")
+	stmt.Effect = policy.Allow
+	stmt.AddActions(
+		actions.GlueGetDatabase, actions.GlueGetTable, actions.GlueGetTables,
+		actions.GlueGetPartition, actions.GlueGetPartitions, actions.GlueBatchGetPartition,
+	)
+	stmt.AddResources(glue+"catalog", glue+"database/"+q.Database)
+	if len(q.Tables) == 0 {
+		stmt.AddResource(glue + "table/" + q.Database + "/*")
+	}
+	for _, table := range q.Tables {
+		stmt.AddResource(glue + "table/" + q.Database + "/" + table)
+	}
+
+	stmt = p.AddIdentityStatement()
+	stmt.SetSid("ReadData")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.S3GetObject)
+	for _, l := range data {
+		stmt.AddResource(l.objectsArn())
+	}
+	for i, l := range data {
+		stmt = l.listStatement()
+		stmt.SetSid(fmt.Sprintf("ListData%d", i))
+		p.Append(stmt)
+	}
+
+	stmt = p.AddIdentityStatement()
+	stmt.SetSid("WriteResults")
+	stmt.Effect = policy.Allow
+	stmt.AddActions(actions.S3GetObject, actions.S3PutObject, actions.S3AbortMultipartUpload, actions.S3ListMultipartUploadParts)
+	stmt.AddResource(results.objectsArn())
+	stmt = results.listStatement()
+	stmt.SetSid("ListResults")
+	stmt.AddAction(actions.S3ListBucketMultipartUploads)
+	p.Append(stmt)
+
+	stmt = p.AddIdentityStatement()
+	stmt.SetSid("LocateBuckets")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.S3GetBucketLocation)
+	buckets := make(map[string]bool)
+	for _, l := range data {
+		buckets[l.bucket] = true
+	}
+	buckets[results.bucket] = true
+	for _, bucket := range sortedKeys(buckets) {
+		stmt.AddResource(location{bucket: bucket}.bucketArn())
+	}
+
+	if len(q.KMSKeyArns) > 0 {
+		stmt = p.AddIdentityStatement()
+		stmt.SetSid("UseKeys")
+		stmt.Effect = policy.Allow
+		stmt.AddActions(actions.KMSDecrypt, actions.KMSGenerateDataKey)
+		stmt.AddResources(q.KMSKeyArns...)
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
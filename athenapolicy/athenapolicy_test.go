@@ -0,0 +1,110 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package athenapolicy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/eval"
+)
+
+func TestNewQueryPolicy(t *testing.T) {
+	q := Query{
+		Region:          "eu-west-1",
+		Account:         "111122223333",
+		Database:        "sales",
+		Tables:          []string{"orders"},
+		DataLocations:   []string{"s3://lake/sales/orders/"},
+		ResultsLocation: "s3://athena-results/",
+		KMSKeyArns:      []string{"arn:aws:kms:eu-west-1:111122223333:key/1234"},
+	}
+	p, err := NewQueryPolicy(q)
+	if err != nil {
+		t.Fatalf("Failed creating policy: %s", err)
+	}
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"RunQueries","Effect":"Allow","Action":["athena:StartQueryExecution","athena:StopQueryExecution","athena:GetQueryExecution","athena:GetQueryResults","athena:GetWorkGroup"],"Resource":["arn:aws:athena:eu-west-1:111122223333:workgroup/primary"]},` +
+		`{"Sid":"ReadCatalog","Effect":"Allow","Action":["glue:GetDatabase","glue:GetTable","glue:GetTables","glue:GetPartition","glue:GetPartitions","glue:BatchGetPartition"],"Resource":["arn:aws:glue:eu-west-1:111122223333:catalog","arn:aws:glue:eu-west-1:111122223333:database/sales","arn:aws:glue:eu-west-1:111122223333:table/sales/orders"]},` +
+		`{"Sid":"ReadData","Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::lake/sales/orders/*"]},` +
+		`{"Sid":"ListData0","Effect":"Allow","Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::lake"],"Condition":{"StringLike":{"s3:prefix":["sales/orders/*"]}}},` +
+		`{"Sid":"WriteResults","Effect":"Allow","Action":["s3:GetObject","s3:PutObject","s3:AbortMultipartUpload","s3:ListMultipartUploadParts"],"Resource":["arn:aws:s3:::athena-results/*"]},` +
+		`{"Sid":"ListResults","Effect":"Allow","Action":["s3:ListBucket","s3:ListBucketMultipartUploads"],"Resource":["arn:aws:s3:::athena-results"]},` +
+		`{"Sid":"LocateBuckets","Effect":"Allow","Action":["s3:GetBucketLocation"],"Resource":["arn:aws:s3:::athena-results","arn:aws:s3:::lake"]},` +
+		`{"Sid":"UseKeys","Effect":"Allow","Action":["kms:Decrypt","kms:GenerateDataKey"],"Resource":["arn:aws:kms:eu-west-1:111122223333:key/1234"]}]}`
+	got, err := p.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+}
+
+func TestNewQueryPolicyEvaluate(t *testing.T) {
+	q := Query{
+		Region:          "eu-west-1",
+		Account:         "111122223333",
+		Database:        "sales",
+		DataLocations:   []string{"s3://lake/sales/orders/"},
+		ResultsLocation: "s3://athena-results/",
+	}
+	p, err := NewQueryPolicy(q)
+	if err != nil {
+		t.Fatalf("Failed creating policy: %s", err)
+	}
+	const principal = "arn:aws:iam::111122223333:role/analyst"
+	tests := []struct {
+		action   string
+		resource string
+		prefix   string
+		expected eval.Decision
+	}{
+		{"s3:GetBucketLocation", "arn:aws:s3:::lake", "", eval.Allow},
+		{"s3:GetBucketLocation", "arn:aws:s3:::athena-results", "", eval.Allow},
+		{"s3:GetBucketLocation", "arn:aws:s3:::other", "", eval.ImplicitDeny},
+		{"s3:ListBucket", "arn:aws:s3:::lake", "sales/orders/2024/", eval.Allow},
+		{"s3:ListBucket", "arn:aws:s3:::lake", "hr/", eval.ImplicitDeny},
+		{"s3:ListBucket", "arn:aws:s3:::lake", "", eval.ImplicitDeny},
+		{"s3:ListBucket", "arn:aws:s3:::athena-results", "", eval.Allow},
+		{"s3:GetObject", "arn:aws:s3:::lake/sales/orders/part-0.parquet", "", eval.Allow},
+		{"s3:PutObject", "arn:aws:s3:::lake/sales/orders/part-0.parquet", "", eval.ImplicitDeny},
+		{"athena:StartQueryExecution", "arn:aws:athena:eu-west-1:111122223333:workgroup/primary", "", eval.Allow},
+	}
+
+	for i, test := range tests {
+		req := eval.NewRequest(principal, test.action, test.resource)
+		if test.prefix != "" {
+			req.Context.Set("s3:prefix", test.prefix)
+		}
+		if decision := eval.Evaluate(p, *req); decision != test.expected {
+			t.Errorf("%d: Expected %s for %s on %s got %s", i, test.expected, test.action, test.resource, decision)
+		}
+	}
+}
+
+func TestNewQueryPolicyErrors(t *testing.T) {
+	valid := Query{Database: "sales", DataLocations: []string{"s3://lake/"}, ResultsLocation: "s3://results/"}
+	tests := []struct {
+		modify   func(q *Query)
+		expected error
+	}{
+		{func(q *Query) { q.Database = "" }, ErrNoDatabase},
+		{func(q *Query) { q.DataLocations = nil }, ErrNoDataLocation},
+		{func(q *Query) { q.ResultsLocation = "" }, ErrNoResultsLocation},
+		{func(q *Query) { q.DataLocations = []string{"lake/sales"} }, InvalidLocationError("lake/sales")},
+		{func(q *Query) { q.ResultsLocation = "s3:///results" }, InvalidLocationError("s3:///results")},
+	}
+
+	for i, test := range tests {
+		q := valid
+		test.modify(&q)
+		if _, err := NewQueryPolicy(q); err != test.expected {
+			t.Errorf("%d: Expected %v got %v", i, test.expected, err)
+		}
+	}
+}
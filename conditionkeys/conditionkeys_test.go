@@ -0,0 +1,44 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+import (
+	"testing"
+)
+
+func TestTypeOf(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected Type
+		ok       bool
+	}{
+		{S3Prefix, TypeString, true},
+		{"S3:MAX-KEYS", TypeNumeric, true},
+		{EC2Encrypted, TypeBool, true},
+		{DynamoDBLeadingKeys, TypeArrayOfString, true},
+		{"s3:unknown", 0, false},
+		{"nonsense", 0, false},
+	}
+
+	for _, test := range tests {
+		got, ok := TypeOf(test.key)
+		if got != test.expected || ok != test.ok {
+			t.Errorf("%s: Expected %s, %t got %s, %t", test.key, test.expected, test.ok, got, ok)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := Keys("STS")
+	if len(keys) != len(stsTypes) || keys[0] != STSAWSServiceName {
+		t.Errorf("Expected sorted STS keys got %v", keys)
+	}
+	if keys := Keys("unknown"); len(keys) != 0 {
+		t.Errorf("Expected no keys got %v", keys)
+	}
+}
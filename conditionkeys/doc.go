@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package conditionkeys defines constants for service specific condition
+// keys, taken from the AWS service authorization reference, together with
+// the type of value each key holds. The constants are untyped and can be
+// passed anywhere a condition key is expected, e.g.
+//
+//	stmt.AddCondition(policy.ConditionStringLike, conditionkeys.S3Prefix, "home/*")
+//
+// Constants are named after the service prefix and the key name. Global
+// aws: keys are defined in the policy package.
+package conditionkeys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Type is the type of value a condition key holds, which determines the
+// condition operators that can be used with it
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeArrayOfString
+	TypeNumeric
+	TypeDate
+	TypeBool
+	TypeARN
+)
+
+var typeNames = map[Type]string{
+	TypeString:        "String",
+	TypeArrayOfString: "ArrayOfString",
+	TypeNumeric:       "Numeric",
+	TypeDate:          "Date",
+	TypeBool:          "Bool",
+	TypeARN:           "ARN",
+}
+
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// Value types by service prefix
+var catalog = map[string]map[string]Type{
+	"dynamodb": dynamodbTypes,
+	"ec2":      ec2Types,
+	"iam":      iamTypes,
+	"kms":      kmsTypes,
+	"lambda":   lambdaTypes,
+	"s3":       s3Types,
+	"sns":      snsTypes,
+	"sts":      stsTypes,
+}
+
+// TypeOf returns the type of value the condition key holds. Condition keys
+// are case insensitive. The second result is false for keys not in the
+// catalog.
+func TypeOf(key string) (Type, bool) {
+	service, _, ok := strings.Cut(key, ":")
+	if !ok {
+		return 0, false
+	}
+	for k, t := range catalog[strings.ToLower(service)] {
+		if strings.EqualFold(k, key) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// Keys returns the condition keys in the catalog for the service prefix
+func Keys(service string) []string {
+	types := catalog[strings.ToLower(service)]
+	keys := make([]string, 0, len(types))
+	for k := range types {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,29 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+// Amazon DynamoDB condition keys
+const (
+	DynamoDBAttributes             = "dynamodb:Attributes"
+	DynamoDBEnclosingOperation     = "dynamodb:EnclosingOperation"
+	DynamoDBFullTableScan          = "dynamodb:FullTableScan"
+	DynamoDBLeadingKeys            = "dynamodb:LeadingKeys"
+	DynamoDBReturnConsumedCapacity = "dynamodb:ReturnConsumedCapacity"
+	DynamoDBReturnValues           = "dynamodb:ReturnValues"
+	DynamoDBSelect                 = "dynamodb:Select"
+)
+
+var dynamodbTypes = map[string]Type{
+	DynamoDBAttributes:             TypeArrayOfString,
+	DynamoDBEnclosingOperation:     TypeString,
+	DynamoDBFullTableScan:          TypeBool,
+	DynamoDBLeadingKeys:            TypeArrayOfString,
+	DynamoDBReturnConsumedCapacity: TypeString,
+	DynamoDBReturnValues:           TypeString,
+	DynamoDBSelect:                 TypeString,
+}
@@ -0,0 +1,55 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+// Amazon EC2 condition keys
+const (
+	EC2AvailabilityZone                = "ec2:AvailabilityZone"
+	EC2Encrypted                       = "ec2:Encrypted"
+	EC2ImageType                       = "ec2:ImageType"
+	EC2InstanceProfile                 = "ec2:InstanceProfile"
+	EC2InstanceType                    = "ec2:InstanceType"
+	EC2IsLaunchTemplateResource        = "ec2:IsLaunchTemplateResource"
+	EC2LaunchTemplate                  = "ec2:LaunchTemplate"
+	EC2MetadataHttpPutResponseHopLimit = "ec2:MetadataHttpPutResponseHopLimit"
+	EC2MetadataHttpTokens              = "ec2:MetadataHttpTokens"
+	EC2Owner                           = "ec2:Owner"
+	EC2Public                          = "ec2:Public"
+	EC2Region                          = "ec2:Region"
+	EC2RoleDelivery                    = "ec2:RoleDelivery"
+	EC2RootDeviceType                  = "ec2:RootDeviceType"
+	EC2SourceInstanceARN               = "ec2:SourceInstanceARN"
+	EC2Subnet                          = "ec2:Subnet"
+	EC2Tenancy                         = "ec2:Tenancy"
+	EC2VolumeSize                      = "ec2:VolumeSize"
+	EC2VolumeType                      = "ec2:VolumeType"
+	EC2Vpc                             = "ec2:Vpc"
+)
+
+var ec2Types = map[string]Type{
+	EC2AvailabilityZone:                TypeString,
+	EC2Encrypted:                       TypeBool,
+	EC2ImageType:                       TypeString,
+	EC2InstanceProfile:                 TypeARN,
+	EC2InstanceType:                    TypeString,
+	EC2IsLaunchTemplateResource:        TypeBool,
+	EC2LaunchTemplate:                  TypeARN,
+	EC2MetadataHttpPutResponseHopLimit: TypeNumeric,
+	EC2MetadataHttpTokens:              TypeString,
+	EC2Owner:                           TypeString,
+	EC2Public:                          TypeBool,
+	EC2Region:                          TypeString,
+	EC2RoleDelivery:                    TypeNumeric,
+	EC2RootDeviceType:                  TypeString,
+	EC2SourceInstanceARN:               TypeARN,
+	EC2Subnet:                          TypeARN,
+	EC2Tenancy:                         TypeString,
+	EC2VolumeSize:                      TypeNumeric,
+	EC2VolumeType:                      TypeString,
+	EC2Vpc:                             TypeARN,
+}
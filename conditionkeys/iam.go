@@ -0,0 +1,27 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+// AWS IAM condition keys
+const (
+	IAMAWSServiceName        = "iam:AWSServiceName"
+	IAMAssociatedResourceArn = "iam:AssociatedResourceArn"
+	IAMOrganizationsPolicyId = "iam:OrganizationsPolicyId"
+	IAMPassedToService       = "iam:PassedToService"
+	IAMPermissionsBoundary   = "iam:PermissionsBoundary"
+	IAMPolicyARN             = "iam:PolicyARN"
+)
+
+var iamTypes = map[string]Type{
+	IAMAWSServiceName:        TypeString,
+	IAMAssociatedResourceArn: TypeARN,
+	IAMOrganizationsPolicyId: TypeString,
+	IAMPassedToService:       TypeString,
+	IAMPermissionsBoundary:   TypeARN,
+	IAMPolicyARN:             TypeARN,
+}
@@ -0,0 +1,45 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+// AWS KMS condition keys
+const (
+	KMSBypassPolicyLockoutSafetyCheck         = "kms:BypassPolicyLockoutSafetyCheck"
+	KMSCallerAccount                          = "kms:CallerAccount"
+	KMSEncryptionAlgorithm                    = "kms:EncryptionAlgorithm"
+	KMSEncryptionContextKeys                  = "kms:EncryptionContextKeys"
+	KMSGrantIsForAWSResource                  = "kms:GrantIsForAWSResource"
+	KMSGrantOperations                        = "kms:GrantOperations"
+	KMSGranteePrincipal                       = "kms:GranteePrincipal"
+	KMSKeyOrigin                              = "kms:KeyOrigin"
+	KMSKeySpec                                = "kms:KeySpec"
+	KMSKeyUsage                               = "kms:KeyUsage"
+	KMSMultiRegion                            = "kms:MultiRegion"
+	KMSRetiringPrincipal                      = "kms:RetiringPrincipal"
+	KMSScheduleKeyDeletionPendingWindowInDays = "kms:ScheduleKeyDeletionPendingWindowInDays"
+	KMSSigningAlgorithm                       = "kms:SigningAlgorithm"
+	KMSViaService                             = "kms:ViaService"
+)
+
+var kmsTypes = map[string]Type{
+	KMSBypassPolicyLockoutSafetyCheck:         TypeBool,
+	KMSCallerAccount:                          TypeString,
+	KMSEncryptionAlgorithm:                    TypeString,
+	KMSEncryptionContextKeys:                  TypeArrayOfString,
+	KMSGrantIsForAWSResource:                  TypeBool,
+	KMSGrantOperations:                        TypeArrayOfString,
+	KMSGranteePrincipal:                       TypeString,
+	KMSKeyOrigin:                              TypeString,
+	KMSKeySpec:                                TypeString,
+	KMSKeyUsage:                               TypeString,
+	KMSMultiRegion:                            TypeBool,
+	KMSRetiringPrincipal:                      TypeString,
+	KMSScheduleKeyDeletionPendingWindowInDays: TypeNumeric,
+	KMSSigningAlgorithm:                       TypeString,
+	KMSViaService:                             TypeString,
+}
@@ -0,0 +1,33 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+// AWS Lambda condition keys
+const (
+	LambdaEventSourceToken    = "lambda:EventSourceToken"
+	LambdaFunctionArn         = "lambda:FunctionArn"
+	LambdaFunctionUrlAuthType = "lambda:FunctionUrlAuthType"
+	LambdaLayer               = "lambda:Layer"
+	LambdaPrincipal           = "lambda:Principal"
+	LambdaSecurityGroupIds    = "lambda:SecurityGroupIds"
+	LambdaSourceFunctionArn   = "lambda:SourceFunctionArn"
+	LambdaSubnetIds           = "lambda:SubnetIds"
+	LambdaVpcIds              = "lambda:VpcIds"
+)
+
+var lambdaTypes = map[string]Type{
+	LambdaEventSourceToken:    TypeString,
+	LambdaFunctionArn:         TypeARN,
+	LambdaFunctionUrlAuthType: TypeString,
+	LambdaLayer:               TypeArrayOfString,
+	LambdaPrincipal:           TypeString,
+	LambdaSecurityGroupIds:    TypeArrayOfString,
+	LambdaSourceFunctionArn:   TypeARN,
+	LambdaSubnetIds:           TypeArrayOfString,
+	LambdaVpcIds:              TypeString,
+}
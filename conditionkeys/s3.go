@@ -0,0 +1,59 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+// Amazon S3 condition keys
+const (
+	S3AuthType                            = "s3:authType"
+	S3Delimiter                           = "s3:delimiter"
+	S3LocationConstraint                  = "s3:LocationConstraint"
+	S3MaxKeys                             = "s3:max-keys"
+	S3ObjectLockLegalHold                 = "s3:object-lock-legal-hold"
+	S3ObjectLockMode                      = "s3:object-lock-mode"
+	S3ObjectLockRemainingRetentionDays    = "s3:object-lock-remaining-retention-days"
+	S3ObjectLockRetainUntilDate           = "s3:object-lock-retain-until-date"
+	S3Prefix                              = "s3:prefix"
+	S3RequestObjectTagKeys                = "s3:RequestObjectTagKeys"
+	S3ResourceAccount                     = "s3:ResourceAccount"
+	S3SignatureAge                        = "s3:signatureAge"
+	S3SignatureVersion                    = "s3:signatureversion"
+	S3TlsVersion                          = "s3:TlsVersion"
+	S3VersionId                           = "s3:VersionId"
+	S3XAmzAcl                             = "s3:x-amz-acl"
+	S3XAmzContentSha256                   = "s3:x-amz-content-sha256"
+	S3XAmzCopySource                      = "s3:x-amz-copy-source"
+	S3XAmzMetadataDirective               = "s3:x-amz-metadata-directive"
+	S3XAmzServerSideEncryption            = "s3:x-amz-server-side-encryption"
+	S3XAmzServerSideEncryptionAwsKmsKeyId = "s3:x-amz-server-side-encryption-aws-kms-key-id"
+	S3XAmzStorageClass                    = "s3:x-amz-storage-class"
+)
+
+var s3Types = map[string]Type{
+	S3AuthType:                            TypeString,
+	S3Delimiter:                           TypeString,
+	S3LocationConstraint:                  TypeString,
+	S3MaxKeys:                             TypeNumeric,
+	S3ObjectLockLegalHold:                 TypeString,
+	S3ObjectLockMode:                      TypeString,
+	S3ObjectLockRemainingRetentionDays:    TypeNumeric,
+	S3ObjectLockRetainUntilDate:           TypeDate,
+	S3Prefix:                              TypeString,
+	S3RequestObjectTagKeys:                TypeArrayOfString,
+	S3ResourceAccount:                     TypeString,
+	S3SignatureAge:                        TypeNumeric,
+	S3SignatureVersion:                    TypeString,
+	S3TlsVersion:                          TypeNumeric,
+	S3VersionId:                           TypeString,
+	S3XAmzAcl:                             TypeString,
+	S3XAmzContentSha256:                   TypeString,
+	S3XAmzCopySource:                      TypeString,
+	S3XAmzMetadataDirective:               TypeString,
+	S3XAmzServerSideEncryption:            TypeString,
+	S3XAmzServerSideEncryptionAwsKmsKeyId: TypeARN,
+	S3XAmzStorageClass:                    TypeString,
+}
@@ -0,0 +1,19 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+// Amazon SNS condition keys
+const (
+	SNSEndpoint = "sns:Endpoint"
+	SNSProtocol = "sns:Protocol"
+)
+
+var snsTypes = map[string]Type{
+	SNSEndpoint: TypeString,
+	SNSProtocol: TypeString,
+}
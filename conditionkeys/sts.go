@@ -0,0 +1,27 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package conditionkeys
+
+// AWS STS condition keys
+const (
+	STSAWSServiceName    = "sts:AWSServiceName"
+	STSDurationSeconds   = "sts:DurationSeconds"
+	STSExternalId        = "sts:ExternalId"
+	STSRoleSessionName   = "sts:RoleSessionName"
+	STSSourceIdentity    = "sts:SourceIdentity"
+	STSTransitiveTagKeys = "sts:TransitiveTagKeys"
+)
+
+var stsTypes = map[string]Type{
+	STSAWSServiceName:    TypeString,
+	STSDurationSeconds:   TypeNumeric,
+	STSExternalId:        TypeString,
+	STSRoleSessionName:   TypeString,
+	STSSourceIdentity:    TypeString,
+	STSTransitiveTagKeys: TypeArrayOfString,
+}
@@ -0,0 +1,169 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package dynamodbpolicy provides helpers to generate and check fine-grained
+// DynamoDB access control, restricting access to items by partition key and
+// to specific attributes
+package dynamodbpolicy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// Error for a fine-grained access control condition that does not restrict
+// access the way it appears to
+type ConditionError struct {
+	Key    policy.ConditionVariable
+	Reason string
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("Condition on %s: %s", e.Key, e.Reason)
+}
+
+// Value of dynamodb:Select for requests that only return the requested
+// attributes
+const SelectSpecificAttributes = "SPECIFIC_ATTRIBUTES"
+
+var forAllValuesStringEquals = policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues)
+
+// TableArn returns the ARN of a DynamoDB table
+func TableArn(region, account, table string) string {
+	return fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/%s", region, account, table)
+}
+
+// IndexArn returns the ARN matching all indexes of a DynamoDB table
+func IndexArn(region, account, table string) string {
+	return TableArn(region, account, table) + "/index/*"
+}
+
+// AddLeadingKeys restricts the statement to items whose partition key is one
+// of the given keys, e.g. policy.Var(policy.VarUsedId) for per-user items
+func AddLeadingKeys(stmt *policy.Statement, keys ...string) {
+	stmt.AddConditionValues(forAllValuesStringEquals, conditionkeys.DynamoDBLeadingKeys, keys...)
+}
+
+// AddAttributes restricts the statement to the given attributes. Requests
+// must select specific attributes, otherwise a query without a projection
+// would return every attribute.
+func AddAttributes(stmt *policy.Statement, attributes ...string) {
+	stmt.AddConditionValues(forAllValuesStringEquals, conditionkeys.DynamoDBAttributes, attributes...)
+	stmt.AddCondition(policy.ConditionStringEquals.IfExists(), conditionkeys.DynamoDBSelect, SelectSpecificAttributes)
+}
+
+// Validate checks the fine-grained access control conditions of the Allow
+// statements in the policy. dynamodb:LeadingKeys and dynamodb:Attributes are
+// multivalued and must be compared with ForAllValues:StringEquals, other
+// operators either never match or match any request. Because ForAllValues
+// also matches when the key is absent, a statement restricted by leading
+// keys must not allow Scan or the other UnpartitionedActions, and one
+// restricted by attributes must restrict dynamodb:Select. Returns nil or
+// policy.ValidationErrors.
+func Validate(p *policy.Policy) error {
+	errs := make(policy.ValidationErrors, 0)
+	for _, stmt := range p.Statement {
+		if stmt.Effect == policy.Allow {
+			errs = append(errs, validateStatement(stmt)...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateStatement(stmt *policy.Statement) []error {
+	errs := make([]error, 0)
+	types := make([]policy.ConditionType, 0, len(stmt.Condition))
+	for t := range stmt.Condition {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	for _, key := range []policy.ConditionVariable{conditionkeys.DynamoDBLeadingKeys, conditionkeys.DynamoDBAttributes} {
+		for _, t := range types {
+			if _, ok := stmt.Condition[t][key]; !ok || t == forAllValuesStringEquals {
+				continue
+			}
+			switch t.SetOperator() {
+			case "":
+				errs = append(errs, &ConditionError{key, fmt.Sprintf("%s on a multivalued key without ForAllValues never matches requests for more than one value", t)})
+			case policy.ForAnyValue:
+				errs = append(errs, &ConditionError{key, fmt.Sprintf("%s matches a request when any single value matches", t)})
+			default:
+				errs = append(errs, &ConditionError{key, fmt.Sprintf("%s must be %s", t, forAllValuesStringEquals)})
+			}
+		}
+	}
+
+	if _, ok := stmt.Condition[forAllValuesStringEquals][conditionkeys.DynamoDBLeadingKeys]; ok {
+		if allowed := AllowedUnpartitioned(stmt); len(allowed) > 0 {
+			errs = append(errs, &ConditionError{conditionkeys.DynamoDBLeadingKeys, fmt.Sprintf("statement allows %s, which have no leading keys and are not restricted", strings.Join(allowed, ", "))})
+		}
+	}
+	if _, ok := stmt.Condition[forAllValuesStringEquals][conditionkeys.DynamoDBAttributes]; ok && !restrictsSelect(stmt) {
+		errs = append(errs, &ConditionError{conditionkeys.DynamoDBAttributes, fmt.Sprintf("%s is not restricted to %s, requests without a projection return all attributes", conditionkeys.DynamoDBSelect, SelectSpecificAttributes)})
+	}
+	return errs
+}
+
+// UnpartitionedActions read or export items without leading keys, so a
+// dynamodb:LeadingKeys condition does not restrict them: a statement allowing
+// them grants access to all items of the table
+var UnpartitionedActions = []string{
+	actions.DynamoDBScan,
+	actions.DynamoDBPartiQLSelect,
+	actions.DynamoDBPartiQLUpdate,
+	actions.DynamoDBPartiQLDelete,
+	actions.DynamoDBExportTableToPointInTime,
+	actions.DynamoDBGetRecords,
+}
+
+// AllowedUnpartitioned returns the UnpartitionedActions the statement's Action
+// or NotAction covers
+func AllowedUnpartitioned(stmt *policy.Statement) []string {
+	result := make([]string, 0)
+	for _, action := range UnpartitionedActions {
+		if coversAction(stmt, action) {
+			result = append(result, action)
+		}
+	}
+	return result
+}
+
+func coversAction(stmt *policy.Statement, action string) bool {
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if policy.WildcardMatch(strings.ToLower(pattern), strings.ToLower(action)) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(stmt.NotAction) > 0 {
+		return !matches(stmt.NotAction)
+	}
+	return matches(stmt.Action)
+}
+
+// restrictsSelect reports whether the statement only allows requests that
+// select specific attributes
+func restrictsSelect(stmt *policy.Statement) bool {
+	for _, t := range []policy.ConditionType{policy.ConditionStringEquals, policy.ConditionStringEquals.IfExists()} {
+		values := stmt.Condition[t][conditionkeys.DynamoDBSelect]
+		if len(values) == 1 && values[0] == SelectSpecificAttributes {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package dynamodbpolicy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestFineGrainedAccess(t *testing.T) {
+	p := policy.NewPolicy()
+	stmt := p.AddIdentityStatement()
+	stmt.Effect = policy.Allow
+	stmt.AddActions(actions.DynamoDBGetItem, actions.DynamoDBQuery)
+	stmt.AddResources(TableArn("eu-west-1", "111122223333", "Scores"), IndexArn("eu-west-1", "111122223333", "Scores"))
+	AddLeadingKeys(stmt, policy.Var(policy.VarUsedId))
+	AddAttributes(stmt, "UserId", "TopScore")
+
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["dynamodb:GetItem","dynamodb:Query"],"Resource":["arn:aws:dynamodb:eu-west-1:111122223333:table/Scores","arn:aws:dynamodb:eu-west-1:111122223333:table/Scores/index/*"],"Condition":{"ForAllValues:StringEquals":{"dynamodb:Attributes":["UserId","TopScore"],"dynamodb:LeadingKeys":["${aws:userid}"]},"StringEqualsIfExists":{"dynamodb:Select":["SPECIFIC_ATTRIBUTES"]}}}]}`
+	got, err := p.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+	if err := Validate(p); err != nil {
+		t.Errorf("Expected no error got %s", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		stmt     *policy.Statement
+		expected []string
+	}{
+		{
+			policy.NewStatement(
+				policy.WithEffect(policy.Allow), policy.WithActions("dynamodb:*"), policy.WithResources("*"),
+				policy.WithCondition(policy.ConditionStringEquals, "dynamodb:LeadingKeys", "user"),
+			),
+			[]string{"Condition on dynamodb:LeadingKeys: StringEquals on a multivalued key without ForAllValues never matches requests for more than one value"},
+		},
+		{
+			policy.NewStatement(
+				policy.WithEffect(policy.Allow), policy.WithActions("dynamodb:Get*"), policy.WithResources("*"),
+				policy.WithCondition(policy.ConditionStringEquals.WithSetOperator(policy.ForAnyValue), "dynamodb:Attributes", "a"),
+			),
+			[]string{"Condition on dynamodb:Attributes: ForAnyValue:StringEquals matches a request when any single value matches"},
+		},
+		{
+			policy.NewStatement(
+				policy.WithEffect(policy.Allow), policy.WithActions("dynamodb:*"), policy.WithResources("*"),
+				policy.WithCondition(forAllValuesStringEquals, "dynamodb:LeadingKeys", "user"),
+				policy.WithCondition(forAllValuesStringEquals, "dynamodb:Attributes", "a"),
+			),
+			[]string{
+				"Condition on dynamodb:LeadingKeys: statement allows dynamodb:Scan, dynamodb:PartiQLSelect, dynamodb:PartiQLUpdate, dynamodb:PartiQLDelete, dynamodb:ExportTableToPointInTime, dynamodb:GetRecords, which have no leading keys and are not restricted",
+				"Condition on dynamodb:Attributes: dynamodb:Select is not restricted to SPECIFIC_ATTRIBUTES, requests without a projection return all attributes",
+			},
+		},
+		{
+			policy.NewStatement(
+				policy.WithActions("dynamodb:*"), policy.WithResources("*"),
+				policy.WithCondition(policy.ConditionStringEquals, "dynamodb:LeadingKeys", "user"),
+			),
+			nil,
+		},
+	}
+
+	for i, test := range tests {
+		p := policy.NewPolicy()
+		p.Append(test.stmt)
+		var errs policy.ValidationErrors
+		errors.As(Validate(p), &errs)
+		if len(errs) != len(test.expected) {
+			t.Errorf("%d: Expected %v got %v", i, test.expected, errs)
+			continue
+		}
+		for j, err := range errs {
+			if err.Error() != test.expected[j] {
+				t.Errorf("%d: Expected %s got %s", i, test.expected[j], err)
+			}
+		}
+	}
+}
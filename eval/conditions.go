@@ -0,0 +1,413 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"math/big"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+// operator compares the values of a condition key in the request context
+// with the values in the policy
+type operator struct {
+	// match reports whether the context value matches the policy value
+	match func(policyValue, value string, ctx Context) bool
+	// Negated operators match when the context value matches none of the
+	// policy values, and when the key is absent
+	negated bool
+}
+
+// Supported condition operators
+var operators = map[policy.ConditionType]operator{
+	policy.ConditionStringEquals:              {stringOperator(StringEquals), false},
+	policy.ConditionStringNotEquals:           {stringOperator(StringEquals), true},
+	policy.ConditionStringEqualsIgnoreCase:    {stringOperator(StringEqualsIgnoreCase), false},
+	policy.ConditionStringNotEqualsIgnoreCase: {stringOperator(StringEqualsIgnoreCase), true},
+	policy.ConditionStringLike:                {likeOperator(StringLike), false},
+	policy.ConditionStringNotLike:             {likeOperator(StringLike), true},
+	policy.ConditionArnEquals:                 {MatchArn, false},
+	policy.ConditionArnNotEquals:              {MatchArn, true},
+	policy.ConditionArnLike:                   {MatchArn, false},
+	policy.ConditionArnNotLike:                {MatchArn, true},
+	policy.ConditionIpAddress:                 {plainOperator(IpAddress), false},
+	policy.ConditionNotIpAddress:              {plainOperator(IpAddress), true},
+	policy.ConditionDateEquals:                {plainOperator(DateEquals), false},
+	policy.ConditionDateNotEquals:             {plainOperator(DateEquals), true},
+	policy.ConditionDateLessThan:              {plainOperator(DateLessThan), false},
+	policy.ConditionDateLessThanEquals:        {plainOperator(DateLessThanEquals), false},
+	policy.ConditionDateGreaterThan:           {plainOperator(DateGreaterThan), false},
+	policy.ConditionDateGreaterThanEquals:     {plainOperator(DateGreaterThanEquals), false},
+	policy.ConditionNumericEquals:             {plainOperator(NumericEquals), false},
+	policy.ConditionNumericNotEquals:          {plainOperator(NumericEquals), true},
+	policy.ConditionNumericLessThan:           {plainOperator(NumericLessThan), false},
+	policy.ConditionNumericLessThanEquals:     {plainOperator(NumericLessThanEquals), false},
+	policy.ConditionNumericGreaterThan:        {plainOperator(NumericGreaterThan), false},
+	policy.ConditionNumericGreaterThanEquals:  {plainOperator(NumericGreaterThanEquals), false},
+	policy.ConditionBool:                      {plainOperator(Bool), false},
+}
+
+// plainOperator compares the policy value as is, for operators that do not
+// support policy variables
+func plainOperator(compare func(policyValue, value string) bool) func(string, string, Context) bool {
+	return func(policyValue, value string, _ Context) bool {
+		return compare(policyValue, value)
+	}
+}
+
+// stringOperator resolves the policy variables in the policy value before
+// comparing it
+func stringOperator(compare func(policyValue, value string) bool) func(string, string, Context) bool {
+	return func(policyValue, value string, ctx Context) bool {
+		resolved, ok := substitute(policyValue, ctx, false)
+		return ok && compare(resolved, value)
+	}
+}
+
+// likeOperator resolves the policy variables in the pattern before comparing
+// it, wildcards in variable values match literally
+func likeOperator(compare func(pattern, value string) bool) func(string, string, Context) bool {
+	return func(pattern, value string, ctx Context) bool {
+		resolved, ok := substitute(pattern, ctx, true)
+		return ok && compare(resolved, value)
+	}
+}
+
+// substitute replaces the policy variables in s by their values in the
+// context. With escape set, *, ? and $ in the values are replaced by ${*},
+// ${?} and ${$} so they match literally in patterns.
+func substitute(s string, ctx Context, escape bool) (string, bool) {
+	resolved, ok := resolve(s, ctx)
+	if !ok {
+		return "", false
+	}
+	var b strings.Builder
+	for _, t := range resolved {
+		if escape && !t.wildcard && (t.c == '*' || t.c == '?' || t.c == '$') {
+			b.WriteString("${" + string(t.c) + "}")
+			continue
+		}
+		b.WriteByte(t.c)
+	}
+	return b.String(), true
+}
+
+// StringEquals reports whether the value equals the policy value exactly
+func StringEquals(policyValue, value string) bool {
+	return policyValue == value
+}
+
+// StringNotEquals reports whether the value differs from the policy value
+func StringNotEquals(policyValue, value string) bool {
+	return !StringEquals(policyValue, value)
+}
+
+// StringEqualsIgnoreCase reports whether the value equals the policy value,
+// ignoring case
+func StringEqualsIgnoreCase(policyValue, value string) bool {
+	return strings.EqualFold(policyValue, value)
+}
+
+// StringNotEqualsIgnoreCase reports whether the value differs from the policy
+// value, ignoring case
+func StringNotEqualsIgnoreCase(policyValue, value string) bool {
+	return !StringEqualsIgnoreCase(policyValue, value)
+}
+
+// StringLike reports whether the value matches the pattern, in which *
+// matches any sequence of characters and ? any single character. ${*}, ${?}
+// and ${$} match a literal *, ? and $.
+func StringLike(pattern, value string) bool {
+	resolved, ok := resolve(pattern, nil)
+	return ok && matchTokens(resolved, value)
+}
+
+// StringNotLike reports whether the value does not match the pattern
+func StringNotLike(pattern, value string) bool {
+	return !StringLike(pattern, value)
+}
+
+// IpAddress reports whether the IP address is in the range of the policy
+// value, an IPv4 or IPv6 CIDR block or a single address. IPv4 addresses mapped
+// to IPv6 are compared as IPv4 addresses. Values that can not be parsed do not
+// match.
+func IpAddress(policyValue, value string) bool {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	if prefix, err := netip.ParsePrefix(policyValue); err == nil {
+		if prefix.Addr().Is4In6() {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Contains(addr)
+	}
+	if policyAddr, err := netip.ParseAddr(policyValue); err == nil {
+		return policyAddr.Unmap() == addr
+	}
+	return false
+}
+
+// NotIpAddress reports whether the IP address is outside the range of the
+// policy value. As with all negated operators, a NotIpAddress condition on
+// aws:SourceIp matches requests without a source IP, such as requests AWS
+// services make on behalf of a principal.
+func NotIpAddress(policyValue, value string) bool {
+	return !IpAddress(policyValue, value)
+}
+
+// Date formats accepted besides epoch seconds, times without zone are UTC
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseDate parses an ISO 8601 date and time, or a number of seconds since
+// the epoch
+func parseDate(s string) (time.Time, bool) {
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compareDates returns -1, 0 or 1 when the value is before, at or after the
+// policy value. Returns false if either can not be parsed.
+func compareDates(policyValue, value string) (int, bool) {
+	p, ok := parseDate(policyValue)
+	if !ok {
+		return 0, false
+	}
+	v, ok := parseDate(value)
+	if !ok {
+		return 0, false
+	}
+	return v.Compare(p), true
+}
+
+// DateEquals reports whether the date and time equals the policy value. Both
+// may be given in ISO 8601 format, e.g. 2026-10-17T12:00:00Z, or as seconds
+// since the epoch, as aws:EpochTime is. Values that can not be parsed do not
+// match.
+func DateEquals(policyValue, value string) bool {
+	c, ok := compareDates(policyValue, value)
+	return ok && c == 0
+}
+
+// DateNotEquals reports whether the date and time differs from the policy
+// value
+func DateNotEquals(policyValue, value string) bool {
+	return !DateEquals(policyValue, value)
+}
+
+// DateLessThan reports whether the date and time is before the policy value
+func DateLessThan(policyValue, value string) bool {
+	c, ok := compareDates(policyValue, value)
+	return ok && c < 0
+}
+
+// DateLessThanEquals reports whether the date and time is at or before the
+// policy value
+func DateLessThanEquals(policyValue, value string) bool {
+	c, ok := compareDates(policyValue, value)
+	return ok && c <= 0
+}
+
+// DateGreaterThan reports whether the date and time is after the policy value
+func DateGreaterThan(policyValue, value string) bool {
+	c, ok := compareDates(policyValue, value)
+	return ok && c > 0
+}
+
+// DateGreaterThanEquals reports whether the date and time is at or after the
+// policy value
+func DateGreaterThanEquals(policyValue, value string) bool {
+	c, ok := compareDates(policyValue, value)
+	return ok && c >= 0
+}
+
+// Integers and decimals, without exponent
+var numberPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+// compareNumbers returns -1, 0 or 1 when the value is less than, equal to or
+// greater than the policy value. Numbers are compared exactly, so 0.1 and
+// 0.10 are equal. Returns false if either is not a number.
+func compareNumbers(policyValue, value string) (int, bool) {
+	if !numberPattern.MatchString(policyValue) || !numberPattern.MatchString(value) {
+		return 0, false
+	}
+	p, ok := new(big.Rat).SetString(policyValue)
+	if !ok {
+		return 0, false
+	}
+	v, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, false
+	}
+	return v.Cmp(p), true
+}
+
+// NumericEquals reports whether the number equals the policy value. Both are
+// integers or decimals, e.g. 3600 or 2.5, values that are not numbers do not
+// match.
+func NumericEquals(policyValue, value string) bool {
+	c, ok := compareNumbers(policyValue, value)
+	return ok && c == 0
+}
+
+// NumericNotEquals reports whether the number differs from the policy value
+func NumericNotEquals(policyValue, value string) bool {
+	return !NumericEquals(policyValue, value)
+}
+
+// NumericLessThan reports whether the number is less than the policy value
+func NumericLessThan(policyValue, value string) bool {
+	c, ok := compareNumbers(policyValue, value)
+	return ok && c < 0
+}
+
+// NumericLessThanEquals reports whether the number is less than or equal to
+// the policy value
+func NumericLessThanEquals(policyValue, value string) bool {
+	c, ok := compareNumbers(policyValue, value)
+	return ok && c <= 0
+}
+
+// NumericGreaterThan reports whether the number is greater than the policy
+// value
+func NumericGreaterThan(policyValue, value string) bool {
+	c, ok := compareNumbers(policyValue, value)
+	return ok && c > 0
+}
+
+// NumericGreaterThanEquals reports whether the number is greater than or
+// equal to the policy value
+func NumericGreaterThanEquals(policyValue, value string) bool {
+	c, ok := compareNumbers(policyValue, value)
+	return ok && c >= 0
+}
+
+// Bool reports whether the boolean value equals the policy value, "true" and
+// "false" are compared case-insensitively and other values do not match.
+//
+// Like other operators, Bool does not match when the key is absent, so
+// {"Bool": {"aws:MultiFactorAuthPresent": "false"}} does not match requests
+// made with long-term access keys, for which the key is absent rather than
+// false. Use BoolIfExists to match those requests as well.
+func Bool(policyValue, value string) bool {
+	p, ok := parseBool(policyValue)
+	if !ok {
+		return false
+	}
+	v, ok := parseBool(value)
+	return ok && p == v
+}
+
+func parseBool(s string) (bool, bool) {
+	switch {
+	case strings.EqualFold(s, "true"):
+		return true, true
+	case strings.EqualFold(s, "false"):
+		return false, true
+	}
+	return false, false
+}
+
+// conditionsMatch reports whether all conditions of the statement match the
+// request context. Returns false for ok if a condition uses an operator that
+// is not supported.
+func conditionsMatch(stmt *policy.Statement, ctx Context) (match, ok bool) {
+	// In sorted order, so an unsupported operator is reported regardless of
+	// the other conditions
+	types := make([]string, 0, len(stmt.Condition))
+	for t := range stmt.Condition {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	match = true
+	for _, t := range types {
+		for key, values := range stmt.Condition[policy.ConditionType(t)] {
+			m, supported := conditionMatches(policy.ConditionType(t), key, values, ctx)
+			if !supported {
+				return false, false
+			}
+			match = match && m
+		}
+	}
+	return match, true
+}
+
+// conditionMatches reports whether the condition on the key matches the
+// request context. A key absent from the context, or present without values,
+// matches IfExists conditions and ForAllValues, and negated operators without
+// set operator, but no other conditions.
+func conditionMatches(t policy.ConditionType, key policy.ConditionVariable, policyValues []string, ctx Context) (match, ok bool) {
+	values, _ := ctx.Get(key)
+	if t.Operator() == policy.ConditionNull {
+		for _, policyValue := range policyValues {
+			if strings.EqualFold(policyValue, "true") == (len(values) == 0) {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	op, ok := operators[t.Operator()]
+	if !ok {
+		return false, false
+	}
+	if len(values) == 0 {
+		switch t.SetOperator() {
+		case policy.ForAllValues:
+			return true, true
+		case policy.ForAnyValue:
+			return t.IsIfExists(), true
+		}
+		return t.IsIfExists() || op.negated, true
+	}
+
+	matchesValue := func(value string) bool {
+		for _, policyValue := range policyValues {
+			if op.match(policyValue, value, ctx) {
+				return !op.negated
+			}
+		}
+		return op.negated
+	}
+	if t.SetOperator() == policy.ForAllValues {
+		for _, value := range values {
+			if !matchesValue(value) {
+				return false, true
+			}
+		}
+		return true, true
+	}
+	// ForAnyValue, and keys without set operator which usually have a single
+	// value
+	for _, value := range values {
+		if matchesValue(value) {
+			return true, true
+		}
+	}
+	return false, true
+}
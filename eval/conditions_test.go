@@ -0,0 +1,274 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestStringOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator func(policyValue, value string) bool
+		policy   string
+		value    string
+		expected bool
+	}{
+		{"StringEquals", StringEquals, "finance", "finance", true},
+		{"StringEquals", StringEquals, "finance", "Finance", false},
+		{"StringEquals", StringEquals, "fin*", "finance", false},
+		{"StringNotEquals", StringNotEquals, "finance", "Finance", true},
+		{"StringEqualsIgnoreCase", StringEqualsIgnoreCase, "finance", "Finance", true},
+		{"StringNotEqualsIgnoreCase", StringNotEqualsIgnoreCase, "finance", "FINANCE", false},
+		{"StringLike", StringLike, "fin*", "finance", true},
+		{"StringLike", StringLike, "fin*", "Finance", false},
+		{"StringLike", StringLike, "home/*/notes", "home/alice/2026/notes", true},
+		{"StringLike", StringLike, "q?", "q1", true},
+		{"StringLike", StringLike, "q?", "q10", false},
+		{"StringLike", StringLike, "literal${*}", "literal*", true},
+		{"StringLike", StringLike, "literal${*}", "literals", false},
+		{"StringNotLike", StringNotLike, "fin*", "sales", true},
+	}
+	for _, test := range tests {
+		if got := test.operator(test.policy, test.value); got != test.expected {
+			t.Errorf("%s(%q, %q): expected %v got %v", test.name, test.policy, test.value, test.expected, got)
+		}
+	}
+}
+
+func TestIpAddress(t *testing.T) {
+	tests := []struct {
+		policy   string
+		value    string
+		expected bool
+	}{
+		{"203.0.113.0/24", "203.0.113.7", true},
+		{"203.0.113.0/24", "203.0.114.7", false},
+		{"203.0.113.7", "203.0.113.7", true},
+		{"203.0.113.7/32", "203.0.113.8", false},
+		{"203.0.113.0/24", "::ffff:203.0.113.7", true},
+		{"::ffff:203.0.113.0/120", "203.0.113.7", true},
+		{"2001:db8::/32", "2001:db8:1234::1", true},
+		{"2001:db8::/32", "2001:db9::1", false},
+		{"2001:db8::1", "2001:0db8:0000::1", true},
+		{"2001:db8::/32", "203.0.113.7", false},
+		{"203.0.113.0/24", "not-an-ip", false},
+		{"not-a-range", "203.0.113.7", false},
+	}
+	for _, test := range tests {
+		if got := IpAddress(test.policy, test.value); got != test.expected {
+			t.Errorf("IpAddress(%q, %q): expected %v got %v", test.policy, test.value, test.expected, got)
+		}
+		if got := NotIpAddress(test.policy, test.value); got == test.expected {
+			t.Errorf("NotIpAddress(%q, %q): expected %v got %v", test.policy, test.value, !test.expected, got)
+		}
+	}
+}
+
+func TestDateOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator func(policyValue, value string) bool
+		policy   string
+		value    string
+		expected bool
+	}{
+		{"DateEquals", DateEquals, "2026-10-17T12:00:00Z", "2026-10-17T14:00:00+02:00", true},
+		{"DateEquals", DateEquals, "2026-10-17T12:00:00Z", "1792238400", true},
+		{"DateEquals", DateEquals, "1792238400", "2026-10-17T12:00:00.000Z", true},
+		{"DateEquals", DateEquals, "2026-10-17", "2026-10-17T00:00:00Z", true},
+		{"DateEquals", DateEquals, "2026-10-17", "yesterday", false},
+		{"DateNotEquals", DateNotEquals, "2026-10-17", "2026-10-18", true},
+		{"DateLessThan", DateLessThan, "2026-10-17T12:00:00Z", "2026-10-17T11:59:59Z", true},
+		{"DateLessThan", DateLessThan, "2026-10-17T12:00:00Z", "2026-10-17T12:00:00Z", false},
+		{"DateLessThanEquals", DateLessThanEquals, "2026-10-17T12:00:00Z", "1792238400", true},
+		{"DateGreaterThan", DateGreaterThan, "2026-10-17T12:00:00Z", "1792238401", true},
+		{"DateGreaterThan", DateGreaterThan, "2026-10-17T12:00:00", "2026-10-17T12:00:00Z", false},
+		{"DateGreaterThanEquals", DateGreaterThanEquals, "2026-10-17T12:00Z", "2026-10-17T12:00:00Z", true},
+		{"DateGreaterThanEquals", DateGreaterThanEquals, "not-a-date", "2026-10-17T12:00:00Z", false},
+	}
+	for _, test := range tests {
+		if got := test.operator(test.policy, test.value); got != test.expected {
+			t.Errorf("%s(%q, %q): expected %v got %v", test.name, test.policy, test.value, test.expected, got)
+		}
+	}
+}
+
+func TestNumericOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator func(policyValue, value string) bool
+		policy   string
+		value    string
+		expected bool
+	}{
+		{"NumericEquals", NumericEquals, "10", "10", true},
+		{"NumericEquals", NumericEquals, "0.1", "0.10", true},
+		{"NumericEquals", NumericEquals, "10", "+10.0", true},
+		{"NumericEquals", NumericEquals, "10", "1e1", false},
+		{"NumericEquals", NumericEquals, "10", "ten", false},
+		{"NumericNotEquals", NumericNotEquals, "10", "11", true},
+		{"NumericLessThan", NumericLessThan, "3600", "3599", true},
+		{"NumericLessThan", NumericLessThan, "3600", "3600", false},
+		{"NumericLessThan", NumericLessThan, "0.3", "0.29999999999999999999", true},
+		{"NumericLessThanEquals", NumericLessThanEquals, "3600", "3600", true},
+		{"NumericGreaterThan", NumericGreaterThan, "-1", "0", true},
+		{"NumericGreaterThan", NumericGreaterThan, ".5", "0.5", false},
+		{"NumericGreaterThanEquals", NumericGreaterThanEquals, ".5", "0.5", true},
+		{"NumericGreaterThanEquals", NumericGreaterThanEquals, "1/2", "1", false},
+	}
+	for _, test := range tests {
+		if got := test.operator(test.policy, test.value); got != test.expected {
+			t.Errorf("%s(%q, %q): expected %v got %v", test.name, test.policy, test.value, test.expected, got)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	tests := []struct {
+		policy   string
+		value    string
+		expected bool
+	}{
+		{"true", "true", true},
+		{"TRUE", "True", true},
+		{"false", "FALSE", true},
+		{"true", "false", false},
+		{"false", "0", false},
+		{"yes", "yes", false},
+	}
+	for _, test := range tests {
+		if got := Bool(test.policy, test.value); got != test.expected {
+			t.Errorf("Bool(%q, %q): expected %v got %v", test.policy, test.value, test.expected, got)
+		}
+	}
+}
+
+func TestConditionMatches(t *testing.T) {
+	ctx := Context{}
+	ctx.Set(policy.VarUsername, "alice")
+	ctx.Set(policy.PrincipalTag("team"), "fin*")
+	ctx.Set(policy.VarTagKeys, "project", "owner")
+	ctx.Set(policy.VarSourceArn, "arn:aws:sns:eu-west-1:111122223333:alerts")
+	ctx.Set(policy.VarSourceIp, "2001:db8::7")
+	ctx.Set(policy.VarMultiFactorAuthAge, "900")
+	tests := []struct {
+		condition policy.ConditionType
+		key       policy.ConditionVariable
+		values    []string
+		expected  bool
+	}{
+		{policy.ConditionStringEquals, policy.VarUsername, []string{"bob", "alice"}, true},
+		{policy.ConditionStringEquals, "AWS:UserName", []string{"alice"}, true},
+		{policy.ConditionStringNotEquals, policy.VarUsername, []string{"bob", "alice"}, false},
+		{policy.ConditionStringNotEquals, policy.VarUsername, []string{"bob"}, true},
+		{policy.ConditionStringEquals, policy.PrincipalTag("team"), []string{"${aws:PrincipalTag/team}"}, true},
+		// Wildcards in variable values match literally
+		{policy.ConditionStringLike, policy.VarUsername, []string{"${aws:PrincipalTag/team}"}, false},
+		{policy.ConditionStringLike, policy.PrincipalTag("team"), []string{"${aws:PrincipalTag/team}"}, true},
+		{policy.ConditionStringEquals, policy.VarUsername, []string{"${aws:userid}"}, false},
+		// Absent keys
+		{policy.ConditionStringEquals, policy.VarUsedId, []string{"AIDA"}, false},
+		{policy.ConditionStringEquals.IfExists(), policy.VarUsedId, []string{"AIDA"}, true},
+		{policy.ConditionStringNotEquals, policy.VarUsedId, []string{"AIDA"}, true},
+		{policy.ConditionStringNotEquals.WithSetOperator(policy.ForAnyValue), policy.VarUsedId, []string{"AIDA"}, false},
+		{policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues), policy.VarUsedId, []string{"AIDA"}, true},
+		// Multivalued keys
+		{policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues), policy.VarTagKeys, []string{"project", "owner", "cost"}, true},
+		{policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues), policy.VarTagKeys, []string{"project"}, false},
+		{policy.ConditionStringEquals.WithSetOperator(policy.ForAnyValue), policy.VarTagKeys, []string{"owner"}, true},
+		{policy.ConditionStringEquals.WithSetOperator(policy.ForAnyValue), policy.VarTagKeys, []string{"cost"}, false},
+		{policy.ConditionNull, policy.VarUsedId, []string{"true"}, true},
+		{policy.ConditionNull, policy.VarUsername, []string{"true"}, false},
+		{policy.ConditionNull, policy.VarUsername, []string{"false"}, true},
+		{policy.ConditionArnLike, policy.VarSourceArn, []string{"arn:aws:sns:*:111122223333:*"}, true},
+		{policy.ConditionArnNotEquals, policy.VarSourceArn, []string{"arn:aws:sns:*:111122223333:*"}, false},
+		{policy.ConditionIpAddress, policy.VarSourceIp, []string{"203.0.113.0/24", "2001:db8::/32"}, true},
+		{policy.ConditionNotIpAddress, policy.VarSourceIp, []string{"203.0.113.0/24", "2001:db8::/32"}, false},
+		{policy.ConditionIpAddress, policy.VarVpcSourceIp, []string{"10.0.0.0/8"}, false},
+		{policy.ConditionNotIpAddress, policy.VarVpcSourceIp, []string{"10.0.0.0/8"}, true},
+		{policy.ConditionNumericLessThan, policy.VarMultiFactorAuthAge, []string{"3600"}, true},
+		{policy.ConditionNumericGreaterThan, policy.VarMultiFactorAuthAge, []string{"3600"}, false},
+		{policy.ConditionBool, policy.VarMultiFactorAuthPresent, []string{"false"}, false},
+		{policy.ConditionBool.IfExists(), policy.VarMultiFactorAuthPresent, []string{"false"}, true},
+		{policy.ConditionBool, policy.VarMultiFactorAuthPresent, []string{"true"}, false},
+	}
+	for _, test := range tests {
+		got, ok := conditionMatches(test.condition, test.key, test.values, ctx)
+		if !ok {
+			t.Errorf("%s %s: operator not supported", test.condition, test.key)
+		}
+		if got != test.expected {
+			t.Errorf("%s %s %v: expected %v got %v", test.condition, test.key, test.values, test.expected, got)
+		}
+	}
+}
+
+func TestEvaluateConditions(t *testing.T) {
+	p, err := policy.LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[
+		{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::home/${aws:username}/*"},
+		{"Effect":"Allow","Action":"ec2:StartInstances","Resource":"*","Condition":{"StringEquals":{"aws:ResourceTag/team":"${aws:PrincipalTag/team}"}}},
+		{"Effect":"Deny","Action":"*","Resource":"*","Condition":{"StringNotEquals":{"aws:RequestedRegion":["eu-west-1","eu-central-1"]}}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	user := "arn:aws:iam::111122223333:user/alice"
+	instance := "arn:aws:ec2:eu-west-1:111122223333:instance/i-0123456789abcdef0"
+
+	r := NewRequest(user, "s3:GetObject", "arn:aws:s3:::home/alice/notes.txt")
+	r.Context.Set(policy.VarRequestedRegion, "eu-west-1")
+	if got := Evaluate(p, *r); got != Allow {
+		t.Errorf("Expected allow for own home got %s", got)
+	}
+	r.Resource = "arn:aws:s3:::home/bob/notes.txt"
+	if got := Evaluate(p, *r); got != ImplicitDeny {
+		t.Errorf("Expected implicit deny for other home got %s", got)
+	}
+
+	r = NewRequest(user, "ec2:StartInstances", instance)
+	r.Context.Set(policy.VarRequestedRegion, "eu-west-1")
+	r.SetPrincipalTag("team", "finance")
+	r.SetResourceTag("team", "finance")
+	if got := Evaluate(p, *r); got != Allow {
+		t.Errorf("Expected allow for own team got %s", got)
+	}
+	r.SetResourceTag("team", "sales")
+	if got := Evaluate(p, *r); got != ImplicitDeny {
+		t.Errorf("Expected implicit deny for other team got %s", got)
+	}
+	r.SetResourceTag("team", "finance")
+	r.Context.Set(policy.VarRequestedRegion, "us-east-1")
+	if got := Evaluate(p, *r); got != ExplicitDeny {
+		t.Errorf("Expected explicit deny outside regions got %s", got)
+	}
+}
+
+func TestEvaluateDateConditions(t *testing.T) {
+	p, err := policy.LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[
+		{"Effect":"Allow","Action":"s3:GetObject","Resource":"*","Condition":{"DateGreaterThan":{"aws:CurrentTime":"2026-10-01T00:00:00Z"},"DateLessThan":{"aws:CurrentTime":"2026-11-01T00:00:00Z"}}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	r := NewRequest("arn:aws:iam::111122223333:user/alice", "s3:GetObject", "arn:aws:s3:::reports/q3.csv")
+	if got := Evaluate(p, *r); got != ImplicitDeny {
+		t.Errorf("Expected implicit deny without time got %s", got)
+	}
+	r.SetCurrentTime(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	if got := Evaluate(p, *r); got != Allow {
+		t.Errorf("Expected allow within window got %s", got)
+	}
+	r.SetCurrentTime(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+	if got := Evaluate(p, *r); got != ImplicitDeny {
+		t.Errorf("Expected implicit deny after window got %s", got)
+	}
+}
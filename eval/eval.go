@@ -0,0 +1,148 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package eval evaluates policies against requests the way IAM does: an
+// explicit Deny in any applicable statement wins, otherwise an applicable
+// Allow allows the request, otherwise the request is implicitly denied.
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+// Decision is the outcome of evaluating a request
+type Decision int
+
+const (
+	// No statement allows the request
+	ImplicitDeny Decision = iota
+	// A statement allows the request and none denies it
+	Allow
+	// A statement denies the request
+	ExplicitDeny
+)
+
+var decisionNames = map[Decision]string{
+	ImplicitDeny: "implicit deny",
+	Allow:        "allow",
+	ExplicitDeny: "explicit deny",
+}
+
+func (d Decision) String() string {
+	if name, ok := decisionNames[d]; ok {
+		return name
+	}
+	return fmt.Sprintf("Decision(%d)", int(d))
+}
+
+// Evaluate decides whether the policy allows the request. Statements with a
+// Principal or NotPrincipal, as in resource-based policies, only apply to the
+// principals they name.
+//
+// Conditions are evaluated against the request context. Statements with a
+// condition operator that is not supported are assumed to deny but not to
+// allow, so the decision is never more permissive than the policy.
+func Evaluate(p *policy.Policy, req Request) Decision {
+	decision := ImplicitDeny
+	for _, stmt := range p.Statement {
+		if !statementApplies(stmt, req) {
+			continue
+		}
+		match, ok := conditionsMatch(stmt, req.Context)
+		if !ok && stmt.Effect == policy.Deny {
+			return ExplicitDeny
+		}
+		if !match {
+			continue
+		}
+		if stmt.Effect == policy.Deny {
+			return ExplicitDeny
+		}
+		decision = Allow
+	}
+	return decision
+}
+
+// statementApplies reports whether the principal, action and resource of the
+// request match the statement
+func statementApplies(stmt *policy.Statement, req Request) bool {
+	if !emptyPrincipal(stmt.Principal) && !principalMatches(stmt.Principal, req.Principal) {
+		return false
+	}
+	if !emptyPrincipal(stmt.NotPrincipal) && principalMatches(stmt.NotPrincipal, req.Principal) {
+		return false
+	}
+	if !matchesList(stmt.Action, stmt.NotAction, req.Action, MatchAction) {
+		return false
+	}
+	// Statements of resource-based policies may leave out the Resource, they
+	// apply to the resource the policy is attached to
+	if len(stmt.ResourceList()) == 0 && len(stmt.NotResource) == 0 {
+		return true
+	}
+	return matchesList(stmt.ResourceList(), stmt.NotResource, req.Resource, func(pattern, arn string) bool {
+		return MatchResource(pattern, arn, req.Context)
+	})
+}
+
+func emptyPrincipal(p *policy.Principal) bool {
+	return p == nil || len(p.Aws)+len(p.Service)+len(p.Federated)+len(p.CanonicalUser) == 0
+}
+
+// principalMatches reports whether the principal element names the principal.
+// An account, as id or root ARN, names every principal in the account, other
+// ARNs must match exactly as principals can not contain wildcards.
+func principalMatches(p *policy.Principal, principal string) bool {
+	account := arnAccount(principal)
+	for _, aws := range p.Aws {
+		if aws == "*" || aws == principal {
+			return true
+		}
+		if account != "" && (aws == account || aws == "arn:aws:iam::"+account+":root") {
+			return true
+		}
+	}
+	for _, list := range [][]string{p.Service, p.Federated, p.CanonicalUser} {
+		for _, name := range list {
+			if name == principal {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesList reports whether the value matches one of the patterns in list,
+// or none of the patterns in notList when that is used
+func matchesList(list, notList []string, value string, match func(pattern, value string) bool) bool {
+	if len(notList) > 0 {
+		for _, pattern := range notList {
+			if match(pattern, value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, pattern := range list {
+		if match(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// arnAccount returns the account id of an ARN
+func arnAccount(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
@@ -0,0 +1,86 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestEvaluate(t *testing.T) {
+	p, err := policy.LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[
+		{"Effect":"Allow","Action":"s3:Get*","Resource":"arn:aws:s3:::reports/*"},
+		{"Effect":"Allow","Action":"s3:PutObject","Resource":"arn:aws:s3:::reports/*","Condition":{"Bool":{"aws:SecureTransport":"true"}}},
+		{"Effect":"Deny","Action":"s3:*","Resource":"arn:aws:s3:::reports/private/*"},
+		{"Effect":"Deny","Action":"s3:DeleteObject","Resource":"*","Condition":{"Bool":{"aws:MultiFactorAuthPresent":"false"}}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	user := "arn:aws:iam::111122223333:user/alice"
+	tests := []struct {
+		action   string
+		resource string
+		expected Decision
+	}{
+		{"s3:GetObject", "arn:aws:s3:::reports/2026/q1.csv", Allow},
+		{"S3:getobject", "arn:aws:s3:::reports/2026/q1.csv", Allow},
+		{"s3:GetObject", "arn:aws:s3:::reports/private/salaries.csv", ExplicitDeny},
+		{"s3:GetObject", "arn:aws:s3:::other/q1.csv", ImplicitDeny},
+		{"s3:PutObject", "arn:aws:s3:::reports/2026/q1.csv", ImplicitDeny},
+		// The MFA key is absent, which is not false
+		{"s3:DeleteObject", "arn:aws:s3:::reports/2026/q1.csv", ImplicitDeny},
+	}
+	for _, test := range tests {
+		got := Evaluate(p, Request{Principal: user, Action: test.action, Resource: test.resource})
+		if got != test.expected {
+			t.Errorf("%s on %s: expected %s got %s", test.action, test.resource, test.expected, got)
+		}
+	}
+
+	r := NewRequest(user, "s3:PutObject", "arn:aws:s3:::reports/2026/q1.csv")
+	r.SetSecureTransport(true)
+	if got := Evaluate(p, *r); got != Allow {
+		t.Errorf("Expected allow over TLS got %s", got)
+	}
+	r.Action = "s3:DeleteObject"
+	r.Context.Set(policy.VarMultiFactorAuthPresent, "false")
+	if got := Evaluate(p, *r); got != ExplicitDeny {
+		t.Errorf("Expected explicit deny without MFA got %s", got)
+	}
+}
+
+func TestEvaluatePrincipal(t *testing.T) {
+	p, err := policy.LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[
+		{"Effect":"Allow","Principal":{"AWS":"111122223333"},"Action":"sqs:SendMessage"},
+		{"Effect":"Allow","Principal":{"Service":"sns.amazonaws.com"},"Action":"sqs:SendMessage"},
+		{"Effect":"Deny","NotPrincipal":{"AWS":"arn:aws:iam::111122223333:role/admin"},"Action":"sqs:DeleteQueue"}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	queue := "arn:aws:sqs:eu-west-1:111122223333:orders"
+	tests := []struct {
+		principal string
+		action    string
+		expected  Decision
+	}{
+		{"arn:aws:iam::111122223333:role/worker", "sqs:SendMessage", Allow},
+		{"sns.amazonaws.com", "sqs:SendMessage", Allow},
+		{"arn:aws:iam::444455556666:role/worker", "sqs:SendMessage", ImplicitDeny},
+		{"arn:aws:iam::111122223333:role/worker", "sqs:DeleteQueue", ExplicitDeny},
+		{"arn:aws:iam::111122223333:role/admin", "sqs:DeleteQueue", ImplicitDeny},
+	}
+	for _, test := range tests {
+		got := Evaluate(p, Request{Principal: test.principal, Action: test.action, Resource: queue})
+		if got != test.expected {
+			t.Errorf("%s by %s: expected %s got %s", test.action, test.principal, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"strings"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+// token is a character of a pattern, wildcard tokens are * or ? characters
+// that match other characters rather than themselves
+type token struct {
+	c        byte
+	wildcard bool
+}
+
+// tokens returns the tokens of a pattern without policy variables
+func tokens(pattern string) []token {
+	result := make([]token, len(pattern))
+	for i := 0; i < len(pattern); i++ {
+		result[i] = token{pattern[i], pattern[i] == '*' || pattern[i] == '?'}
+	}
+	return result
+}
+
+// resolve returns the tokens of a pattern with the policy variables replaced
+// by their values in the context. Variable values match literally, ${*}, ${?}
+// and ${$} stand for a literal *, ? and $. Returns false if a variable has
+// no default and its key is absent or has multiple values.
+func resolve(pattern string, ctx Context) ([]token, bool) {
+	result := make([]token, 0, len(pattern))
+	for len(pattern) > 0 {
+		start := strings.Index(pattern, "${")
+		end := -1
+		if start >= 0 {
+			end = strings.IndexByte(pattern[start:], '}')
+		}
+		if end < 0 {
+			result = append(result, tokens(pattern)...)
+			break
+		}
+		end += start
+		result = append(result, tokens(pattern[:start])...)
+		value, ok := variableValue(pattern[start+2:end], ctx)
+		if !ok {
+			return nil, false
+		}
+		for i := 0; i < len(value); i++ {
+			result = append(result, token{value[i], false})
+		}
+		pattern = pattern[end+1:]
+	}
+	return result, true
+}
+
+// variableValue returns the value of the variable ${name}, name may include a
+// default value as in ${aws:username, 'nobody'}
+func variableValue(name string, ctx Context) (string, bool) {
+	switch name {
+	case "*", "?", "$":
+		return name, true
+	}
+	key, fallback, hasDefault := strings.Cut(name, ",")
+	values, _ := ctx.Get(policy.ConditionVariable(strings.TrimSpace(key)))
+	if len(values) == 1 {
+		return values[0], true
+	}
+	if !hasDefault {
+		return "", false
+	}
+	fallback = strings.TrimSpace(fallback)
+	if len(fallback) < 2 || fallback[0] != '\'' || fallback[len(fallback)-1] != '\'' {
+		return "", false
+	}
+	return fallback[1 : len(fallback)-1], true
+}
+
+// matchTokens reports whether s matches the pattern tokens, in which a *
+// matches any sequence of characters, including none and including / and :,
+// and a ? matches any single character
+func matchTokens(pattern []token, s string) bool {
+	// Position in pattern and s to resume from after the last *
+	star, next := -1, 0
+	p, i := 0, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p].wildcard && pattern[p].c == '*':
+			star, next = p, i
+			p++
+		case p < len(pattern) && (pattern[p].wildcard || pattern[p].c == s[i]):
+			p++
+			i++
+		case star >= 0:
+			next++
+			p, i = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p].wildcard && pattern[p].c == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// wildcardMatch reports whether s matches the IAM wildcard pattern, in which
+// * matches any sequence of characters and ? any single character
+func wildcardMatch(pattern, s string) bool {
+	return matchTokens(tokens(pattern), s)
+}
+
+// MatchAction reports whether the action matches the action pattern. Actions
+// are compared case-insensitively, the service prefix and the action name are
+// matched separately so wildcards do not span the colon: s3* does not match
+// s3:GetObject, and s3:* does not match s3express:CreateSession. The pattern *
+// matches every action.
+func MatchAction(pattern, action string) bool {
+	if pattern == "*" {
+		return true
+	}
+	patternService, patternName, ok := strings.Cut(strings.ToLower(pattern), ":")
+	if !ok {
+		return false
+	}
+	service, name, ok := strings.Cut(strings.ToLower(action), ":")
+	if !ok {
+		return false
+	}
+	return wildcardMatch(patternService, service) && wildcardMatch(patternName, name)
+}
+
+// MatchResource reports whether the ARN matches the pattern of a Resource or
+// NotResource element. Policy variables in the pattern are replaced by their
+// values in the context, a pattern using a variable without a value does not
+// match. Wildcards span the separators of the ARN, arn:aws:s3:::* matches
+// arn:aws:s3:::bucket/key, and * matches every resource.
+func MatchResource(pattern, arn string, ctx Context) bool {
+	resolved, ok := resolve(pattern, ctx)
+	return ok && matchTokens(resolved, arn)
+}
+
+// MatchArn reports whether the ARN matches the pattern as the ArnLike
+// condition operator does. The six colon-separated components of the ARN are
+// matched separately, so wildcards do not span the colons between them.
+// Policy variables are resolved as by MatchResource.
+func MatchArn(pattern, arn string, ctx Context) bool {
+	patternParts := splitArn(pattern)
+	parts := strings.SplitN(arn, ":", 6)
+	if len(patternParts) != 6 || len(parts) != 6 {
+		return false
+	}
+	for i := range parts {
+		resolved, ok := resolve(patternParts[i], ctx)
+		if !ok || !matchTokens(resolved, parts[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitArn splits an ARN pattern in at most six components, ignoring colons
+// in policy variables
+func splitArn(s string) []string {
+	parts := make([]string, 0, 6)
+	start, inVariable := 0, false
+	for i := 0; i < len(s) && len(parts) < 5; i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			inVariable = true
+		case s[i] == '}':
+			inVariable = false
+		case s[i] == ':' && !inVariable:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestMatchAction(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		action   string
+		expected bool
+	}{
+		{"*", "s3:GetObject", true},
+		{"s3:*", "s3:GetObject", true},
+		{"S3:getobject", "s3:GetObject", true},
+		{"s3:Get*", "s3:GetObjectAcl", true},
+		{"s3:Get?bject", "s3:GetObject", true},
+		{"s3:Get?bject", "s3:GetObjects", false},
+		{"s3:*Object", "s3:PutObject", true},
+		{"*:GetObject", "s3:GetObject", true},
+		{"s3:*", "s3express:CreateSession", false},
+		{"s3*", "s3:GetObject", false},
+		{"s3*:*", "s3express:CreateSession", true},
+		{"s3:GetObject", "s3:GetObjectAcl", false},
+		{"s3:GetObject", "GetObject", false},
+	}
+	for _, test := range tests {
+		if got := MatchAction(test.pattern, test.action); got != test.expected {
+			t.Errorf("MatchAction(%q, %q): expected %v got %v", test.pattern, test.action, test.expected, got)
+		}
+	}
+}
+
+func TestMatchResource(t *testing.T) {
+	ctx := Context{}
+	ctx.Set(policy.VarUsername, "alice")
+	ctx.Set(policy.PrincipalTag("team"), "fin*")
+	tests := []struct {
+		pattern  string
+		arn      string
+		expected bool
+	}{
+		{"*", "arn:aws:s3:::reports/q1.csv", true},
+		{"arn:aws:s3:::*", "arn:aws:s3:::reports/q1.csv", true},
+		{"arn:aws:s3:::reports/*", "arn:aws:s3:::reports/2026/q1.csv", true},
+		{"arn:aws:s3:::reports/q?.csv", "arn:aws:s3:::reports/q1.csv", true},
+		{"arn:aws:s3:::Reports/*", "arn:aws:s3:::reports/q1.csv", false},
+		{"arn:*:iam::*:user/*", "arn:aws:iam::111122223333:user/alice", true},
+		{"arn:aws:s3:::home/${aws:username}/*", "arn:aws:s3:::home/alice/notes.txt", true},
+		{"arn:aws:s3:::home/${aws:username}/*", "arn:aws:s3:::home/bob/notes.txt", false},
+		{"arn:aws:s3:::home/${aws:userid}/*", "arn:aws:s3:::home/alice/notes.txt", false},
+		{"arn:aws:s3:::home/${aws:userid, 'alice'}/*", "arn:aws:s3:::home/alice/notes.txt", true},
+		// Variable values match literally
+		{"arn:aws:s3:::teams/${aws:PrincipalTag/team}", "arn:aws:s3:::teams/finance", false},
+		{"arn:aws:s3:::teams/${aws:PrincipalTag/team}", "arn:aws:s3:::teams/fin*", true},
+		{"arn:aws:s3:::literal/${*}", "arn:aws:s3:::literal/*", true},
+		{"arn:aws:s3:::literal/${*}", "arn:aws:s3:::literal/x", false},
+	}
+	for _, test := range tests {
+		if got := MatchResource(test.pattern, test.arn, ctx); got != test.expected {
+			t.Errorf("MatchResource(%q, %q): expected %v got %v", test.pattern, test.arn, test.expected, got)
+		}
+	}
+}
+
+func TestMatchArn(t *testing.T) {
+	ctx := Context{}
+	ctx.Set(policy.VarPrincipalAccount, "111122223333")
+	tests := []struct {
+		pattern  string
+		arn      string
+		expected bool
+	}{
+		{"arn:aws:sns:*:111122223333:*", "arn:aws:sns:eu-west-1:111122223333:alerts", true},
+		{"arn:aws:sns:*:${aws:PrincipalAccount}:*", "arn:aws:sns:eu-west-1:111122223333:alerts", true},
+		{"arn:aws:sns:*:${aws:PrincipalAccount}:*", "arn:aws:sns:eu-west-1:444455556666:alerts", false},
+		// A wildcard does not span the colons between the components
+		{"arn:aws:sns:*:alerts", "arn:aws:sns:eu-west-1:111122223333:alerts", false},
+		{"arn:aws:lambda:*:*:function:*", "arn:aws:lambda:eu-west-1:111122223333:function:resize:live", true},
+		{"arn:aws:s3:::uploads/*", "arn:aws:s3:::uploads/2026/photo.jpg", true},
+		{"arn:aws:s3:::uploads", "not-an-arn", false},
+	}
+	for _, test := range tests {
+		if got := MatchArn(test.pattern, test.arn, ctx); got != test.expected {
+			t.Errorf("MatchArn(%q, %q): expected %v got %v", test.pattern, test.arn, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+// Error when a forwarding header can not be parsed
+type InvalidForwardedError string
+
+func (e InvalidForwardedError) Error() string {
+	return fmt.Sprintf("Invalid forwarded address %q", string(e))
+}
+
+// Default names of the headers carrying the VPC endpoint and VPC a request
+// was made through, as set by API Gateway for private APIs
+const (
+	DefaultVpceHeader = "X-Amzn-Vpce-Id"
+	DefaultVpcHeader  = "X-Amzn-Vpc-Id"
+)
+
+// ProxyConfig describes the load balancers and proxies in front of a service
+// that evaluates policies itself, so the network keys of a request can be
+// derived from the forwarding headers they add.
+//
+// Headers are only trusted when they were set by a trusted proxy, anyone can
+// send an X-Forwarded-For header. Requests that do not come from a trusted
+// proxy get their keys from the connection only.
+type ProxyConfig struct {
+	// Addresses of the trusted proxies, e.g. the subnets of the load
+	// balancer. Forwarded addresses in these ranges are skipped when looking
+	// for the client address.
+	TrustedProxies []netip.Prefix
+	// Headers carrying the VPC endpoint id and VPC id, default to
+	// DefaultVpceHeader and DefaultVpcHeader
+	VpceHeader string
+	VpcHeader  string
+}
+
+func (c ProxyConfig) trusted(addr netip.Addr) bool {
+	for _, prefix := range c.TrustedProxies {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply sets aws:SourceIp or aws:VpcSourceIp, aws:SecureTransport,
+// aws:SourceVpce and aws:SourceVpc on the request. remote is the address of
+// the peer of the connection, tls whether the connection uses TLS.
+//
+// When the peer is a trusted proxy the client address is the last address in
+// X-Forwarded-For that is not a trusted proxy, TLS is taken from the last
+// X-Forwarded-Proto value, and the VPC headers are used. The proxies must
+// remove VPC headers sent by clients. Requests made through a VPC endpoint
+// get aws:VpcSourceIp instead of aws:SourceIp, as in AWS.
+func (c ProxyConfig) Apply(r *Request, remote string, tls bool, header http.Header) error {
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	client, err := netip.ParseAddr(remote)
+	if err != nil {
+		return InvalidForwardedError(remote)
+	}
+	client = client.Unmap()
+	if !c.trusted(client) {
+		setNetwork(r, client, tls, "", "")
+		return nil
+	}
+	if client, err = c.forwardedClient(client, header.Values("X-Forwarded-For")); err != nil {
+		return err
+	}
+	if proto := lastValue(header.Values("X-Forwarded-Proto")); proto != "" {
+		tls = strings.EqualFold(proto, "https")
+	}
+	vpce := header.Get(defaultString(c.VpceHeader, DefaultVpceHeader))
+	vpc := header.Get(defaultString(c.VpcHeader, DefaultVpcHeader))
+	setNetwork(r, client, tls, vpce, vpc)
+	return nil
+}
+
+// ApplyHTTP sets the network keys of the request from an HTTP request
+// received by the service, see Apply
+func (c ProxyConfig) ApplyHTTP(r *Request, req *http.Request) error {
+	return c.Apply(r, req.RemoteAddr, req.TLS != nil, req.Header)
+}
+
+// ApplyALBEvent sets aws:SourceIp and aws:SecureTransport on the request from
+// the event an Application Load Balancer invokes a Lambda function with. The
+// load balancer appends the address of its peer to X-Forwarded-For and sets
+// X-Forwarded-Proto, proxies in front of it such as CloudFront must be listed
+// in TrustedProxies. The VPC headers are not used, the load balancer passes
+// them on from clients.
+func (c ProxyConfig) ApplyALBEvent(r *Request, event []byte) error {
+	var v struct {
+		Headers           map[string]string   `json:"headers"`
+		MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	}
+	if err := json.Unmarshal(event, &v); err != nil {
+		return err
+	}
+	header := make(http.Header)
+	for name, value := range v.Headers {
+		header.Add(name, value)
+	}
+	for name, values := range v.MultiValueHeaders {
+		header.Del(name)
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+	client, err := c.forwardedClient(netip.Addr{}, header.Values("X-Forwarded-For"))
+	if err != nil {
+		return err
+	}
+	if !client.IsValid() {
+		return InvalidForwardedError("")
+	}
+	setNetwork(r, client, strings.EqualFold(lastValue(header.Values("X-Forwarded-Proto")), "https"), "", "")
+	return nil
+}
+
+func setNetwork(r *Request, client netip.Addr, tls bool, vpce, vpc string) {
+	r.context().Set(policy.VarSecureTransport, strconv.FormatBool(tls))
+	if vpce == "" {
+		r.Context.Set(policy.VarSourceIp, client.String())
+		return
+	}
+	r.Context.Set(policy.VarVpcSourceIp, client.String())
+	r.Context.Set(policy.VarSourceVpce, vpce)
+	if vpc != "" {
+		r.Context.Set(policy.VarSourceVpc, vpc)
+	}
+}
+
+// forwardedClient returns the last address in the X-Forwarded-For values that
+// is not a trusted proxy, or the first address if all are. Returns peer if
+// there are no forwarded addresses.
+func (c ProxyConfig) forwardedClient(peer netip.Addr, values []string) (netip.Addr, error) {
+	addrs := make([]netip.Addr, 0)
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return netip.Addr{}, InvalidForwardedError(s)
+			}
+			addrs = append(addrs, addr.Unmap())
+		}
+	}
+	if len(addrs) == 0 {
+		return peer, nil
+	}
+	for i := len(addrs) - 1; i >= 0; i-- {
+		if !c.trusted(addrs[i]) {
+			return addrs[i], nil
+		}
+	}
+	return addrs[0], nil
+}
+
+// lastValue returns the last of the comma-separated values in the headers,
+// which was added by the nearest proxy
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	last := values[len(values)-1]
+	return strings.TrimSpace(last[strings.LastIndex(last, ",")+1:])
+}
+
+func defaultString(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"net/http"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestProxyConfigApply(t *testing.T) {
+	c := ProxyConfig{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}}
+	tests := []struct {
+		name     string
+		remote   string
+		tls      bool
+		header   http.Header
+		expected Context
+	}{
+		{
+			"direct client, headers ignored",
+			"203.0.113.7:51234", true,
+			http.Header{"X-Forwarded-For": {"198.51.100.1"}, "X-Forwarded-Proto": {"http"}, "X-Amzn-Vpce-Id": {"vpce-1a2b3c4d"}},
+			Context{"aws:securetransport": {"true"}, "aws:sourceip": {"203.0.113.7"}},
+		},
+		{
+			"through load balancer",
+			"10.0.1.5:443", false,
+			http.Header{"X-Forwarded-For": {"198.51.100.1, 203.0.113.7"}, "X-Forwarded-Proto": {"https"}},
+			Context{"aws:securetransport": {"true"}, "aws:sourceip": {"203.0.113.7"}},
+		},
+		{
+			"chained trusted proxies",
+			"10.0.1.5:443", false,
+			http.Header{"X-Forwarded-For": {"203.0.113.7", "10.0.2.9"}, "X-Forwarded-Proto": {"https", "http"}},
+			Context{"aws:securetransport": {"false"}, "aws:sourceip": {"203.0.113.7"}},
+		},
+		{
+			"through VPC endpoint",
+			"[::ffff:10.0.1.5]:443", true,
+			http.Header{"X-Forwarded-For": {"10.20.0.8"}, "X-Amzn-Vpce-Id": {"vpce-1a2b3c4d"}, "X-Amzn-Vpc-Id": {"vpc-0a1b2c3d"}},
+			Context{"aws:securetransport": {"true"}, "aws:vpcsourceip": {"10.20.0.8"}, "aws:sourcevpce": {"vpce-1a2b3c4d"}, "aws:sourcevpc": {"vpc-0a1b2c3d"}},
+		},
+	}
+	for _, test := range tests {
+		r := &Request{}
+		if err := c.Apply(r, test.remote, test.tls, test.header); err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(r.Context, test.expected) {
+			t.Errorf("%s: expected %v got %v", test.name, test.expected, r.Context)
+		}
+	}
+
+	err := c.Apply(&Request{}, "10.0.1.5:443", false, http.Header{"X-Forwarded-For": {"not-an-ip"}})
+	if err != InvalidForwardedError("not-an-ip") {
+		t.Errorf("Expected InvalidForwardedError got %v", err)
+	}
+}
+
+func TestProxyConfigApplyALBEvent(t *testing.T) {
+	event := `{
+		"requestContext": {"elb": {"targetGroupArn": "arn:aws:elasticloadbalancing:eu-west-1:111122223333:targetgroup/api/0123456789abcdef"}},
+		"httpMethod": "GET",
+		"path": "/reports",
+		"headers": {"x-forwarded-for": "198.51.100.1, 203.0.113.7, 130.176.0.12", "x-forwarded-proto": "https", "x-amzn-vpce-id": "vpce-spoofed"}
+	}`
+	cloudFront := ProxyConfig{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("130.176.0.0/16")}}
+	r := &Request{}
+	if err := cloudFront.ApplyALBEvent(r, []byte(event)); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := Context{"aws:securetransport": {"true"}, "aws:sourceip": {"203.0.113.7"}}
+	if !reflect.DeepEqual(r.Context, expected) {
+		t.Errorf("Expected %v got %v", expected, r.Context)
+	}
+}
@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+// Context holds the values of the condition keys of a request. Keys are case
+// insensitive, a key may have multiple values, e.g. aws:TagKeys.
+type Context map[string][]string
+
+// Set sets the values of the key, replacing any previous values
+func (c Context) Set(key policy.ConditionVariable, values ...string) {
+	c[strings.ToLower(string(key))] = values
+}
+
+// Add adds a value to the values of the key
+func (c Context) Add(key policy.ConditionVariable, value string) {
+	k := strings.ToLower(string(key))
+	c[k] = append(c[k], value)
+}
+
+// Get returns the values of the key, and whether the key is present
+func (c Context) Get(key policy.ConditionVariable) ([]string, bool) {
+	values, ok := c[strings.ToLower(string(key))]
+	return values, ok
+}
+
+// Request is a request made to AWS
+type Request struct {
+	// ARN of the principal making the request, or the service principal for
+	// requests made by a service
+	Principal string
+	// Action requested, e.g. s3:GetObject
+	Action string
+	// ARN of the resource the action is performed on
+	Resource string
+	// Values of the condition keys
+	Context Context
+}
+
+// NewRequest creates a request with the principal keys set: aws:PrincipalArn
+// and aws:PrincipalAccount, aws:PrincipalType and aws:username for IAM users,
+// or aws:PrincipalIsAWSService and aws:PrincipalServiceName for services
+func NewRequest(principal, action, resource string) *Request {
+	r := &Request{Principal: principal, Action: action, Resource: resource, Context: make(Context)}
+	if _, err := policy.ParseServicePrincipal(principal); err == nil {
+		r.Context.Set(policy.VarPrincipalIsAWSService, "true")
+		r.Context.Set(policy.VarPrincipalServiceName, principal)
+		return r
+	}
+	r.Context.Set(policy.VarPrincipalIsAWSService, "false")
+	r.Context.Set(policy.VarPrincipalArn, principal)
+	if account := arnAccount(principal); account != "" {
+		r.Context.Set(policy.VarPrincipalAccount, account)
+	}
+	name := ""
+	if parts := strings.SplitN(principal, ":", 6); len(parts) == 6 {
+		name = parts[5]
+	}
+	switch {
+	case name == "root":
+		r.Context.Set(policy.VarPrincipalType, "Account")
+	case strings.HasPrefix(name, "user/"):
+		r.Context.Set(policy.VarPrincipalType, "User")
+		r.Context.Set(policy.VarUsername, name[strings.LastIndex(name, "/")+1:])
+	case strings.HasPrefix(name, "assumed-role/"):
+		r.Context.Set(policy.VarPrincipalType, "AssumedRole")
+	case strings.HasPrefix(name, "federated-user/"):
+		r.Context.Set(policy.VarPrincipalType, "FederatedUser")
+	}
+	return r
+}
+
+func (r *Request) context() Context {
+	if r.Context == nil {
+		r.Context = make(Context)
+	}
+	return r.Context
+}
+
+// SetSourceIp sets the IP address the request is made from
+func (r *Request) SetSourceIp(ip string) {
+	r.context().Set(policy.VarSourceIp, ip)
+}
+
+// SetSecureTransport sets whether the request is made over TLS
+func (r *Request) SetSecureTransport(secure bool) {
+	r.context().Set(policy.VarSecureTransport, strconv.FormatBool(secure))
+}
+
+// SetMultiFactorAuth marks the request as made with credentials that were
+// authenticated with MFA the given time ago. Without it the MFA keys are
+// absent, as for requests made with long-term access keys.
+func (r *Request) SetMultiFactorAuth(age time.Duration) {
+	r.context().Set(policy.VarMultiFactorAuthPresent, "true")
+	r.context().Set(policy.VarMultiFactorAuthAge, strconv.FormatInt(int64(age/time.Second), 10))
+}
+
+// SetCurrentTime sets the time of the request, as aws:CurrentTime and
+// aws:EpochTime
+func (r *Request) SetCurrentTime(t time.Time) {
+	r.context().Set(policy.VarCurrentTime, t.UTC().Format(time.RFC3339))
+	r.context().Set(policy.VarEpochTime, strconv.FormatInt(t.Unix(), 10))
+}
+
+// SetPrincipalTag sets a tag of the principal making the request
+func (r *Request) SetPrincipalTag(key, value string) {
+	r.context().Set(policy.PrincipalTag(key), value)
+}
+
+// SetRequestTag sets a tag passed in the request, the tag key is added to
+// aws:TagKeys
+func (r *Request) SetRequestTag(key, value string) {
+	r.context().Set(policy.RequestTag(key), value)
+	if keys, _ := r.Context.Get(policy.VarTagKeys); !contains(keys, key) {
+		r.Context.Add(policy.VarTagKeys, key)
+	}
+}
+
+// SetResourceTag sets a tag of the resource the request is made on
+func (r *Request) SetResourceTag(key, value string) {
+	r.context().Set(policy.ResourceTag(key), value)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package eval
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestNewRequest(t *testing.T) {
+	r := NewRequest("arn:aws:iam::111122223333:user/engineering/alice", "s3:PutObject", "arn:aws:s3:::reports/q1.csv")
+	r.SetSourceIp("203.0.113.7")
+	r.SetMultiFactorAuth(90 * time.Second)
+	r.SetCurrentTime(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	r.SetPrincipalTag("team", "finance")
+	r.SetRequestTag("project", "q1")
+	r.SetRequestTag("project", "q2")
+
+	expected := Context{
+		"aws:principalisawsservice":  {"false"},
+		"aws:principalarn":           {"arn:aws:iam::111122223333:user/engineering/alice"},
+		"aws:principalaccount":       {"111122223333"},
+		"aws:principaltype":          {"User"},
+		"aws:username":               {"alice"},
+		"aws:sourceip":               {"203.0.113.7"},
+		"aws:multifactorauthpresent": {"true"},
+		"aws:multifactorauthage":     {"90"},
+		"aws:currenttime":            {"2026-10-17T12:00:00Z"},
+		"aws:epochtime":              {"1792238400"},
+		"aws:principaltag/team":      {"finance"},
+		"aws:requesttag/project":     {"q2"},
+		"aws:tagkeys":                {"project"},
+	}
+	if !reflect.DeepEqual(r.Context, expected) {
+		t.Errorf("Expected %v got %v", expected, r.Context)
+	}
+	if values, ok := r.Context.Get(policy.PrincipalTag("Team")); !ok || values[0] != "finance" {
+		t.Errorf("Expected case insensitive key lookup, got %v", values)
+	}
+
+	r = NewRequest("sns.amazonaws.com", "sqs:SendMessage", "arn:aws:sqs:eu-west-1:111122223333:orders")
+	expected = Context{
+		"aws:principalisawsservice": {"true"},
+		"aws:principalservicename":  {"sns.amazonaws.com"},
+	}
+	if !reflect.DeepEqual(r.Context, expected) {
+		t.Errorf("Expected %v got %v", expected, r.Context)
+	}
+}
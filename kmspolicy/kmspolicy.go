@@ -0,0 +1,141 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package kmspolicy builds KMS key policies. A key policy that does not
+// allow the key's account to manage the key makes the key unmanageable, so
+// every KeyPolicy includes a statement enabling IAM policies of the account.
+package kmspolicy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// ErrNoRootStatement is returned when a key policy no longer allows the
+// account root to manage the key
+var ErrNoRootStatement = errors.New("Key policy does not allow the account root all KMS actions")
+
+// Sids of the statements added by KeyPolicy
+const (
+	RootSid  = "EnableIAMUserPermissions"
+	UseSid   = "AllowUseOfTheKey"
+	GrantSid = "AllowAttachmentOfPersistentResources"
+)
+
+var (
+	useActions = []string{
+		actions.KMSEncrypt, actions.KMSDecrypt, "kms:ReEncrypt*", "kms:GenerateDataKey*", actions.KMSDescribeKey,
+	}
+	grantActions = []string{actions.KMSCreateGrant, actions.KMSListGrants, actions.KMSRevokeGrant}
+)
+
+// KeyPolicy is the resource policy of a KMS key
+type KeyPolicy struct {
+	*policy.Policy
+	// The account the key belongs to
+	Account string
+}
+
+// NewKeyPolicy creates a key policy allowing the account root, and through it
+// the IAM policies of the account, to manage and use the key
+func NewKeyPolicy(account string) *KeyPolicy {
+	k := &KeyPolicy{policy.NewPolicy(), account}
+	stmt := k.AddStatement()
+	stmt.SetSid(RootSid)
+	stmt.Effect = policy.Allow
+	stmt.AddPrincipal(k.root())
+	stmt.AddAction(actions.KMSAll)
+	stmt.AddResource("*")
+	return k
+}
+
+func (k *KeyPolicy) root() string {
+	return fmt.Sprintf("arn:aws:iam::%s:root", k.Account)
+}
+
+// allow adds the principal to the statement with the given Sid, creating the
+// statement if the policy does not have it yet
+func (k *KeyPolicy) allow(sid, principal string, granted []string) *policy.Statement {
+	stmt := k.StatementBySid(sid)
+	if stmt == nil {
+		stmt = k.AddStatement()
+		stmt.SetSid(sid)
+		stmt.Effect = policy.Allow
+		stmt.AddActions(granted...)
+		stmt.AddResource("*")
+	}
+	// Statements loaded from a document or appended may have no Principal
+	if stmt.Principal != nil && contains(stmt.Principal.Aws, principal) {
+		return stmt
+	}
+	stmt.AddPrincipal(principal)
+	return stmt
+}
+
+// AllowUse allows the principal to use the key for cryptographic operations,
+// returns the statement granting it
+func (k *KeyPolicy) AllowUse(principal string) *policy.Statement {
+	return k.allow(UseSid, principal, useActions)
+}
+
+// AllowGrant allows the principal to create grants on the key for AWS
+// services integrated with KMS, such as EBS, returns the statement granting it
+func (k *KeyPolicy) AllowGrant(principal string) *policy.Statement {
+	stmt := k.allow(GrantSid, principal, grantActions)
+	if len(stmt.Condition[policy.ConditionBool][conditionkeys.KMSGrantIsForAWSResource]) == 0 {
+		stmt.AddCondition(policy.ConditionBool, conditionkeys.KMSGrantIsForAWSResource, "true")
+	}
+	return stmt
+}
+
+// Validate checks that the account root may still manage the key, that the
+// statements are valid and that the policy does not exceed the key policy
+// size limit
+func (k *KeyPolicy) Validate() error {
+	if !k.hasRootStatement() {
+		return ErrNoRootStatement
+	}
+	if err := k.Policy.Validate(); err != nil {
+		return err
+	}
+	_, err := k.Policy.GetWithLimits(policy.KeyPolicy)
+	return err
+}
+
+func (k *KeyPolicy) hasRootStatement() bool {
+	for _, stmt := range k.Statement {
+		if stmt.Effect != policy.Allow || stmt.Principal == nil || len(stmt.Condition) > 0 {
+			continue
+		}
+		if contains(stmt.Principal.Aws, k.root()) && contains(stmt.Action, actions.KMSAll) && contains(stmt.ResourceList(), "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// Retrieve the policy as a JSON encoded string, returns an error if the
+// policy is not valid
+func (k *KeyPolicy) Get() ([]byte, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+	return k.Policy.Get()
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package kmspolicy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestKeyPolicy(t *testing.T) {
+	k := NewKeyPolicy("111122223333")
+	k.AllowUse("arn:aws:iam::111122223333:role/app")
+	k.AllowUse("arn:aws:iam::111122223333:role/worker")
+	k.AllowUse("arn:aws:iam::111122223333:role/app")
+	k.AllowGrant("arn:aws:iam::111122223333:role/app")
+
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"EnableIAMUserPermissions","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::111122223333:root"]},"Action":["kms:*"],"Resource":["*"]},` +
+		`{"Sid":"AllowUseOfTheKey","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::111122223333:role/app","arn:aws:iam::111122223333:role/worker"]},"Action":["kms:Encrypt","kms:Decrypt","kms:ReEncrypt*","kms:GenerateDataKey*","kms:DescribeKey"],"Resource":["*"]},` +
+		`{"Sid":"AllowAttachmentOfPersistentResources","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::111122223333:role/app"]},"Action":["kms:CreateGrant","kms:ListGrants","kms:RevokeGrant"],"Resource":["*"],"Condition":{"Bool":{"kms:GrantIsForAWSResource":["true"]}}}]}`
+	got, err := k.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling key policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+
+	k.RemoveStatement(RootSid)
+	if _, err := k.Get(); err != ErrNoRootStatement {
+		t.Errorf("Expected ErrNoRootStatement got %v", err)
+	}
+}
+
+func TestKeyPolicyAllowWithoutPrincipal(t *testing.T) {
+	k := NewKeyPolicy("111122223333")
+	k.Append(policy.NewStatement(policy.WithSid(UseSid), policy.WithEffect(policy.Allow), policy.WithActions("kms:Decrypt"), policy.WithResources("*")))
+
+	stmt := k.AllowUse("arn:aws:iam::111122223333:role/app")
+	if stmt.Principal == nil || len(stmt.Principal.Aws) != 1 || stmt.Principal.Aws[0] != "arn:aws:iam::111122223333:role/app" {
+		t.Errorf("Expected the principal to be added got %v", stmt.Principal)
+	}
+}
@@ -0,0 +1,201 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package lambdapolicy models the resource-based policy of a Lambda function.
+// Lambda manages the policy one statement per permission through the
+// AddPermission and RemovePermission APIs and returns it as a JSON string in
+// the GetPolicy output.
+package lambdapolicy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+var (
+	// ErrNoSourceArn is returned for a permission granting a service access
+	// without a source ARN or account, which would let any resource of that
+	// service, in any account, invoke the function
+	ErrNoSourceArn = errors.New("Permission for a service principal has no SourceArn or SourceAccount")
+	// ErrS3NoSourceAccount is returned for an S3 permission without a source
+	// account. Bucket ARNs do not contain the account, so a deleted bucket's
+	// name could be taken by another account.
+	ErrS3NoSourceAccount = errors.New("Permission for S3 has no SourceAccount")
+)
+
+// Error when a statement id is already used or not accepted by Lambda
+type InvalidStatementIdError string
+
+func (e InvalidStatementIdError) Error() string {
+	return fmt.Sprintf("Invalid or duplicate statement id %q", string(e))
+}
+
+// The S3 service principal, which needs a SourceAccount
+const s3ServicePrincipal = "s3.amazonaws.com"
+
+var statementIdPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,100}$`)
+
+// Permission mirrors the input of the Lambda AddPermission API
+type Permission struct {
+	StatementId string
+	// Action to allow, defaults to lambda:InvokeFunction
+	Action string
+	// Service principal such as s3.amazonaws.com, or an account id or ARN
+	Principal string
+	// ARN of the resource invoking the function, required for services
+	SourceArn string
+	// Account owning the source resource, required for S3
+	SourceAccount string
+	// Organization the principal must belong to
+	PrincipalOrgID string
+	// Authentication type of the function URL, for lambda:InvokeFunctionUrl
+	FunctionUrlAuthType string
+}
+
+// FunctionPolicy is the resource-based policy of a Lambda function
+type FunctionPolicy struct {
+	*policy.Policy
+	// Revision of the policy, pass it to AddPermission to detect concurrent
+	// changes
+	RevisionId string
+}
+
+// ParseGetPolicyOutput parses the JSON output of the Lambda GetPolicy API,
+// which holds the policy document as a string
+func ParseGetPolicyOutput(b []byte) (*FunctionPolicy, error) {
+	var output struct {
+		Policy     string
+		RevisionId string
+	}
+	if err := json.Unmarshal(b, &output); err != nil {
+		return nil, err
+	}
+	p, err := policy.LoadPolicy([]byte(output.Policy))
+	if err != nil {
+		return nil, err
+	}
+	return &FunctionPolicy{p, output.RevisionId}, nil
+}
+
+func isServicePrincipal(principal string) bool {
+	_, err := policy.ParseServicePrincipal(principal)
+	return err == nil
+}
+
+// principalArn returns the principal in the form Lambda stores it, account
+// ids are stored as the account root
+func principalArn(principal string) string {
+	if len(principal) == 12 && strings.Trim(principal, "0123456789") == "" {
+		return fmt.Sprintf("arn:aws:iam::%s:root", principal)
+	}
+	return principal
+}
+
+// Statement returns the statement Lambda creates for the permission on the
+// function, after checking the permission follows the source conventions
+func (perm Permission) Statement(functionArn string) (*policy.Statement, error) {
+	if !statementIdPattern.MatchString(perm.StatementId) {
+		return nil, InvalidStatementIdError(perm.StatementId)
+	}
+	service := isServicePrincipal(perm.Principal)
+	if service && perm.SourceArn == "" && perm.SourceAccount == "" {
+		return nil, ErrNoSourceArn
+	}
+	if perm.Principal == s3ServicePrincipal && perm.SourceAccount == "" {
+		return nil, ErrS3NoSourceAccount
+	}
+	action := perm.Action
+	if action == "" {
+		action = actions.LambdaInvokeFunction
+	}
+
+	principal := policy.WithPrincipals(principalArn(perm.Principal))
+	if service {
+		principal = policy.WithServicePrincipals(perm.Principal)
+	}
+	stmt := policy.NewStatement(
+		policy.WithSid(perm.StatementId),
+		policy.WithEffect(policy.Allow),
+		principal,
+		policy.WithActions(action),
+		policy.WithResources(functionArn),
+	)
+	if perm.SourceAccount != "" {
+		stmt.AddCondition(policy.ConditionStringEquals, policy.VarSourceAccount, perm.SourceAccount)
+	}
+	if perm.PrincipalOrgID != "" {
+		stmt.AddCondition(policy.ConditionStringEquals, policy.VarPrincipalOrgID, perm.PrincipalOrgID)
+	}
+	if perm.FunctionUrlAuthType != "" {
+		stmt.AddCondition(policy.ConditionStringEquals, conditionkeys.LambdaFunctionUrlAuthType, perm.FunctionUrlAuthType)
+	}
+	if perm.SourceArn != "" {
+		stmt.AddCondition(policy.ConditionArnLike, policy.VarSourceArn, perm.SourceArn)
+	}
+	return stmt, nil
+}
+
+// AddPermission adds the statement for the permission to the policy, returns
+// the new Statement. Statement ids must be unique within the policy.
+func (f *FunctionPolicy) AddPermission(functionArn string, perm Permission) (*policy.Statement, error) {
+	if f.StatementBySid(perm.StatementId) != nil {
+		return nil, InvalidStatementIdError(perm.StatementId)
+	}
+	stmt, err := perm.Statement(functionArn)
+	if err != nil {
+		return nil, err
+	}
+	f.Append(stmt)
+	return stmt, nil
+}
+
+// Validate checks that every statement granting a service access is
+// restricted by source ARN or account, and by account for S3. The statements
+// are not checked with Policy.Validate, Lambda accepts statement ids that IAM
+// policies do not. Returns nil or policy.ValidationErrors.
+func (f *FunctionPolicy) Validate() error {
+	errs := make(policy.ValidationErrors, 0)
+	for i, stmt := range f.Statement {
+		if stmt.Effect != policy.Allow || stmt.Principal == nil || len(stmt.Principal.Service) == 0 {
+			continue
+		}
+		sourceArn := hasCondition(stmt, policy.VarSourceArn)
+		sourceAccount := hasCondition(stmt, policy.VarSourceAccount)
+		if !sourceArn && !sourceAccount {
+			errs = append(errs, fmt.Errorf("Statement[%d]: %w", i, ErrNoSourceArn))
+		}
+		for _, service := range stmt.Principal.Service {
+			if service == s3ServicePrincipal && !sourceAccount {
+				errs = append(errs, fmt.Errorf("Statement[%d]: %w", i, ErrS3NoSourceAccount))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// hasCondition reports whether the statement has a condition on the key.
+// Lambda writes global keys as AWS:SourceArn, keys are case insensitive.
+func hasCondition(stmt *policy.Statement, key policy.ConditionVariable) bool {
+	for _, keys := range stmt.Condition {
+		for k := range keys {
+			if strings.EqualFold(string(k), string(key)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package lambdapolicy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+const functionArn = "arn:aws:lambda:eu-west-1:111122223333:function:thumbnails"
+
+const getPolicyOutput = `{
+	"Policy": "{\"Version\":\"2012-10-17\",\"Id\":\"default\",\"Statement\":[{\"Sid\":\"s3-invoke\",\"Effect\":\"Allow\",\"Principal\":{\"Service\":\"s3.amazonaws.com\"},\"Action\":\"lambda:InvokeFunction\",\"Resource\":\"arn:aws:lambda:eu-west-1:111122223333:function:thumbnails\",\"Condition\":{\"ArnLike\":{\"AWS:SourceArn\":\"arn:aws:s3:::uploads\"}}}]}",
+	"RevisionId": "4843f2f6-7c59-4fda-b484-afd0bc0e22b8"
+}`
+
+func TestParseGetPolicyOutput(t *testing.T) {
+	f, err := ParseGetPolicyOutput([]byte(getPolicyOutput))
+	if err != nil {
+		t.Fatalf("Failed parsing GetPolicy output: %s", err)
+	}
+	if f.RevisionId != "4843f2f6-7c59-4fda-b484-afd0bc0e22b8" || len(f.Statement) != 1 || *f.Statement[0].Sid != "s3-invoke" {
+		t.Errorf("Unexpected function policy %s", f)
+	}
+
+	var errs policy.ValidationErrors
+	if !errors.As(f.Validate(), &errs) || len(errs) != 1 || !errors.Is(errs[0], ErrS3NoSourceAccount) {
+		t.Errorf("Expected ErrS3NoSourceAccount got %v", errs)
+	}
+}
+
+func TestAddPermission(t *testing.T) {
+	f, err := ParseGetPolicyOutput([]byte(getPolicyOutput))
+	if err != nil {
+		t.Fatalf("Failed parsing GetPolicy output: %s", err)
+	}
+	f.RemoveStatement("s3-invoke")
+
+	permissions := []Permission{
+		{StatementId: "s3-invoke", Principal: "s3.amazonaws.com", SourceArn: "arn:aws:s3:::uploads", SourceAccount: "111122223333"},
+		{StatementId: "partner", Principal: "444455556666"},
+		{StatementId: "url", Action: "lambda:InvokeFunctionUrl", Principal: "*", FunctionUrlAuthType: "AWS_IAM", PrincipalOrgID: "o-a1b2c3d4e5"},
+	}
+	for _, perm := range permissions {
+		if _, err := f.AddPermission(functionArn, perm); err != nil {
+			t.Fatalf("Failed adding permission %s: %s", perm.StatementId, err)
+		}
+	}
+	expected := `{"Version":"2012-10-17","Id":"default","Statement":[` +
+		`{"Sid":"s3-invoke","Effect":"Allow","Principal":{"Service":["s3.amazonaws.com"]},"Action":["lambda:InvokeFunction"],"Resource":["arn:aws:lambda:eu-west-1:111122223333:function:thumbnails"],"Condition":{"ArnLike":{"aws:SourceArn":["arn:aws:s3:::uploads"]},"StringEquals":{"aws:SourceAccount":["111122223333"]}}},` +
+		`{"Sid":"partner","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::444455556666:root"]},"Action":["lambda:InvokeFunction"],"Resource":["arn:aws:lambda:eu-west-1:111122223333:function:thumbnails"]},` +
+		`{"Sid":"url","Effect":"Allow","Principal":{"AWS":["*"]},"Action":["lambda:InvokeFunctionUrl"],"Resource":["arn:aws:lambda:eu-west-1:111122223333:function:thumbnails"],"Condition":{"StringEquals":{"aws:PrincipalOrgID":["o-a1b2c3d4e5"],"lambda:FunctionUrlAuthType":["AWS_IAM"]}}}]}`
+	got, err := f.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+	if err := f.Validate(); err != nil {
+		t.Errorf("Expected no error got %s", err)
+	}
+
+	tests := []struct {
+		perm     Permission
+		expected error
+	}{
+		{Permission{StatementId: "partner", Principal: "444455556666"}, InvalidStatementIdError("partner")},
+		{Permission{StatementId: "bad id", Principal: "444455556666"}, InvalidStatementIdError("bad id")},
+		{Permission{StatementId: "sns", Principal: "sns.amazonaws.com"}, ErrNoSourceArn},
+		{Permission{StatementId: "s3", Principal: "s3.amazonaws.com", SourceArn: "arn:aws:s3:::other"}, ErrS3NoSourceAccount},
+	}
+	for _, test := range tests {
+		if _, err := f.AddPermission(functionArn, test.perm); err != test.expected {
+			t.Errorf("Expected %v got %v", test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Anonymizer replaces account ids, organization ids, canonical users, VPC and
+// VPC endpoint ids and the resource names in ARNs with placeholders, so
+// policies can be shared without revealing details of the environment. The
+// same value is always replaced by the same placeholder, also across
+// policies, so relationships such as two statements referring to the same
+// bucket are preserved.
+//
+// Condition values are anonymized the same way, ARN values such as those of
+// aws:SourceArn have their resource names replaced, and the names in s3:prefix
+// paths are replaced as in S3 object ARNs. Actions, regions, service
+// principals and condition keys are left unchanged, as are IP addresses, user
+// names, tags and other condition values.
+type Anonymizer struct {
+	// Placeholder by original value, and original value by placeholder
+	placeholders map[string]string
+	originals    map[string]string
+	// Number of placeholders assigned per kind of value
+	counts map[string]int
+}
+
+// Placeholder formats by kind of value, placeholders match the format of the
+// value they replace so the policy stays valid
+var placeholderFormats = map[string]string{
+	"account":   "%012d",
+	"org":       "o-%010d",
+	"ou":        "ou-0000-%08d",
+	"canonical": "%064d",
+	"vpc":       "vpc-%017d",
+	"vpce":      "vpce-%017d",
+	"name":      "resource%d",
+}
+
+var (
+	accountPattern = regexp.MustCompile(`\b[0-9]{12}\b`)
+	orgPattern     = regexp.MustCompile(`\bo-[a-z0-9]{10,32}\b`)
+	ouPattern      = regexp.MustCompile(`\bou-[a-z0-9]{4,32}-[a-z0-9]{8,32}\b`)
+	vpcPattern     = regexp.MustCompile(`\bvpc-[0-9a-f]{8,17}\b`)
+	vpcePattern    = regexp.MustCompile(`\bvpce-[0-9a-f]{8,17}\b`)
+	// Wildcards and policy variables, kept as is in resource names
+	resourcePatternPart = regexp.MustCompile(`\$\{[^}]*\}|[*?]`)
+)
+
+// Condition keys whose values are paths of resource names rather than ARNs,
+// by lowercased key, and the service of the resources
+var pathConditionKeys = map[string]string{
+	"s3:prefix": "s3",
+}
+
+// Resource path segments that are part of the ARN format rather than names
+var resourceKeywords = map[string]bool{
+	"index":      true,
+	"stream":     true,
+	"log-stream": true,
+}
+
+// NewAnonymizer creates an Anonymizer without any placeholders assigned
+func NewAnonymizer() *Anonymizer {
+	return &Anonymizer{
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+		counts:       make(map[string]int),
+	}
+}
+
+// Anonymize returns a copy of the policy with environment details replaced by
+// placeholders
+func (a *Anonymizer) Anonymize(p *Policy) *Policy {
+	return rewritePolicy(p, a.placeholder)
+}
+
+// Restore returns a copy of a policy anonymized by this Anonymizer with the
+// placeholders replaced by the original values
+func (a *Anonymizer) Restore(p *Policy) *Policy {
+	return rewritePolicy(p, func(_, s string) string {
+		if original, ok := a.originals[s]; ok {
+			return original
+		}
+		return s
+	})
+}
+
+// Mapping returns the original value of each placeholder assigned so far.
+// The mapping reveals the anonymized details and is meant for internal use
+// only.
+func (a *Anonymizer) Mapping() map[string]string {
+	result := make(map[string]string, len(a.originals))
+	for placeholder, original := range a.originals {
+		result[placeholder] = original
+	}
+	return result
+}
+
+func (a *Anonymizer) placeholder(kind, s string) string {
+	if placeholder, ok := a.placeholders[s]; ok {
+		return placeholder
+	}
+	a.counts[kind]++
+	placeholder := fmt.Sprintf(placeholderFormats[kind], a.counts[kind])
+	a.placeholders[s] = placeholder
+	a.originals[placeholder] = s
+	return placeholder
+}
+
+// rewritePolicy returns a copy of the policy with replace applied to the
+// values that may reveal details of the environment
+func rewritePolicy(p *Policy, replace func(kind, s string) string) *Policy {
+	c := p.Clone()
+	rewriteAll := func(list []string) {
+		for i, s := range list {
+			list[i] = rewriteValue(s, replace)
+		}
+	}
+	for _, stmt := range c.Statement {
+		for _, principal := range []*Principal{stmt.Principal, stmt.NotPrincipal} {
+			if principal == nil {
+				continue
+			}
+			rewriteAll(principal.Aws)
+			rewriteAll(principal.Federated)
+			for i, user := range principal.CanonicalUser {
+				principal.CanonicalUser[i] = replace("canonical", user)
+			}
+		}
+		if stmt.Resource != "" {
+			stmt.Resource = rewriteValue(stmt.Resource, replace)
+		}
+		rewriteAll(stmt.Resources)
+		rewriteAll(stmt.NotResource)
+		// In sorted order, so placeholders are numbered the same every time
+		types := make([]string, 0, len(stmt.Condition))
+		for t := range stmt.Condition {
+			types = append(types, string(t))
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			keys := stmt.Condition[ConditionType(t)]
+			names := make([]string, 0, len(keys))
+			for key := range keys {
+				names = append(names, string(key))
+			}
+			sort.Strings(names)
+			for _, key := range names {
+				values := keys[ConditionVariable(key)]
+				service, ok := pathConditionKeys[strings.ToLower(key)]
+				if !ok {
+					rewriteAll(values)
+					continue
+				}
+				for i, value := range values {
+					values[i] = rewriteResource(service, value, replace)
+				}
+			}
+		}
+	}
+	return c
+}
+
+// rewriteValue applies replace to the account id and resource names of an
+// ARN, or to the account, organization, VPC and VPC endpoint ids in any other
+// value
+func rewriteValue(s string, replace func(kind, s string) string) string {
+	parts := splitArn(s)
+	if len(parts) < 6 || parts[0] != "arn" {
+		s = vpcPattern.ReplaceAllStringFunc(s, func(id string) string { return replace("vpc", id) })
+		s = vpcePattern.ReplaceAllStringFunc(s, func(id string) string { return replace("vpce", id) })
+		s = ouPattern.ReplaceAllStringFunc(s, func(id string) string { return replace("ou", id) })
+		s = orgPattern.ReplaceAllStringFunc(s, func(id string) string { return replace("org", id) })
+		return accountPattern.ReplaceAllStringFunc(s, func(id string) string { return replace("account", id) })
+	}
+	// AWS managed resources such as arn:aws:iam::aws:policy/ReadOnlyAccess
+	// are public
+	if parts[4] == "aws" {
+		return s
+	}
+	if accountPattern.MatchString(parts[4]) {
+		parts[4] = replace("account", parts[4])
+	}
+	parts[5] = rewriteResource(parts[2], parts[5], replace)
+	return strings.Join(parts, ":")
+}
+
+// rewriteResource applies replace to the names in the resource part of an
+// ARN, keeping the resource type, separators, wildcards and policy variables
+func rewriteResource(service, resource string, replace func(kind, s string) string) string {
+	var b strings.Builder
+	segment := 0
+	for len(resource) > 0 {
+		end := indexSeparator(resource, "/:")
+		if end < 0 {
+			end = len(resource)
+		}
+		name := resource[:end]
+		// Resources other than S3 objects start with their type, e.g. role/
+		isType := segment == 0 && end < len(resource) && service != "s3"
+		if isType || resourceKeywords[name] {
+			b.WriteString(name)
+		} else {
+			b.WriteString(rewriteName(name, replace))
+		}
+		if end < len(resource) {
+			b.WriteByte(resource[end])
+			end++
+		}
+		resource = resource[end:]
+		segment++
+	}
+	return b.String()
+}
+
+// rewriteName applies replace to the literal parts of a name, which may
+// contain wildcards and policy variables
+func rewriteName(name string, replace func(kind, s string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range resourcePatternPart.FindAllStringIndex(name, -1) {
+		if loc[0] > last {
+			b.WriteString(replace("name", name[last:loc[0]]))
+		}
+		b.WriteString(name[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	if last < len(name) {
+		b.WriteString(replace("name", name[last:]))
+	}
+	return b.String()
+}
+
+// indexSeparator returns the index of the first of the separator characters
+// in s outside of policy variables, or -1 if there is none
+func indexSeparator(s, separators string) int {
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], "${") {
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return -1
+			}
+			i += end
+			continue
+		}
+		if strings.IndexByte(separators, s[i]) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitArn splits an ARN in at most six fields, ignoring colons in policy
+// variables
+func splitArn(s string) []string {
+	parts := make([]string, 0, 6)
+	for len(parts) < 5 {
+		i := indexSeparator(s, ":")
+		if i < 0 {
+			break
+		}
+		parts = append(parts, s[:i])
+		s = s[i+1:]
+	}
+	return append(parts, s)
+}
@@ -0,0 +1,78 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestAnonymize(t *testing.T) {
+	p := NewPolicy()
+	p.Append(NewStatement(
+		WithEffect(Allow),
+		WithPrincipals("arn:aws:iam::111122223333:role/deploy", "444455556666"),
+		WithActions("s3:GetObject"),
+		WithResources("arn:aws:s3:::acme-reports/home/${aws:username}/*", "arn:aws:iam::aws:policy/ReadOnlyAccess"),
+		WithCondition(ConditionStringEquals, VarPrincipalOrgID, "o-a1b2c3d4e5"),
+		WithCondition(ConditionArnLike, VarSourceArn, "arn:aws:sns:eu-west-1:111122223333:acme-*"),
+	))
+	p.Append(NewStatement(
+		WithEffect(Allow),
+		WithActions("dynamodb:Query"),
+		WithResources("arn:aws:dynamodb:eu-west-1:111122223333:table/acme-reports/index/*"),
+		WithCondition(ConditionStringLike, VarPrincipalOrgPaths, "o-a1b2c3d4e5/r-ab12/ou-ab12-11111111/*"),
+	))
+
+	a := NewAnonymizer()
+	anonymized := a.Anonymize(p)
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::000000000001:role/resource1","000000000002"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::resource2/resource3/${aws:username}/*","arn:aws:iam::aws:policy/ReadOnlyAccess"],"Condition":{"ArnLike":{"aws:SourceArn":["arn:aws:sns:eu-west-1:000000000001:resource4*"]},"StringEquals":{"aws:PrincipalOrgID":["o-0000000001"]}}},` +
+		`{"Effect":"Allow","Action":["dynamodb:Query"],"Resource":["arn:aws:dynamodb:eu-west-1:000000000001:table/resource2/index/*"],"Condition":{"StringLike":{"aws:PrincipalOrgPaths":["o-0000000001/r-ab12/ou-0000-00000001/*"]}}}]}`
+	got, err := anonymized.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+
+	if mapping := a.Mapping(); mapping["000000000001"] != "111122223333" || mapping["resource2"] != "acme-reports" {
+		t.Errorf("Unexpected mapping %v", mapping)
+	}
+	if restored := a.Restore(anonymized); !restored.Equal(p) {
+		t.Errorf("Expected the restored policy to equal the original got \n%s", restored)
+	}
+}
+
+func TestAnonymizeConditionValues(t *testing.T) {
+	p := NewPolicy()
+	p.Append(NewStatement(
+		WithEffect(Allow),
+		WithActions("s3:ListBucket"),
+		WithResources("arn:aws:s3:::acme-reports"),
+		WithCondition(ConditionStringLike, "s3:prefix", "home/${aws:username}/*", "acme-reports/"),
+		WithCondition(ConditionStringEquals, VarSourceVpce, "vpce-1a2b3c4d"),
+		WithCondition(ConditionStringNotEquals, VarSourceVpc, "vpc-0123456789abcdef0", "vpc-*"),
+		WithCondition(ConditionIpAddress, VarSourceIp, "203.0.113.0/24"),
+	))
+
+	a := NewAnonymizer()
+	anonymized := a.Anonymize(p)
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Allow","Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::resource1"],"Condition":{"IpAddress":{"aws:SourceIp":["203.0.113.0/24"]},"StringEquals":{"aws:SourceVpce":["vpce-00000000000000001"]},"StringLike":{"s3:prefix":["resource2/${aws:username}/*","resource1/"]},"StringNotEquals":{"aws:SourceVpc":["vpc-00000000000000001","vpc-*"]}}}]}`
+	got, err := anonymized.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+	if restored := a.Restore(anonymized); !restored.Equal(p) {
+		t.Errorf("Expected the restored policy to equal the original got \n%s", restored)
+	}
+}
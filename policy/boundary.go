@@ -0,0 +1,353 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// PermissionsBoundary is a managed policy set as the permissions boundary of
+// a user or role. Boundaries use the identity-based policy grammar, they do
+// not grant anything themselves but limit the permissions the identity-based
+// policies of the user or role can grant.
+type PermissionsBoundary struct {
+	*Policy
+}
+
+// Create a new empty permissions boundary
+func NewPermissionsBoundary() *PermissionsBoundary {
+	return &PermissionsBoundary{NewPolicy()}
+}
+
+// Create a permissions boundary from JSON, the policy is validated
+func LoadPermissionsBoundary(b []byte) (*PermissionsBoundary, error) {
+	p, err := LoadPolicy(b)
+	if err != nil {
+		return nil, err
+	}
+	boundary := &PermissionsBoundary{p}
+	if err := boundary.Validate(); err != nil {
+		return nil, err
+	}
+	return boundary, nil
+}
+
+// Add a new (empty) Statement to the policy, returns the new Statement
+func (p *PermissionsBoundary) AddStatement() *Statement {
+	return p.Policy.AddIdentityStatement()
+}
+
+// Validate checks that every statement is an identity statement, so contains
+// no Principal or NotPrincipal, and that the policy does not exceed the
+// managed policy size limit
+func (p *PermissionsBoundary) Validate() error {
+	for _, stmt := range p.Statement {
+		if stmt.Kind != IdentityStatement {
+			return InvalidStatementError("statement is not a permissions boundary statement")
+		}
+	}
+	if err := p.Policy.Validate(); err != nil {
+		return err
+	}
+	_, err := p.Policy.GetWithLimits(BoundaryPolicy)
+	return err
+}
+
+// Retrieve the policy as a JSON encoded string, returns an error if the
+// policy is not valid
+func (p *PermissionsBoundary) Get() ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p.Policy.Get()
+}
+
+// EffectivePermissions returns an identity-based policy granting what both
+// the identity-based policy and the permissions boundary allow, see Intersect
+func EffectivePermissions(identity *Policy, boundary *PermissionsBoundary) *Policy {
+	return Intersect(identity, boundary.Policy)
+}
+
+// Intersect returns an identity-based policy granting what both policies
+// allow. Every Allow statement of the first policy is intersected with every
+// Allow statement of the second, the Deny statements of both are kept as they
+// are.
+//
+// The intersection is computed on the patterns, not on the actions and
+// resources they match:
+//   - of two patterns the more specific one is kept when it is matched by the
+//     other, patterns that only partially overlap such as s3:Get* and
+//     s3:*Object are left out
+//   - patterns are removed when a NotAction or NotResource pattern of the
+//     other statement matches them completely, partially excluded patterns
+//     are kept unless the pattern is *, which becomes the NotAction or
+//     NotResource of the other statement
+//   - conditions of both statements are all kept, for a key compared by the
+//     same operator in both the values are combined so the condition holds
+//     only when both did, see conjoinConditionValues
+func Intersect(p, other *Policy) *Policy {
+	result := NewPolicy()
+	for _, stmt := range p.Statement {
+		if stmt.Effect != Allow {
+			continue
+		}
+		for _, limit := range other.Statement {
+			if limit.Effect != Allow {
+				continue
+			}
+			if intersection := intersectStatements(stmt, limit); intersection != nil {
+				result.Statement = append(result.Statement, intersection)
+			}
+		}
+	}
+	for _, q := range []*Policy{p, other} {
+		for _, stmt := range q.Statement {
+			if stmt.Effect == Deny {
+				result.Statement = append(result.Statement, stmt.Clone())
+			}
+		}
+	}
+	return result
+}
+
+// intersectStatements returns an Allow statement granting what both
+// statements allow, or nil if they have nothing in common
+func intersectStatements(a, b *Statement) *Statement {
+	action, notAction := intersectPatterns(a.Action, a.NotAction, b.Action, b.NotAction, matchAction)
+	if len(action) == 0 && len(notAction) == 0 {
+		return nil
+	}
+	resource, notResource := intersectPatterns(a.ResourceList(), a.NotResource, b.ResourceList(), b.NotResource, WildcardMatch)
+	if len(resource) == 0 && len(notResource) == 0 {
+		return nil
+	}
+	condition, ok := intersectConditions(a.Condition, b.Condition)
+	if !ok {
+		return nil
+	}
+	return &Statement{
+		Kind:        IdentityStatement,
+		Effect:      Allow,
+		Action:      action,
+		NotAction:   notAction,
+		Resources:   resource,
+		NotResource: notResource,
+		Condition:   condition,
+	}
+}
+
+// intersectPatterns intersects two pattern lists, each given as either a list
+// of patterns or a list of excluded patterns. Returns the patterns or the
+// excluded patterns of the intersection, both are empty if there is none.
+func intersectPatterns(list, notList, other, otherNot []string, match func(pattern, value string) bool) ([]string, []string) {
+	switch {
+	case len(notList) > 0 && len(otherNot) > 0:
+		return nil, appendUnique(cloneList(notList), otherNot...)
+	case len(otherNot) > 0 && contains(list, "*"):
+		return nil, cloneList(otherNot)
+	case len(notList) > 0 && contains(other, "*"):
+		return nil, cloneList(notList)
+	case len(otherNot) > 0:
+		return excludePatterns(list, otherNot, match), nil
+	case len(notList) > 0:
+		return excludePatterns(other, notList, match), nil
+	}
+	result := make([]string, 0)
+	for _, a := range list {
+		for _, b := range other {
+			// A pattern matching the other pattern as a value matches
+			// everything the other pattern matches
+			switch {
+			case match(a, b):
+				result = appendUnique(result, b)
+			case match(b, a):
+				result = appendUnique(result, a)
+			}
+		}
+	}
+	return result, nil
+}
+
+// excludePatterns returns the patterns not completely matched by any of the
+// excluded patterns
+func excludePatterns(list, excluded []string, match func(pattern, value string) bool) []string {
+	result := make([]string, 0, len(list))
+	for _, pattern := range list {
+		if !matchesList(excluded, nil, pattern, match) {
+			result = appendUnique(result, pattern)
+		}
+	}
+	return result
+}
+
+// intersectConditions combines the conditions of two statements, returns
+// false if they can not both hold
+func intersectConditions(a, b map[ConditionType]map[ConditionVariable][]string) (map[ConditionType]map[ConditionVariable][]string, bool) {
+	result := (&Statement{Condition: a}).Clone().Condition
+	if result == nil {
+		result = make(map[ConditionType]map[ConditionVariable][]string, len(b))
+	}
+	for t, vars := range b {
+		if result[t] == nil {
+			result[t] = make(map[ConditionVariable][]string, len(vars))
+		}
+		for key, values := range vars {
+			existing, ok := result[t][key]
+			if !ok {
+				result[t][key] = cloneList(values)
+				continue
+			}
+			combined := conjoinConditionValues(t, existing, values)
+			if len(combined) == 0 {
+				return nil, false
+			}
+			result[t][key] = combined
+		}
+	}
+	return result, true
+}
+
+// conjoinConditionValues returns the values for a condition comparing a key
+// with the operator that holds when the conditions with both lists of values
+// hold. A condition matches any of its values, or with a negated operator
+// none of them, so:
+//   - for equality only the values in common are kept
+//   - for negated operators the values of both are joined
+//   - for less than and greater than only the tighter of the two bounds is
+//     kept
+//   - of patterns and IP ranges the more specific one is kept when it is
+//     matched by the other, partially overlapping ones are left out
+//
+// With the ForAnyValue set operator the result may match less than both
+// conditions, never more.
+func conjoinConditionValues(t ConditionType, a, b []string) []string {
+	op := t.Operator()
+	switch op {
+	case ConditionStringNotEquals, ConditionStringNotEqualsIgnoreCase, ConditionStringNotLike,
+		ConditionNumericNotEquals, ConditionDateNotEquals, ConditionArnNotEquals, ConditionArnNotLike,
+		ConditionNotIpAddress:
+		return appendUnique(cloneList(a), b...)
+	case ConditionNumericLessThan, ConditionNumericLessThanEquals, ConditionDateLessThan, ConditionDateLessThanEquals:
+		if value, ok := tighterBound(op, a, b, true); ok {
+			return []string{value}
+		}
+	case ConditionNumericGreaterThan, ConditionNumericGreaterThanEquals, ConditionDateGreaterThan, ConditionDateGreaterThanEquals:
+		if value, ok := tighterBound(op, a, b, false); ok {
+			return []string{value}
+		}
+	case ConditionStringLike, ConditionArnLike, ConditionArnEquals:
+		// ArnEquals accepts wildcards too
+		result, _ := intersectPatterns(a, nil, b, nil, WildcardMatch)
+		return result
+	case ConditionIpAddress:
+		if result, ok := intersectRanges(a, b); ok {
+			return result
+		}
+	case ConditionStringEqualsIgnoreCase:
+		result := make([]string, 0)
+		for _, value := range b {
+			for _, other := range a {
+				if strings.EqualFold(value, other) {
+					result = appendUnique(result, value)
+				}
+			}
+		}
+		return result
+	}
+	// Equality, and the values that could not be parsed: a value in common
+	// matches both conditions
+	result := make([]string, 0)
+	for _, value := range b {
+		if contains(a, value) {
+			result = appendUnique(result, value)
+		}
+	}
+	return result
+}
+
+// tighterBound returns the value of the less than (upper) or greater than
+// condition that bounds the tightest. A condition with multiple values
+// matches any of them, so its widest value is its bound.
+func tighterBound(op ConditionType, a, b []string, upper bool) (string, bool) {
+	var result string
+	var tightest float64
+	for i, values := range [][]string{a, b} {
+		var widest string
+		var bound float64
+		for j, value := range values {
+			v, err := parseBound(op, value)
+			if err != nil {
+				return "", false
+			}
+			if j == 0 || (upper && v > bound) || (!upper && v < bound) {
+				widest, bound = value, v
+			}
+		}
+		if widest == "" {
+			return "", false
+		}
+		if i == 0 || (upper && bound < tightest) || (!upper && bound > tightest) {
+			result, tightest = widest, bound
+		}
+	}
+	return result, true
+}
+
+// intersectRanges returns the IP ranges of both lists contained in a range of
+// the other, returns false if a value is not an IP address or range
+func intersectRanges(a, b []string) ([]string, bool) {
+	parse := func(values []string) ([]netip.Prefix, bool) {
+		prefixes := make([]netip.Prefix, 0, len(values))
+		for _, value := range values {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				addr, err := netip.ParseAddr(value)
+				if err != nil {
+					return nil, false
+				}
+				prefix = netip.PrefixFrom(addr, addr.BitLen())
+			}
+			prefixes = append(prefixes, prefix.Masked())
+		}
+		return prefixes, true
+	}
+	pa, ok := parse(a)
+	if !ok {
+		return nil, false
+	}
+	pb, ok := parse(b)
+	if !ok {
+		return nil, false
+	}
+	result := make([]string, 0)
+	for i, x := range pa {
+		for j, y := range pb {
+			// Two ranges that overlap are either equal or one contains
+			// the other
+			if !x.Overlaps(y) {
+				continue
+			}
+			if x.Bits() >= y.Bits() {
+				result = appendUnique(result, a[i])
+			} else {
+				result = appendUnique(result, b[j])
+			}
+		}
+	}
+	return result, true
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		if !contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
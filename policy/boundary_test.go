@@ -0,0 +1,153 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPermissionsBoundary(t *testing.T) {
+	p := NewPermissionsBoundary()
+	stmt := p.AddStatement()
+	stmt.Effect = Allow
+	stmt.AddAction("s3:*")
+	stmt.AddResource("*")
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:*"],"Resource":["*"]}]}`
+
+	got, err := p.Get()
+	if err != nil {
+		t.Fatalf("Failed getting policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+
+	stmt.AddPrincipal("*")
+	if _, err := p.Get(); err == nil {
+		t.Error("Expected error for statement with a Principal")
+	}
+
+	_, err = LoadPermissionsBoundary([]byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:*","Resource":"*"}]}`))
+	var stmtErr InvalidStatementError
+	if !errors.As(err, &stmtErr) {
+		t.Errorf("Expected InvalidStatementError got %v", err)
+	}
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	identity, err := LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[
+		{"Effect":"Allow","Action":["s3:*","ec2:DescribeInstances"],"Resource":"arn:aws:s3:::reports/*"},
+		{"Effect":"Allow","NotAction":"iam:*","Resource":"*","Condition":{"StringEquals":{"aws:RequestedRegion":["eu-west-1","eu-central-1"]}}},
+		{"Effect":"Deny","Action":"s3:DeleteObject","Resource":"*"}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	boundary, err := LoadPermissionsBoundary([]byte(`{"Version":"2012-10-17","Statement":[
+		{"Effect":"Allow","Action":["s3:Get*","sqs:*"],"Resource":"*"},
+		{"Effect":"Allow","Action":["lambda:*"],"NotResource":"arn:aws:lambda:*:*:function:admin-*","Condition":{"StringEquals":{"aws:RequestedRegion":"eu-west-1"}}},
+		{"Effect":"Allow","Action":"iam:PassRole","Resource":"*","Condition":{"StringEquals":{"aws:RequestedRegion":"us-east-1"}}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed loading boundary: %s", err)
+	}
+
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Allow","Action":["s3:Get*"],"Resource":["arn:aws:s3:::reports/*"]},` +
+		`{"Effect":"Allow","Action":["s3:Get*","sqs:*"],"Resource":["*"],"Condition":{"StringEquals":{"aws:RequestedRegion":["eu-central-1","eu-west-1"]}}},` +
+		`{"Effect":"Allow","Action":["lambda:*"],"NotResource":["arn:aws:lambda:*:*:function:admin-*"],"Condition":{"StringEquals":{"aws:RequestedRegion":["eu-west-1"]}}},` +
+		`{"Effect":"Deny","Action":["s3:DeleteObject"],"Resource":["*"]}]}`
+	got, err := EffectivePermissions(identity, boundary).GetCanonical()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+}
+
+func TestIntersectConditions(t *testing.T) {
+	tests := []struct {
+		identity, boundary string
+		expected           string
+	}{
+		{
+			`{"StringNotEquals":{"aws:RequestedRegion":["us-east-1","eu-west-1"]}}`,
+			`{"StringNotEquals":{"aws:RequestedRegion":"eu-west-1"}}`,
+			`{"StringNotEquals":{"aws:RequestedRegion":["eu-west-1","us-east-1"]}}`,
+		},
+		{
+			`{"StringNotEquals":{"aws:RequestedRegion":"us-east-1"}}`,
+			`{"StringNotLike":{"aws:RequestedRegion":"eu-*"}}`,
+			`{"StringNotEquals":{"aws:RequestedRegion":["us-east-1"]},"StringNotLike":{"aws:RequestedRegion":["eu-*"]}}`,
+		},
+		{
+			`{"NumericLessThan":{"sts:DurationSeconds":"3600"}}`,
+			`{"NumericLessThan":{"sts:DurationSeconds":"7200"}}`,
+			`{"NumericLessThan":{"sts:DurationSeconds":["3600"]}}`,
+		},
+		{
+			`{"NumericGreaterThanEquals":{"s3:max-keys":["10","100"]}}`,
+			`{"NumericGreaterThanEquals":{"s3:max-keys":"50"}}`,
+			`{"NumericGreaterThanEquals":{"s3:max-keys":["50"]}}`,
+		},
+		{
+			`{"DateLessThan":{"aws:CurrentTime":"2030-01-01T00:00:00Z"}}`,
+			`{"DateLessThan":{"aws:CurrentTime":"2027-06-30T00:00:00Z"}}`,
+			`{"DateLessThan":{"aws:CurrentTime":["2027-06-30T00:00:00Z"]}}`,
+		},
+		{
+			`{"StringLike":{"s3:prefix":["home/*","shared/*"]}}`,
+			`{"StringLike":{"s3:prefix":["home/alice/*","tmp/*"]}}`,
+			`{"StringLike":{"s3:prefix":["home/alice/*"]}}`,
+		},
+		{
+			`{"IpAddress":{"aws:SourceIp":["10.0.0.0/8","192.0.2.0/24"]}}`,
+			`{"IpAddress":{"aws:SourceIp":["10.1.0.0/16","2001:db8::/32"]}}`,
+			`{"IpAddress":{"aws:SourceIp":["10.1.0.0/16"]}}`,
+		},
+		{
+			`{"NotIpAddress":{"aws:SourceIp":"10.0.0.0/8"}}`,
+			`{"NotIpAddress":{"aws:SourceIp":"192.0.2.0/24"}}`,
+			`{"NotIpAddress":{"aws:SourceIp":["10.0.0.0/8","192.0.2.0/24"]}}`,
+		},
+		{
+			`{"StringEquals":{"aws:RequestedRegion":["eu-west-1","eu-central-1"]}}`,
+			`{"StringEquals":{"aws:RequestedRegion":"eu-west-1"}}`,
+			`{"StringEquals":{"aws:RequestedRegion":["eu-west-1"]}}`,
+		},
+		{
+			`{"StringEquals":{"aws:RequestedRegion":"eu-west-1"}}`,
+			`{"StringEquals":{"aws:RequestedRegion":"us-east-1"}}`,
+			``,
+		},
+	}
+
+	for i, test := range tests {
+		identity, err := LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":{"Effect":"Allow","Action":"s3:*","Resource":"*","Condition":` + test.identity + `}}`))
+		if err != nil {
+			t.Fatalf("Test %d: failed loading policy: %s", i, err)
+		}
+		boundary, err := LoadPermissionsBoundary([]byte(`{"Version":"2012-10-17","Statement":{"Effect":"Allow","Action":"s3:*","Resource":"*","Condition":` + test.boundary + `}}`))
+		if err != nil {
+			t.Fatalf("Test %d: failed loading boundary: %s", i, err)
+		}
+		expected := `{"Version":"2012-10-17","Statement":[]}`
+		if test.expected != "" {
+			expected = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:*"],"Resource":["*"],"Condition":` + test.expected + `}]}`
+		}
+		got, err := EffectivePermissions(identity, boundary).GetCanonical()
+		if err != nil {
+			t.Fatalf("Test %d: failed marshaling policy: %s", i, err)
+		}
+		if string(got) != expected {
+			t.Errorf("Test %d: expected \n%s got \n%s", i, expected, got)
+		}
+	}
+}
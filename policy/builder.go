@@ -0,0 +1,127 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+// Builder creates a policy using chained calls, e.g.
+//
+//	p := policy.Build().
+//		Allow().Actions("s3:GetObject").Resources("arn:aws:s3:::bucket/*").
+//		Deny().Actions("s3:*").Resources("*").Condition(policy.ConditionBool, policy.VarSecureTransport, "false").
+//		Done()
+//
+// Allow and Deny start a new statement, the other statement methods apply to
+// the last statement started. Statements are identity statements unless a
+// Principal or NotPrincipal is added.
+type Builder struct {
+	policy *Policy
+	stmt   *Statement
+}
+
+// Start building a new policy
+func Build() *Builder {
+	return &Builder{policy: NewPolicy()}
+}
+
+// Set the Id of the policy
+func (b *Builder) Id(id string) *Builder {
+	b.policy.SetId(id)
+	return b
+}
+
+// Start a new Allow statement
+func (b *Builder) Allow() *Builder {
+	b.stmt = b.policy.AddIdentityStatement()
+	b.stmt.Effect = Allow
+	return b
+}
+
+// Start a new Deny statement
+func (b *Builder) Deny() *Builder {
+	b.stmt = b.policy.AddIdentityStatement()
+	b.stmt.Effect = Deny
+	return b
+}
+
+// statement returns the current statement, starting a Deny statement if none
+// has been started yet
+func (b *Builder) statement() *Statement {
+	if b.stmt == nil {
+		b.Deny()
+	}
+	return b.stmt
+}
+
+// Set the Sid of the current statement
+func (b *Builder) Sid(sid string) *Builder {
+	b.statement().SetSid(sid)
+	return b
+}
+
+// Add AWS principals to the current statement
+func (b *Builder) Principal(principals ...string) *Builder {
+	stmt := b.statement()
+	stmt.Kind = ResourceStatement
+	stmt.AddPrincipals(principals...)
+	return b
+}
+
+// Add service principals to the current statement
+func (b *Builder) ServicePrincipal(principals ...string) *Builder {
+	stmt := b.statement()
+	stmt.Kind = ResourceStatement
+	stmt.AddServicePrincipals(principals...)
+	return b
+}
+
+// Add AWS principals to the NotPrincipal list of the current statement
+func (b *Builder) NotPrincipal(principals ...string) *Builder {
+	stmt := b.statement()
+	stmt.Kind = ResourceStatement
+	stmt.AddNotPrincipals(principals...)
+	return b
+}
+
+// Add actions to the current statement
+func (b *Builder) Actions(actions ...string) *Builder {
+	stmt := b.statement()
+	stmt.AddActions(actions...)
+	return b
+}
+
+// Add actions to the NotAction list of the current statement
+func (b *Builder) NotActions(actions ...string) *Builder {
+	stmt := b.statement()
+	stmt.AddNotActions(actions...)
+	return b
+}
+
+// Add resources to the current statement
+func (b *Builder) Resources(resources ...string) *Builder {
+	stmt := b.statement()
+	stmt.AddResources(resources...)
+	return b
+}
+
+// Add resources to the NotResource list of the current statement
+func (b *Builder) NotResources(resources ...string) *Builder {
+	stmt := b.statement()
+	stmt.AddNotResources(resources...)
+	return b
+}
+
+// Add a condition with one or more values to the current statement
+func (b *Builder) Condition(t ConditionType, key ConditionVariable, values ...string) *Builder {
+	stmt := b.statement()
+	stmt.AddConditionValues(t, key, values...)
+	return b
+}
+
+// Finish building, returns the policy
+func (b *Builder) Done() *Policy {
+	return b.policy
+}
@@ -0,0 +1,25 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	p := Build().
+		Allow().Sid("Read").Principal("arn:aws:iam::123456789012:root").Actions("s3:GetObject", "s3:ListBucket").Resources("arn:aws:s3:::bucket", "arn:aws:s3:::bucket/*").
+		Deny().Actions("s3:*").Resources("*").Condition(ConditionBool, VarSecureTransport, "false").
+		Done()
+	expected := `{"Version":"2012-10-17","Statement":[{"Sid":"Read","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::123456789012:root"]},"Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::bucket","arn:aws:s3:::bucket/*"]},{"Effect":"Deny","Action":["s3:*"],"Resource":["*"],"Condition":{"Bool":{"aws:SecureTransport":["false"]}}}]}`
+
+	assertPolicy(t, p, expected)
+	if p.Statement[0].Kind != ResourceStatement || p.Statement[1].Kind != IdentityStatement {
+		t.Errorf("Expected a resource and an identity statement got %v and %v", p.Statement[0].Kind, p.Statement[1].Kind)
+	}
+}
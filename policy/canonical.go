@@ -0,0 +1,319 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Retrieve the policy as a canonical JSON encoded string. Actions, resources,
+// principals, condition operators, keys and values are emitted in sorted
+// order, so policies that only differ in the order of these produce identical
+// output, suitable for diffing and hashing.
+func (p *Policy) GetCanonical() ([]byte, error) {
+	return p.getCodec().Marshal(p.canonical())
+}
+
+// canonical returns a copy of the policy with all lists sorted. The order of
+// the statements is kept.
+func (p *Policy) canonical() *Policy {
+	c := *p
+	c.Statement = make([]*Statement, len(p.Statement))
+	for i, stmt := range p.Statement {
+		c.Statement[i] = stmt.canonical()
+	}
+	return &c
+}
+
+func (s *Statement) canonical() *Statement {
+	c := *s
+	c.Principal = s.Principal.canonical()
+	c.NotPrincipal = s.NotPrincipal.canonical()
+	c.Action = sortedCopy(s.Action)
+	c.NotAction = sortedCopy(s.NotAction)
+	c.Resource = ""
+	c.Resources = sortedCopy(s.ResourceList())
+	c.NotResource = sortedCopy(s.NotResource)
+	if s.Condition != nil {
+		c.Condition = make(map[ConditionType]map[ConditionVariable][]string, len(s.Condition))
+		for t, vars := range s.Condition {
+			c.Condition[t] = make(map[ConditionVariable][]string, len(vars))
+			for key, values := range vars {
+				c.Condition[t][key] = sortedCopy(values)
+			}
+		}
+	}
+	return &c
+}
+
+func (p *Principal) canonical() *Principal {
+	if p == nil {
+		return nil
+	}
+	return &Principal{
+		Aws:           sortedCopy(p.Aws),
+		Service:       sortedCopy(p.Service),
+		Federated:     sortedCopy(p.Federated),
+		CanonicalUser: sortedCopy(p.CanonicalUser),
+	}
+}
+
+func sortedCopy(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	c := make([]string, len(list))
+	copy(c, list)
+	sort.Strings(c)
+	return c
+}
+
+// Fingerprint returns a hash over the semantic content of the statement. It
+// does not depend on the Sid, the order of values, duplicate values, the case
+// of actions or the JSON formatting of the document the statement was loaded
+// from, so it can be used to track a statement across policy revisions.
+func (s *Statement) Fingerprint() string {
+	c := s.canonical()
+	c.Sid = nil
+	c.Principal = c.Principal.fingerprint()
+	c.NotPrincipal = c.NotPrincipal.fingerprint()
+	c.Action = fingerprintList(lowerList(c.Action))
+	c.NotAction = fingerprintList(lowerList(c.NotAction))
+	c.Resources = fingerprintList(c.Resources)
+	c.NotResource = fingerprintList(c.NotResource)
+	for _, vars := range c.Condition {
+		for key, values := range vars {
+			vars[key] = fingerprintList(values)
+		}
+	}
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (p *Principal) fingerprint() *Principal {
+	if p == nil {
+		return nil
+	}
+	c := &Principal{
+		Aws:           fingerprintList(p.Aws),
+		Service:       fingerprintList(p.Service),
+		Federated:     fingerprintList(p.Federated),
+		CanonicalUser: fingerprintList(p.CanonicalUser),
+	}
+	if c.Aws == nil && c.Service == nil && c.Federated == nil && c.CanonicalUser == nil {
+		return nil
+	}
+	return c
+}
+
+// fingerprintList removes duplicates from a sorted list, and returns nil for
+// empty lists
+func fingerprintList(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	result := list[:1]
+	for _, s := range list[1:] {
+		if s != result[len(result)-1] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func lowerList(list []string) []string {
+	for i, s := range list {
+		list[i] = strings.ToLower(s)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Equal reports whether the statements are semantically identical, i.e. have
+// the same Fingerprint. The Sid is not compared.
+func (s *Statement) Equal(other *Statement) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	return s.Fingerprint() == other.Fingerprint()
+}
+
+// Equal reports whether the policies are semantically identical: they contain
+// the same statements, compared using Statement.Equal, in any order.
+// Duplicate statements and the Id of the policies are ignored.
+func (p *Policy) Equal(other *Policy) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	return equalSets(p.fingerprints(), other.fingerprints())
+}
+
+func (p *Policy) fingerprints() map[string]bool {
+	result := make(map[string]bool, len(p.Statement))
+	for _, stmt := range p.Statement {
+		result[stmt.Fingerprint()] = true
+	}
+	return result
+}
+
+func equalSets(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if !b[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize rewrites the policy into its canonical form: within every
+// statement the service prefixes of actions are lowercased, all lists are
+// sorted and deduplicated, actions case-insensitively as IAM compares them,
+// and empty conditions are removed. Statements are ordered by Sid and
+// Fingerprint, and duplicate statements with the same Sid are removed.
+// Policies that are Equal, use the same Sids and spell the action names the
+// same have the same normalized form, except for their Id.
+func (p *Policy) Normalize() {
+	for _, stmt := range p.Statement {
+		stmt.Normalize()
+	}
+	keys := make(map[*Statement]string, len(p.Statement))
+	for _, stmt := range p.Statement {
+		keys[stmt] = stmt.Fingerprint()
+	}
+	sort.SliceStable(p.Statement, func(i, j int) bool {
+		a, b := p.Statement[i], p.Statement[j]
+		if sa, sb := stringValue(a.Sid), stringValue(b.Sid); sa != sb {
+			return sa < sb
+		}
+		return keys[a] < keys[b]
+	})
+	result := p.Statement[:0]
+	for i, stmt := range p.Statement {
+		if i > 0 {
+			prev := p.Statement[i-1]
+			if stringValue(prev.Sid) == stringValue(stmt.Sid) && keys[prev] == keys[stmt] {
+				continue
+			}
+		}
+		result = append(result, stmt)
+	}
+	p.Statement = result
+}
+
+// Normalize rewrites the statement into its canonical form, see
+// Policy.Normalize
+func (s *Statement) Normalize() {
+	s.Principal = s.Principal.normalize()
+	s.NotPrincipal = s.NotPrincipal.normalize()
+	s.Action = normalizeList(normalizeActions(s.Action))
+	s.NotAction = normalizeList(normalizeActions(s.NotAction))
+	s.Resources = normalizeList(s.ResourceList())
+	s.Resource = ""
+	s.NotResource = normalizeList(s.NotResource)
+	for t, vars := range s.Condition {
+		for key, values := range vars {
+			if len(values) == 0 {
+				delete(vars, key)
+				continue
+			}
+			vars[key] = normalizeList(values)
+		}
+		if len(vars) == 0 {
+			delete(s.Condition, t)
+		}
+	}
+}
+
+func (p *Principal) normalize() *Principal {
+	if p == nil {
+		return nil
+	}
+	p.Aws = normalizeList(p.Aws)
+	p.Service = normalizeList(p.Service)
+	p.Federated = normalizeList(p.Federated)
+	p.CanonicalUser = normalizeList(p.CanonicalUser)
+	return p
+}
+
+// normalizeActions lowercases the service prefix of the actions and removes
+// actions differing only in case, of which the first in sort order is kept
+func normalizeActions(actions []string) []string {
+	if len(actions) == 0 {
+		return actions
+	}
+	kept := make(map[string]string, len(actions))
+	for _, action := range actions {
+		if service, name, ok := strings.Cut(action, ":"); ok {
+			action = strings.ToLower(service) + ":" + name
+		}
+		key := strings.ToLower(action)
+		if k, ok := kept[key]; !ok || action < k {
+			kept[key] = action
+		}
+	}
+	result := actions[:0]
+	for _, action := range kept {
+		result = append(result, action)
+	}
+	return result
+}
+
+// normalizeList sorts and deduplicates the list in place, keeping empty lists
+// empty and nil lists nil
+func normalizeList(list []string) []string {
+	if len(list) == 0 {
+		return list
+	}
+	sort.Strings(list)
+	return fingerprintList(list)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// EnsureSids assigns a Sid to every statement that has none. Sids are derived
+// from the statement Fingerprint, so a statement keeps its Sid when the
+// policy is regenerated, and only contain letters and digits. A number is
+// appended when the Sid is already in use. Returns the number of Sids
+// assigned.
+func (p *Policy) EnsureSids() int {
+	used := make(map[string]bool, len(p.Statement))
+	for _, stmt := range p.Statement {
+		if stmt.Sid != nil {
+			used[*stmt.Sid] = true
+		}
+	}
+	assigned := 0
+	for _, stmt := range p.Statement {
+		if stmt.Sid != nil {
+			continue
+		}
+		base := "S" + strings.ToUpper(stmt.Fingerprint()[:12])
+		sid := base
+		for i := 2; used[sid]; i++ {
+			sid = fmt.Sprintf("%s%d", base, i)
+		}
+		used[sid] = true
+		stmt.SetSid(sid)
+		assigned++
+	}
+	return assigned
+}
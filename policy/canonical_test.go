@@ -0,0 +1,189 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestGetCanonical(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.Effect = Allow
+	stmt.AddPrincipal("arn:aws:iam::222222222222:root")
+	stmt.AddPrincipal("arn:aws:iam::111111111111:root")
+	stmt.AddAction("s3:PutObject")
+	stmt.AddAction("s3:GetObject")
+	stmt.AddResource("arn:aws:s3:::b/*")
+	stmt.AddResource("arn:aws:s3:::a/*")
+	stmt.AddCondition(ConditionIpAddress, VarSourceIp, "192.168.0.0/16")
+	stmt.AddCondition(ConditionIpAddress, VarSourceIp, "10.0.0.0/8")
+	stmt.AddCondition(ConditionBool, VarSecureTransport, "true")
+
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::111111111111:root","arn:aws:iam::222222222222:root"]},"Action":["s3:GetObject","s3:PutObject"],"Resource":["arn:aws:s3:::a/*","arn:aws:s3:::b/*"],"Condition":{"Bool":{"aws:SecureTransport":["true"]},"IpAddress":{"aws:SourceIp":["10.0.0.0/8","192.168.0.0/16"]}}}]}`
+
+	got, err := p.GetCanonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, string(got))
+	}
+
+	if stmt.Action[0] != "s3:PutObject" || stmt.Condition[ConditionIpAddress][VarSourceIp][0] != "192.168.0.0/16" {
+		t.Error("GetCanonical modified the policy")
+	}
+}
+
+func TestStatementFingerprint(t *testing.T) {
+	p := NewPolicy()
+	a := p.AddStatement()
+	a.SetSid("a")
+	a.Effect = Allow
+	a.AddAction("s3:GetObject")
+	a.AddAction("s3:PutObject")
+	a.AddResource("*")
+	a.AddCondition(ConditionIpAddress, VarSourceIp, "10.0.0.0/8")
+
+	loaded, err := LoadPolicy([]byte(`{
+		"Version": "2012-10-17",
+		"Statement": {
+			"Effect": "Allow",
+			"Principal": {"AWS": []},
+			"Action": ["S3:putObject", "s3:GetObject", "s3:GetObject"],
+			"Resource": "*",
+			"Condition": {"IpAddress": {"aws:SourceIp": "10.0.0.0/8"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := loaded.Statement[0]
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("Expected equal fingerprints got %s and %s", a.Fingerprint(), b.Fingerprint())
+	}
+	if len(a.Fingerprint()) != 64 {
+		t.Errorf("Expected hex encoded SHA-256 got %s", a.Fingerprint())
+	}
+
+	b.Effect = Deny
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Error("Expected different fingerprints for different effects")
+	}
+	b.Effect = Allow
+	b.AddResource("arn:aws:s3:::bucket")
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Error("Expected different fingerprints for different resources")
+	}
+}
+
+func TestPolicyEqual(t *testing.T) {
+	a, err := LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[{"Sid":"Read","Effect":"Allow","Action":"s3:GetObject","Resource":["arn:aws:s3:::a/*","arn:aws:s3:::b/*"],"Condition":{"Bool":{"aws:SecureTransport":"true"},"StringEquals":{"aws:PrincipalTag/team":["red","blue"]}}},{"Effect":"Deny","Action":"*","Resource":"*"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":["*"],"Resource":["*"]},{"Effect":"Allow","Action":["S3:GetObject"],"Resource":["arn:aws:s3:::b/*","arn:aws:s3:::a/*"],"Condition":{"StringEquals":{"aws:PrincipalTag/team":["blue","red"]},"Bool":{"aws:SecureTransport":["true"]}}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Errorf("Expected policies to be equal")
+	}
+	if !a.Statement[0].Equal(b.Statement[1]) || a.Statement[0].Equal(b.Statement[0]) {
+		t.Errorf("Expected only the matching statements to be equal")
+	}
+
+	b.Statement[1].AddResource("arn:aws:s3:::c/*")
+	if a.Equal(b) {
+		t.Errorf("Expected policies to differ")
+	}
+	if a.Equal(nil) || !(*Policy)(nil).Equal(nil) {
+		t.Errorf("Expected nil policies to only equal nil")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	p, err := LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"*","Resource":"*"},{"Sid":"Read","Effect":"Allow","Principal":{"AWS":["b","a","b"]},"Action":["S3:ListBucket","s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::b/*","arn:aws:s3:::a/*"],"Condition":{"StringEquals":{"aws:PrincipalTag/team":["red","blue","red"]},"Bool":{}}},{"Effect":"Allow","Action":"ec2:DescribeInstances","Resource":"*"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := p.Clone()
+	p.Normalize()
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["ec2:DescribeInstances"],"Resource":["*"]},{"Effect":"Deny","Action":["*"],"Resource":["*"]},{"Sid":"Read","Effect":"Allow","Principal":{"AWS":["a","b"]},"Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::a/*","arn:aws:s3:::b/*"],"Condition":{"StringEquals":{"aws:PrincipalTag/team":["blue","red"]}}}]}`
+
+	assertPolicy(t, p, expected)
+	if !p.Equal(original) {
+		t.Errorf("Expected normalized policy to equal the original")
+	}
+}
+
+func TestNormalizeEqual(t *testing.T) {
+	tests := [][2]string{
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","s3:getobject"],"Resource":"*"}]}`,
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["S3:GetObject"],"Resource":["*","*"]}]}`,
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Sid":"A","Effect":"Allow","Action":["s3:GetObject"],"Resource":"*"},{"Effect":"Deny","Action":"*","Resource":"*"}]}`,
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":["*"],"Resource":["*"]},{"Sid":"A","Effect":"Allow","Action":["S3:GetObject"],"Resource":["*"]},{"Effect":"Deny","Action":"*","Resource":"*"}]}`,
+		},
+	}
+
+	for i, test := range tests {
+		a, err := LoadPolicy([]byte(test[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := LoadPolicy([]byte(test[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !a.Equal(b) {
+			t.Errorf("%d: Expected policies to be equal", i)
+			continue
+		}
+		a.Normalize()
+		b.Normalize()
+		if a.String() != b.String() {
+			t.Errorf("%d: Expected equal policies to normalize to the same form got \n%s and \n%s", i, a, b)
+		}
+	}
+}
+
+func TestEnsureSids(t *testing.T) {
+	p := Build().
+		Allow().Actions("s3:GetObject").Resources("*").
+		Allow().Sid("Named").Actions("s3:PutObject").Resources("*").
+		Allow().Actions("s3:GetObject").Resources("*").
+		Done()
+
+	if n := p.EnsureSids(); n != 2 {
+		t.Errorf("Expected 2 Sids to be assigned got %d", n)
+	}
+	first, second := *p.Statement[0].Sid, *p.Statement[2].Sid
+	if first == second || second != first+"2" {
+		t.Errorf("Expected unique Sids got %s and %s", first, second)
+	}
+	if *p.Statement[1].Sid != "Named" {
+		t.Errorf("Expected existing Sid to be kept got %s", *p.Statement[1].Sid)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Failed validating policy: %s", err)
+	}
+
+	q := Build().Allow().Actions("s3:GetObject").Resources("*").Done()
+	q.EnsureSids()
+	if *q.Statement[0].Sid != first {
+		t.Errorf("Expected stable Sid %s got %s", first, *q.Statement[0].Sid)
+	}
+	if n := q.EnsureSids(); n != 0 {
+		t.Errorf("Expected no Sids to be assigned got %d", n)
+	}
+}
@@ -0,0 +1,131 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parsing error when an element name, principal type or condition operator
+// is not written in the case defined by the policy grammar
+type ElementCaseError string
+
+func (s ElementCaseError) Error() string {
+	return fmt.Sprintf("Invalid case for element %s", string(s))
+}
+
+// checkElementCase finds keys in the document that only differ in case from
+// an element of the policy grammar. With fix set the keys are rewritten to the
+// proper case in a copy of the document, otherwise an ElementCaseError is
+// returned for the first one. encoding/json would otherwise match such keys
+// case-insensitively for some elements and not at all for others. Only ASCII
+// keys are rewritten, keys matching through a Unicode case folding such as
+// "Reſource" are always rejected.
+func checkElementCase(data []byte, fix bool, c Codec) ([]byte, error) {
+	if fix {
+		data = append([]byte(nil), data...)
+	}
+	var found error
+	w := &jsonWalker{data: data, dec: c.NewTokenizer(data)}
+	w.key = func(path []string, offset int64, raw []byte) bool {
+		key := path[len(path)-1]
+		name := canonicalElement(path[:len(path)-1], key)
+		if name == "" || name == key {
+			return true
+		}
+		if !fix || string(raw) != `"`+key+`"` || len(key) != len(name) || !isASCII(key) {
+			found = &ParseError{Path: formatPath(path), Offset: offset, Err: ElementCaseError(key)}
+			return false
+		}
+		copy(raw[1:], name)
+		return true
+	}
+	// Syntax errors are reported when the document is decoded
+	w.walk()
+	return data, found
+}
+
+// canonicalElement returns the name of the element the key refers to given the
+// path of the object containing it, or an empty string if the key is not a
+// case variant of a known element
+func canonicalElement(parent []string, key string) string {
+	switch {
+	case len(parent) == 0:
+		return matchFold(key, policyFields)
+	case isStatementPath(parent):
+		return matchFold(key, statementFields)
+	case len(parent) >= 2 && isStatementPath(parent[:len(parent)-1]):
+		switch strings.ToLower(parent[len(parent)-1]) {
+		case "principal", "notprincipal":
+			return matchFold(key, principalFields)
+		case "condition":
+			return canonicalConditionType(key)
+		}
+	}
+	return ""
+}
+
+// isStatementPath reports whether the path refers to a statement, either an
+// element of the Statement array or a single Statement object
+func isStatementPath(path []string) bool {
+	switch len(path) {
+	case 1:
+		return strings.EqualFold(path[0], "Statement")
+	case 2:
+		return strings.EqualFold(path[0], "Statement") && isIndex(path[1])
+	}
+	return false
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func matchFold(s string, names []string) string {
+	for _, name := range names {
+		if strings.EqualFold(s, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// canonicalConditionType returns the properly cased form of a condition
+// operator, including set operator and IfExists suffix
+func canonicalConditionType(s string) string {
+	prefix := ""
+	if i := strings.Index(s, ":"); i >= 0 {
+		set := matchFold(s[:i], []string{string(ForAllValues), string(ForAnyValue)})
+		if set == "" {
+			return ""
+		}
+		prefix = set + ":"
+		s = s[i+1:]
+	}
+	for t := range conditionTypes {
+		if strings.EqualFold(s, string(t)) {
+			return prefix + string(t)
+		}
+	}
+	if len(s) > len(conditionIfExists) && strings.EqualFold(s[len(s)-len(conditionIfExists):], conditionIfExists) {
+		base := s[:len(s)-len(conditionIfExists)]
+		for t := range conditionTypes {
+			if strings.EqualFold(base, string(t)) {
+				return prefix + string(t) + conditionIfExists
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCaseInsensitiveNames(t *testing.T) {
+	data := []byte(`{"version":"2012-10-17","statement":[{"sid":"a","EFFECT":"Allow","principal":{"aws":["*"],"service":["ec2.amazonaws.com"]},"action":["*"],"resource":["*"],"condition":{"forallvalues:stringequalsifexists":{"aws:TagKeys":["team"]},"IPADDRESS":{"aws:SourceIp":["10.0.0.0/8"]}}}]}`)
+	expected := `{"Version":"2012-10-17","Statement":[{"Sid":"a","Effect":"Allow","Principal":{"AWS":["*"],"Service":["ec2.amazonaws.com"]},"Action":["*"],"Resource":["*"],"Condition":{"ForAllValues:StringEqualsIfExists":{"aws:TagKeys":["team"]},"IpAddress":{"aws:SourceIp":["10.0.0.0/8"]}}}]}`
+
+	p, err := LoadPolicyWithOptions(data, ParseOptions{CaseInsensitiveNames: true})
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	assertPolicy(t, p, expected)
+
+	if string(data[2:9]) != "version" {
+		t.Error("Loading modified the document")
+	}
+}
+
+func TestCaseInsensitiveNamesUnicode(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"*","Reſource":"*"}]}`)
+	_, err := LoadPolicyWithOptions(data, ParseOptions{CaseInsensitiveNames: true})
+	var caseErr ElementCaseError
+	if !errors.As(err, &caseErr) || string(caseErr) != "Reſource" {
+		t.Errorf("Expected ElementCaseError got %v", err)
+	}
+}
+
+func TestElementCaseError(t *testing.T) {
+	tests := []struct {
+		data string
+		path string
+	}{
+		{
+			`{"Version":"2012-10-17","Statement":[{"effect":"Allow"}]}`,
+			"Statement[0].effect",
+		},
+		{
+			`{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":{"Aws":["*"]}}}`,
+			"Statement.Principal.Aws",
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Condition":{"stringEquals":{"aws:username":["bob"]}}}]}`,
+			"Statement[0].Condition.stringEquals",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := LoadPolicyWithOptions([]byte(test.data), ParseOptions{DisallowCaseVariants: true})
+		var parseErr *ParseError
+		var caseErr ElementCaseError
+		if !errors.As(err, &parseErr) || !errors.As(err, &caseErr) {
+			t.Errorf("Expected ElementCaseError got %v", err)
+			continue
+		}
+		if parseErr.Path != test.path {
+			t.Errorf("Expected error at %s got %s", test.path, parseErr.Path)
+		}
+	}
+}
+
+func TestLoadPolicyCaseVariants(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"effect":"Allow","action":["s3:GetObject"],"Resource":"*"}]}`)
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":"*"}]}`
+
+	p, err := LoadPolicy(data)
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	assertPolicy(t, p, expected)
+
+	_, err = LoadPolicyStrict(data)
+	var caseErr ElementCaseError
+	if !errors.As(err, &caseErr) {
+		t.Errorf("Expected ElementCaseError got %v", err)
+	}
+}
+
+func TestCaseInsensitiveDuplicates(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","effect":"Deny"}]}`)
+
+	_, err := LoadPolicyWithOptions(data, ParseOptions{CaseInsensitiveNames: true})
+	var keyErr DuplicateKeyError
+	if !errors.As(err, &keyErr) {
+		t.Errorf("Expected DuplicateKeyError got %v", err)
+	}
+}
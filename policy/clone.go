@@ -0,0 +1,88 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+// Clone returns a deep copy of the policy, changes to the copy do not affect
+// the original
+func (p *Policy) Clone() *Policy {
+	if p == nil {
+		return nil
+	}
+	c := &Policy{Version: p.Version, Id: cloneString(p.Id), codec: p.codec}
+	if p.Statement != nil {
+		c.Statement = make([]*Statement, len(p.Statement))
+		for i, stmt := range p.Statement {
+			c.Statement[i] = stmt.Clone()
+		}
+	}
+	return c
+}
+
+// Clone returns a deep copy of the statement, changes to the copy do not
+// affect the original
+func (s *Statement) Clone() *Statement {
+	if s == nil {
+		return nil
+	}
+	c := &Statement{
+		Kind:         s.Kind,
+		Sid:          cloneString(s.Sid),
+		Effect:       s.Effect,
+		Principal:    s.Principal.Clone(),
+		NotPrincipal: s.NotPrincipal.Clone(),
+		Action:       cloneList(s.Action),
+		NotAction:    cloneList(s.NotAction),
+		Resource:     s.Resource,
+		Resources:    cloneList(s.Resources),
+		NotResource:  cloneList(s.NotResource),
+	}
+	if s.Condition != nil {
+		c.Condition = make(map[ConditionType]map[ConditionVariable][]string, len(s.Condition))
+		for t, vars := range s.Condition {
+			if vars == nil {
+				c.Condition[t] = nil
+				continue
+			}
+			c.Condition[t] = make(map[ConditionVariable][]string, len(vars))
+			for key, values := range vars {
+				c.Condition[t][key] = cloneList(values)
+			}
+		}
+	}
+	return c
+}
+
+// Clone returns a deep copy of the principal
+func (p *Principal) Clone() *Principal {
+	if p == nil {
+		return nil
+	}
+	return &Principal{
+		Aws:           cloneList(p.Aws),
+		Service:       cloneList(p.Service),
+		Federated:     cloneList(p.Federated),
+		CanonicalUser: cloneList(p.CanonicalUser),
+	}
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func cloneList(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	c := make([]string, len(list), cap(list))
+	copy(c, list)
+	return c
+}
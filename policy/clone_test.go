@@ -0,0 +1,41 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	p := NewPolicy()
+	p.SetId("original")
+	stmt := p.AddStatement()
+	stmt.SetSid("a")
+	stmt.AddPrincipal("arn:aws:iam::123456789012:root")
+	stmt.AddAction("s3:GetObject")
+	stmt.AddResource("arn:aws:s3:::bucket/*")
+	stmt.AddCondition(ConditionStringEquals, "aws:PrincipalTag/team", "red")
+	expected := p.String()
+
+	c := p.Clone()
+	if c.String() != expected {
+		t.Fatalf("Expected \n%s got \n%s", expected, c.String())
+	}
+
+	c.SetId("copy")
+	*c.Statement[0].Sid = "b"
+	c.Statement[0].AddPrincipal("*")
+	c.Statement[0].Action[0] = "s3:PutObject"
+	c.Statement[0].AddCondition(ConditionStringEquals, "aws:PrincipalTag/team", "blue")
+	c.Statement[0].AddCondition(ConditionBool, VarSecureTransport, "true")
+	c.AddStatement()
+
+	if p.String() != expected {
+		t.Errorf("Expected original to be unchanged \n%s got \n%s", expected, p.String())
+	}
+}
@@ -0,0 +1,70 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Codec serializes policies. Implementations must honor the json.Marshaler
+// and json.Unmarshaler implementations of the policy types, which the common
+// alternatives to encoding/json do. Embed StdCodec to only replace some of
+// the methods.
+//
+// The codec only handles the outer document. The MarshalJSON and
+// UnmarshalJSON methods of Policy, Statement and Principal, the condition
+// values and the reformatting done by Render and String still use
+// encoding/json, so replacing the codec does not remove its cost.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	// NewTokenizer returns a Tokenizer over the document, used to find
+	// duplicate keys and the location of errors when loading
+	NewTokenizer(data []byte) Tokenizer
+}
+
+// Tokenizer reads a JSON document token by token, as json.Decoder does
+type Tokenizer interface {
+	Token() (json.Token, error)
+	More() bool
+	InputOffset() int64
+}
+
+// StdCodec is the Codec backed by encoding/json, used by default
+type StdCodec struct{}
+
+func (StdCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (StdCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (StdCodec) NewTokenizer(data []byte) Tokenizer {
+	return json.NewDecoder(bytes.NewReader(data))
+}
+
+// SetCodec sets the Codec used by Get, String, Render and GetCanonical to
+// marshal the policy. Passing nil restores the default. Policies loaded with
+// ParseOptions.Codec use that codec.
+func (p *Policy) SetCodec(c Codec) {
+	p.codec = c
+}
+
+func (p *Policy) getCodec() Codec {
+	return defaultCodec(p.codec)
+}
+
+func defaultCodec(c Codec) Codec {
+	if c == nil {
+		return StdCodec{}
+	}
+	return c
+}
@@ -0,0 +1,73 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+type countingCodec struct {
+	StdCodec
+	marshaled, unmarshaled, tokenized int
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshaled++
+	return c.StdCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshaled++
+	return c.StdCodec.Unmarshal(data, v)
+}
+
+func (c *countingCodec) NewTokenizer(data []byte) Tokenizer {
+	c.tokenized++
+	return c.StdCodec.NewTokenizer(data)
+}
+
+func TestParseOptionsCodec(t *testing.T) {
+	c := &countingCodec{}
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["*"],"Resource":["*"]}]}`)
+	p, err := LoadPolicyWithOptions(data, ParseOptions{Codec: c})
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	if _, err := p.Get(); err != nil {
+		t.Fatalf("Failed getting policy: %s", err)
+	}
+	if c.marshaled != 1 || c.unmarshaled != 1 || c.tokenized != 1 {
+		t.Errorf("Expected codec to be used once for each direction and to find duplicate keys got %d marshals, %d unmarshals and %d tokenizers", c.marshaled, c.unmarshaled, c.tokenized)
+	}
+
+	// The strict checks decode the document with the codec as well
+	strict := StrictParseOptions
+	strict.Codec = &countingCodec{}
+	if _, err := LoadPolicyWithOptions(data, strict); err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	if c := strict.Codec.(*countingCodec); c.unmarshaled != 2 || c.tokenized != 2 {
+		t.Errorf("Expected strict checks to use the codec got %d unmarshals and %d tokenizers", c.unmarshaled, c.tokenized)
+	}
+}
+
+func TestPolicySetCodec(t *testing.T) {
+	c := &countingCodec{}
+	p := NewPolicy()
+	p.SetCodec(c)
+	p.AddIdentityStatement().WithEffect(Allow).WithAction("*").WithResource("*")
+	if _, err := p.Clone().Get(); err != nil {
+		t.Fatalf("Failed getting policy: %s", err)
+	}
+	if _, err := NewPolicy().Get(); err != nil {
+		t.Fatalf("Failed getting policy: %s", err)
+	}
+	if c.marshaled != 1 {
+		t.Errorf("Expected codec to be used by the policy and its clone only got %d marshals", c.marshaled)
+	}
+}
@@ -0,0 +1,73 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The W3C profile of ISO 8601 used for date condition values
+const conditionDateFormat = "2006-01-02T15:04:05Z"
+
+// Add a date condition to the statement, the time is formatted in UTC as
+// expected by AWS. Returns an InvalidConditionTypeError if op is not one of the
+// Date operators.
+func (s *Statement) AddDateCondition(op ConditionType, key ConditionVariable, t time.Time) error {
+	if !op.Valid() || !strings.HasPrefix(string(op.Operator()), "Date") {
+		return InvalidConditionTypeError(op)
+	}
+	s.AddCondition(op, key, t.UTC().Format(conditionDateFormat))
+	return nil
+}
+
+// Add an IpAddress (allow true) or NotIpAddress (allow false) condition to the
+// statement for the given CIDR blocks. Nothing is added and an
+// InvalidConditionValueError is returned if any of the prefixes is invalid.
+func (s *Statement) AddIPCondition(allow bool, key ConditionVariable, cidrs ...netip.Prefix) error {
+	for _, cidr := range cidrs {
+		if !cidr.IsValid() {
+			return InvalidConditionValueError(cidr.String())
+		}
+	}
+	t := ConditionNotIpAddress
+	if allow {
+		t = ConditionIpAddress
+	}
+	for _, cidr := range cidrs {
+		s.AddCondition(t, key, cidr.String())
+	}
+	return nil
+}
+
+// Add a numeric condition with one or more values to the statement. Returns an
+// InvalidConditionTypeError if op is not one of the Numeric operators.
+func (s *Statement) AddNumericCondition(op ConditionType, key ConditionVariable, values ...float64) error {
+	if !op.Valid() || !strings.HasPrefix(string(op.Operator()), "Numeric") {
+		return InvalidConditionTypeError(op)
+	}
+	for _, v := range values {
+		s.AddCondition(op, key, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return nil
+}
+
+// Add a numeric condition with one or more integer values to the statement.
+// Returns an InvalidConditionTypeError if op is not one of the Numeric
+// operators.
+func (s *Statement) AddIntCondition(op ConditionType, key ConditionVariable, values ...int64) error {
+	if !op.Valid() || !strings.HasPrefix(string(op.Operator()), "Numeric") {
+		return InvalidConditionTypeError(op)
+	}
+	for _, v := range values {
+		s.AddCondition(op, key, strconv.FormatInt(v, 10))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestAddDateCondition(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddIdentityStatement()
+	cet := time.FixedZone("CET", 3600)
+	if err := stmt.AddDateCondition(ConditionDateLessThan, VarCurrentTime, time.Date(2024, 1, 1, 1, 30, 0, 500, cet)); err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.AddDateCondition(ConditionDateGreaterThan.IfExists(), "aws:TokenIssueTime", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Condition":{"DateGreaterThanIfExists":{"aws:TokenIssueTime":["2023-06-01T00:00:00Z"]},"DateLessThan":{"aws:CurrentTime":["2024-01-01T00:30:00Z"]}}}]}`
+
+	assertPolicy(t, p, expected)
+
+	if err := stmt.AddDateCondition(ConditionNumericLessThan, VarCurrentTime, time.Now()); err != InvalidConditionTypeError(ConditionNumericLessThan) {
+		t.Errorf("Expected InvalidConditionTypeError got %v", err)
+	}
+}
+
+func TestAddIPCondition(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddIdentityStatement()
+	if err := stmt.AddIPCondition(true, VarSourceIp, netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("2001:db8::/32")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.AddIPCondition(false, VarSourceIp, netip.MustParsePrefix("10.1.0.0/16")); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Condition":{"IpAddress":{"aws:SourceIp":["10.0.0.0/8","2001:db8::/32"]},"NotIpAddress":{"aws:SourceIp":["10.1.0.0/16"]}}}]}`
+
+	assertPolicy(t, p, expected)
+
+	if err := stmt.AddIPCondition(true, VarSourceIp, netip.MustParsePrefix("192.168.0.0/16"), netip.Prefix{}); err != InvalidConditionValueError("invalid Prefix") {
+		t.Errorf("Expected InvalidConditionValueError got %v", err)
+	}
+	assertPolicy(t, p, expected)
+}
+
+func TestAddNumericCondition(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddIdentityStatement()
+	if err := stmt.AddIntCondition(ConditionNumericLessThan, VarMultiFactorAuthAge, 3600); err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.AddNumericCondition(ConditionNumericEquals.IfExists(), "s3:max-keys", 10, 0.5, 1e21); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Condition":{"NumericEqualsIfExists":{"s3:max-keys":["10","0.5","1000000000000000000000"]},"NumericLessThan":{"aws:MultiFactorAuthAge":["3600"]}}}]}`
+
+	assertPolicy(t, p, expected)
+
+	if err := stmt.AddIntCondition(ConditionDateLessThan, VarMultiFactorAuthAge, 1); err != InvalidConditionTypeError(ConditionDateLessThan) {
+		t.Errorf("Expected InvalidConditionTypeError got %v", err)
+	}
+}
@@ -0,0 +1,563 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gwkunze/goiam/conditionkeys"
+)
+
+// ConditionExprError is returned when a condition expression can not be parsed
+type ConditionExprError struct {
+	// Byte offset in the expression the error occurred at
+	Pos int
+	Msg string
+}
+
+func (e *ConditionExprError) Error() string {
+	return fmt.Sprintf("Invalid condition expression at %d: %s", e.Pos, e.Msg)
+}
+
+// The kind of value a condition compares, used to pick the condition operator
+// for the comparison operators of an expression
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumeric
+	kindDate
+	kindBool
+	kindIp
+	kindArn
+)
+
+// Value kinds of the well-known condition keys, by lowercased key
+var exprKeyKinds = map[string]valueKind{
+	"aws:currenttime":                  kindDate,
+	"aws:epochtime":                    kindDate,
+	"aws:multifactorauthage":           kindNumeric,
+	"aws:securetransport":              kindBool,
+	"aws:sourcearn":                    kindArn,
+	"aws:sourceip":                     kindIp,
+	"aws:principalarn":                 kindArn,
+	"aws:principalisawsservice":        kindBool,
+	"aws:multifactorauthpresent":       kindBool,
+	"aws:tokenissuetime":               kindDate,
+	"aws:assumedroot":                  kindBool,
+	"aws:ec2instancesourceprivateipv4": kindIp,
+	"aws:vpcsourceip":                  kindIp,
+	"aws:viaawsservice":                kindBool,
+	"aws:principalaccount":             kindString,
+	"aws:principalorgid":               kindString,
+	"aws:principalorgpaths":            kindString,
+	"aws:principaltype":                kindString,
+	"aws:principalservicename":         kindString,
+	"aws:principalservicenameslist":    kindString,
+	"aws:resourceaccount":              kindString,
+	"aws:resourceorgid":                kindString,
+	"aws:resourceorgpaths":             kindString,
+	"aws:sourceaccount":                kindString,
+	"aws:sourceorgid":                  kindString,
+	"aws:sourceorgpaths":               kindString,
+	"aws:sourceowner":                  kindString,
+	"aws:requestedregion":              kindString,
+	"aws:userid":                       kindString,
+	"aws:username":                     kindString,
+	"aws:sourceidentity":               kindString,
+	"aws:federatedprovider":            kindString,
+	"aws:sourcevpc":                    kindString,
+	"aws:sourcevpce":                   kindString,
+	"aws:ec2instancesourcevpc":         kindString,
+	"aws:calledvia":                    kindString,
+	"aws:calledviafirst":               kindString,
+	"aws:calledvialast":                kindString,
+	"aws:referer":                      kindString,
+	"aws:useragent":                    kindString,
+	"aws:tagkeys":                      kindString,
+}
+
+// Value kinds of the conditionkeys catalog types
+var catalogKinds = map[conditionkeys.Type]valueKind{
+	conditionkeys.TypeString:        kindString,
+	conditionkeys.TypeArrayOfString: kindString,
+	conditionkeys.TypeNumeric:       kindNumeric,
+	conditionkeys.TypeDate:          kindDate,
+	conditionkeys.TypeBool:          kindBool,
+	conditionkeys.TypeARN:           kindArn,
+}
+
+// keyKind returns the value kind of a well-known global or service specific
+// condition key. Tag keys such as aws:ResourceTag/CostCenter hold strings.
+func keyKind(key ConditionVariable) (valueKind, bool) {
+	name := strings.ToLower(string(key))
+	if kind, ok := exprKeyKinds[name]; ok {
+		return kind, true
+	}
+	if _, tag, ok := strings.Cut(name, ":"); ok && strings.Contains(tag, "tag/") {
+		return kindString, true
+	}
+	if t, ok := conditionkeys.TypeOf(string(key)); ok {
+		return catalogKinds[t], true
+	}
+	return 0, false
+}
+
+var exprOperators = map[string]map[valueKind]ConditionType{
+	"=": {
+		kindString:  ConditionStringEquals,
+		kindNumeric: ConditionNumericEquals,
+		kindDate:    ConditionDateEquals,
+		kindBool:    ConditionBool,
+		kindIp:      ConditionIpAddress,
+		kindArn:     ConditionArnEquals,
+	},
+	"!=": {
+		kindString:  ConditionStringNotEquals,
+		kindNumeric: ConditionNumericNotEquals,
+		kindDate:    ConditionDateNotEquals,
+		kindIp:      ConditionNotIpAddress,
+		kindArn:     ConditionArnNotEquals,
+	},
+	"like": {
+		kindString: ConditionStringLike,
+		kindArn:    ConditionArnLike,
+	},
+	"not like": {
+		kindString: ConditionStringNotLike,
+		kindArn:    ConditionArnNotLike,
+	},
+	"<": {
+		kindNumeric: ConditionNumericLessThan,
+		kindDate:    ConditionDateLessThan,
+	},
+	"<=": {
+		kindNumeric: ConditionNumericLessThanEquals,
+		kindDate:    ConditionDateLessThanEquals,
+	},
+	">": {
+		kindNumeric: ConditionNumericGreaterThan,
+		kindDate:    ConditionDateGreaterThan,
+	},
+	">=": {
+		kindNumeric: ConditionNumericGreaterThanEquals,
+		kindDate:    ConditionDateGreaterThanEquals,
+	},
+}
+
+// The order in which FormatCondition tries the comparison operators
+var exprOperatorOrder = []string{"=", "!=", "like", "not like", "<", "<=", ">", ">="}
+
+// ParseCondition compiles a condition expression into a Condition map. An
+// expression is a list of clauses joined by AND, each comparing a condition
+// key to one or a list of values:
+//
+//	aws:SourceIp in [10.0.0.0/8, 192.168.0.0/16] AND aws:SecureTransport = true
+//
+// The comparison operators are =, !=, in, not in, like, not like, <, <=, > and
+// >=. The condition operator is chosen based on the key, e.g. account ids and
+// tags are compared as strings. Only for unknown keys it is chosen on the
+// values: booleans, numbers, IP addresses, ARNs and dates are recognized,
+// quoted values are always strings. "key exists" and "key not
+// exists" produce Null conditions. Any condition operator can also be used
+// directly, e.g. aws:TagKeys ForAllValues:StringEquals [team, env].
+//
+// A key can only be compared with the same condition operator once, as a
+// condition matches when any of its values matches: "a = x AND a = y" is
+// rejected, "a in [x, y]" matches either value. Clauses with a negated
+// operator, such as "a != x AND a != y", are combined.
+func ParseCondition(expr string) (map[ConditionType]map[ConditionVariable][]string, error) {
+	p := &exprParser{expr: expr}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	result := make(map[ConditionType]map[ConditionVariable][]string)
+	for {
+		start := p.peek()
+		t, key, values, err := p.clause()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result[t]; !ok {
+			result[t] = make(map[ConditionVariable][]string)
+		}
+		// IAM matches any of the values of a key, merging the clauses would
+		// turn AND into OR. Negated operators match when none of the values
+		// match, so their clauses can be merged.
+		if _, ok := result[t][key]; ok && !mergeableClauses(t) {
+			return nil, p.errorf(start, "%s compared with %s more than once, use a list of values", key, t)
+		}
+		result[t][key] = append(result[t][key], values...)
+
+		tok := p.next()
+		if tok.kind == tokenEOF {
+			return result, nil
+		}
+		if tok.kind != tokenWord || tok.quoted || !strings.EqualFold(tok.text, "AND") {
+			return nil, p.errorf(tok, "expected AND, got %q", tok.text)
+		}
+	}
+}
+
+// mergeableClauses reports whether clauses comparing the same key with the
+// operator can be combined into one list of values
+func mergeableClauses(t ConditionType) bool {
+	return t.SetOperator() == "" && strings.Contains(string(t.Operator()), "Not")
+}
+
+// Add the conditions described by the expression to the statement, see
+// ParseCondition for the syntax
+func (s *Statement) AddConditionExpr(expr string) error {
+	c, err := ParseCondition(expr)
+	if err != nil {
+		return err
+	}
+	for t, vars := range c {
+		for key, values := range vars {
+			for _, value := range values {
+				s.AddCondition(t, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// FormatCondition renders a Condition map as an expression that ParseCondition
+// compiles back into the same map
+func FormatCondition(c map[ConditionType]map[ConditionVariable][]string) string {
+	types := make([]string, 0, len(c))
+	for t := range c {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+
+	clauses := make([]string, 0)
+	for _, t := range types {
+		vars := c[ConditionType(t)]
+		keys := make([]string, 0, len(vars))
+		for key := range vars {
+			keys = append(keys, string(key))
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			clauses = append(clauses, formatClause(ConditionType(t), ConditionVariable(key), vars[ConditionVariable(key)]))
+		}
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// formatClause renders a single clause using the most readable form that
+// parses back into the same condition operator
+func formatClause(t ConditionType, key ConditionVariable, values []string) string {
+	candidates := make([]string, 0, 2)
+	if t == ConditionNull && len(values) == 1 {
+		switch values[0] {
+		case "false":
+			candidates = append(candidates, fmt.Sprintf("%s exists", formatValue(string(key), false)))
+		case "true":
+			candidates = append(candidates, fmt.Sprintf("%s not exists", formatValue(string(key), false)))
+		}
+	}
+	for _, op := range exprOperatorOrder {
+		for _, opType := range exprOperators[op] {
+			if opType == t && len(values) > 0 {
+				candidates = append(candidates, formatComparison(key, op, values, false))
+				candidates = append(candidates, formatComparison(key, op, values, true))
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		parsed, err := ParseCondition(candidate)
+		if err == nil && len(parsed) == 1 && equalValues(parsed[t][key], values) {
+			return candidate
+		}
+	}
+	return fmt.Sprintf("%s %s %s", formatValue(string(key), false), t, formatValues(values, false))
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatComparison(key ConditionVariable, op string, values []string, quote bool) string {
+	if len(values) > 1 {
+		switch op {
+		case "=":
+			op = "in"
+		case "!=":
+			op = "not in"
+		}
+	}
+	return fmt.Sprintf("%s %s %s", formatValue(string(key), false), op, formatValues(values, quote))
+}
+
+func formatValues(values []string, quote bool) string {
+	if len(values) == 1 {
+		return formatValue(values[0], quote)
+	}
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = formatValue(value, quote)
+	}
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+func formatValue(value string, quote bool) string {
+	if quote || value == "" || strings.ContainsAny(value, " \t\r\n[],\"=!<>") || isExprKeyword(value) {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func isExprKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case "and", "in", "not", "like", "exists":
+		return true
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenOperator
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+type exprToken struct {
+	kind   tokenKind
+	text   string
+	pos    int
+	quoted bool
+}
+
+type exprParser struct {
+	expr   string
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) errorf(tok exprToken, format string, args ...interface{}) error {
+	return &ConditionExprError{Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *exprParser) tokenize() error {
+	s := p.expr
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '[':
+			p.tokens = append(p.tokens, exprToken{kind: tokenOpen, text: "[", pos: i})
+			i++
+		case c == ']':
+			p.tokens = append(p.tokens, exprToken{kind: tokenClose, text: "]", pos: i})
+			i++
+		case c == ',':
+			p.tokens = append(p.tokens, exprToken{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return &ConditionExprError{Pos: i, Msg: "unterminated string"}
+			}
+			text, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return &ConditionExprError{Pos: i, Msg: "invalid string " + s[i:end+1]}
+			}
+			p.tokens = append(p.tokens, exprToken{kind: tokenWord, text: text, pos: i, quoted: true})
+			i = end + 1
+		case strings.IndexByte("=!<>", c) >= 0:
+			end := i + 1
+			if end < len(s) && s[end] == '=' {
+				end++
+			}
+			op := s[i:end]
+			if op == "!" || op == "==" {
+				return &ConditionExprError{Pos: i, Msg: "invalid operator " + op}
+			}
+			p.tokens = append(p.tokens, exprToken{kind: tokenOperator, text: op, pos: i})
+			i = end
+		default:
+			end := i
+			for end < len(s) && strings.IndexByte(" \t\r\n[],\"=!<>", s[end]) < 0 {
+				end++
+			}
+			p.tokens = append(p.tokens, exprToken{kind: tokenWord, text: s[i:end], pos: i})
+			i = end
+		}
+	}
+	p.tokens = append(p.tokens, exprToken{kind: tokenEOF, pos: len(s)})
+	return nil
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) isKeyword(tok exprToken, keyword string) bool {
+	return tok.kind == tokenWord && !tok.quoted && strings.EqualFold(tok.text, keyword)
+}
+
+// clause parses a single "key operator values" comparison
+func (p *exprParser) clause() (ConditionType, ConditionVariable, []string, error) {
+	tok := p.next()
+	if tok.kind != tokenWord {
+		return "", "", nil, p.errorf(tok, "expected condition key, got %q", tok.text)
+	}
+	key := ConditionVariable(tok.text)
+
+	tok = p.next()
+	var op string
+	switch {
+	case tok.kind == tokenOperator:
+		op = tok.text
+	case p.isKeyword(tok, "in"):
+		op = "="
+	case p.isKeyword(tok, "like"):
+		op = "like"
+	case p.isKeyword(tok, "exists"):
+		return ConditionNull, key, []string{"false"}, nil
+	case p.isKeyword(tok, "not"):
+		next := p.next()
+		switch {
+		case p.isKeyword(next, "in"):
+			op = "!="
+		case p.isKeyword(next, "like"):
+			op = "not like"
+		case p.isKeyword(next, "exists"):
+			return ConditionNull, key, []string{"true"}, nil
+		default:
+			return "", "", nil, p.errorf(next, "expected in, like or exists after not, got %q", next.text)
+		}
+	case tok.kind == tokenWord && !tok.quoted && ConditionType(tok.text).Valid():
+		values, _, err := p.values()
+		return ConditionType(tok.text), key, values, err
+	default:
+		return "", "", nil, p.errorf(tok, "expected operator, got %q", tok.text)
+	}
+
+	opTok := tok
+	values, quoted, err := p.values()
+	if err != nil {
+		return "", "", nil, err
+	}
+	kind, ok := keyKind(key)
+	if !ok {
+		kind = inferKind(values, quoted)
+	}
+	t, ok := exprOperators[op][kind]
+	if !ok {
+		return "", "", nil, p.errorf(opTok, "operator %s can not be used with %s", op, key)
+	}
+	return t, key, values, nil
+}
+
+// values parses a single value or a bracketed list of values, and reports
+// whether any of the values was quoted
+func (p *exprParser) values() ([]string, bool, error) {
+	tok := p.next()
+	if tok.kind == tokenWord {
+		return []string{tok.text}, tok.quoted, nil
+	}
+	if tok.kind != tokenOpen {
+		return nil, false, p.errorf(tok, "expected value, got %q", tok.text)
+	}
+	values := make([]string, 0)
+	quoted := false
+	for {
+		tok = p.next()
+		if tok.kind != tokenWord {
+			return nil, false, p.errorf(tok, "expected value, got %q", tok.text)
+		}
+		values = append(values, tok.text)
+		quoted = quoted || tok.quoted
+		tok = p.next()
+		if tok.kind == tokenClose {
+			return values, quoted, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, false, p.errorf(tok, "expected , or ], got %q", tok.text)
+		}
+	}
+}
+
+// inferKind guesses the kind of values compared by a clause on an unknown key
+func inferKind(values []string, quoted bool) valueKind {
+	if quoted {
+		return kindString
+	}
+	for _, kind := range []valueKind{kindBool, kindNumeric, kindIp, kindArn, kindDate} {
+		match := true
+		for _, value := range values {
+			if !isKind(kind, value) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return kind
+		}
+	}
+	return kindString
+}
+
+func isKind(kind valueKind, value string) bool {
+	switch kind {
+	case kindBool:
+		_, err := strconv.ParseBool(value)
+		return err == nil && (strings.EqualFold(value, "true") || strings.EqualFold(value, "false"))
+	case kindNumeric:
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case kindIp:
+		if net.ParseIP(value) != nil {
+			return true
+		}
+		_, _, err := net.ParseCIDR(value)
+		return err == nil
+	case kindArn:
+		return strings.HasPrefix(value, "arn:")
+	case kindDate:
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func assertCondition(t *testing.T, c map[ConditionType]map[ConditionVariable][]string, expected string) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(data) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, string(data))
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{
+			`aws:SourceIp in [10.0.0.0/8] AND aws:SecureTransport = true`,
+			`{"Bool":{"aws:SecureTransport":["true"]},"IpAddress":{"aws:SourceIp":["10.0.0.0/8"]}}`,
+		},
+		{
+			`aws:username != "bob" and aws:username not in [alice, "carol"]`,
+			`{"StringNotEquals":{"aws:username":["bob","alice","carol"]}}`,
+		},
+		{
+			`aws:MultiFactorAuthAge<3600 AND aws:CurrentTime >= 2013-01-01T00:00:00Z`,
+			`{"DateGreaterThanEquals":{"aws:CurrentTime":["2013-01-01T00:00:00Z"]},"NumericLessThan":{"aws:MultiFactorAuthAge":["3600"]}}`,
+		},
+		{
+			`aws:SourceArn like arn:aws:sns:*:123456789012:* AND s3:prefix not like "home/*"`,
+			`{"ArnLike":{"aws:SourceArn":["arn:aws:sns:*:123456789012:*"]},"StringNotLike":{"s3:prefix":["home/*"]}}`,
+		},
+		{
+			`aws:TokenIssueTime exists AND aws:PrincipalTag/team not exists`,
+			`{"Null":{"aws:PrincipalTag/team":["true"],"aws:TokenIssueTime":["false"]}}`,
+		},
+		{
+			`aws:TagKeys ForAllValues:StringEquals [team, env] AND aws:userid StringEqualsIgnoreCase ABC`,
+			`{"ForAllValues:StringEquals":{"aws:TagKeys":["team","env"]},"StringEqualsIgnoreCase":{"aws:userid":["ABC"]}}`,
+		},
+		{
+			`ec2:InstanceCount > 2 AND aws:RequestedRegion = eu-west-1`,
+			`{"NumericGreaterThan":{"ec2:InstanceCount":["2"]},"StringEquals":{"aws:RequestedRegion":["eu-west-1"]}}`,
+		},
+		{
+			`ec2:Encrypted = true AND ec2:VolumeSize <= 100 AND s3:prefix = 100`,
+			`{"Bool":{"ec2:Encrypted":["true"]},"NumericLessThanEquals":{"ec2:VolumeSize":["100"]},"StringEquals":{"s3:prefix":["100"]}}`,
+		},
+		{
+			`aws:MultiFactorAuthPresent = true AND aws:PrincipalArn like arn:aws:iam::*:role/admin AND aws:TokenIssueTime > 2020-01-01T00:00:00Z`,
+			`{"ArnLike":{"aws:PrincipalArn":["arn:aws:iam::*:role/admin"]},"Bool":{"aws:MultiFactorAuthPresent":["true"]},"DateGreaterThan":{"aws:TokenIssueTime":["2020-01-01T00:00:00Z"]}}`,
+		},
+		{
+			`aws:SourceAccount = 111122223333 AND aws:PrincipalAccount in [111122223333, 444455556666]`,
+			`{"StringEquals":{"aws:PrincipalAccount":["111122223333","444455556666"],"aws:SourceAccount":["111122223333"]}}`,
+		},
+		{
+			`aws:ResourceTag/CostCenter = 0042 AND s3:ExistingObjectTag/level != 1`,
+			`{"StringEquals":{"aws:ResourceTag/CostCenter":["0042"]},"StringNotEquals":{"s3:ExistingObjectTag/level":["1"]}}`,
+		},
+		{
+			`custom:limit = 0042`,
+			`{"NumericEquals":{"custom:limit":["0042"]}}`,
+		},
+	}
+
+	for _, test := range tests {
+		c, err := ParseCondition(test.expr)
+		if err != nil {
+			t.Errorf("Failed parsing %s: %s", test.expr, err)
+			continue
+		}
+		assertCondition(t, c, test.expected)
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{``, 0},
+		{`aws:username`, 12},
+		{`aws:username = `, 15},
+		{`aws:username = bob OR aws:username = alice`, 19},
+		{`aws:username like [a, b`, 23},
+		{`aws:SecureTransport < true`, 20},
+		{`aws:username = "bob`, 15},
+		{`aws:username == bob`, 13},
+		{`aws:username = bob AND aws:SecureTransport = true AND aws:username = alice`, 54},
+		{`aws:SourceIp in [10.0.0.0/8] AND aws:SourceIp = 192.168.0.1`, 33},
+	}
+
+	for _, test := range tests {
+		_, err := ParseCondition(test.expr)
+		exprErr, ok := err.(*ConditionExprError)
+		if !ok {
+			t.Errorf("Expected ConditionExprError for %s got %v", test.expr, err)
+			continue
+		}
+		if exprErr.Pos != test.pos {
+			t.Errorf("Expected error at %d for %s got %s", test.pos, test.expr, exprErr)
+		}
+	}
+}
+
+func TestFormatCondition(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.AddCondition(ConditionIpAddress, VarSourceIp, "10.0.0.0/8")
+	stmt.AddCondition(ConditionIpAddress, VarSourceIp, "192.168.0.0/16")
+	stmt.AddCondition(ConditionBool, VarSecureTransport, "true")
+	stmt.AddCondition(ConditionStringEquals, "aws:PrincipalTag/admin", "true")
+	stmt.AddCondition(ConditionNull, "aws:TokenIssueTime", "false")
+	stmt.AddCondition(ConditionStringEqualsIgnoreCase, VarUsername, "Bob Smith")
+	stmt.AddSetCondition(ForAnyValue, ConditionStringLike, "aws:TagKeys", "team*")
+
+	expected := `aws:SecureTransport = true AND ` +
+		`aws:TagKeys ForAnyValue:StringLike team* AND ` +
+		`aws:SourceIp in [10.0.0.0/8, 192.168.0.0/16] AND ` +
+		`aws:TokenIssueTime exists AND ` +
+		`aws:PrincipalTag/admin = true AND ` +
+		`aws:username StringEqualsIgnoreCase "Bob Smith"`
+	got := FormatCondition(stmt.Condition)
+	if got != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+
+	c, err := ParseCondition(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if FormatCondition(c) != got {
+		t.Errorf("Condition did not round trip, got %s", FormatCondition(c))
+	}
+}
+
+func TestAddConditionExpr(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.AddCondition(ConditionIpAddress, VarSourceIp, "10.0.0.0/8")
+	if err := stmt.AddConditionExpr(`aws:SourceIp = 192.168.0.0/16`); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Condition":{"IpAddress":{"aws:SourceIp":["10.0.0.0/8","192.168.0.0/16"]}}}]}`
+
+	assertPolicy(t, p, expected)
+
+	if err := stmt.AddConditionExpr(`aws:SourceIp`); err == nil {
+		t.Error("Expected error for invalid expression")
+	}
+}
@@ -0,0 +1,84 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Guardrail generation error when the options can not produce a valid
+// statement
+type GuardrailError string
+
+func (s GuardrailError) Error() string {
+	return fmt.Sprintf("Invalid guardrail: %s", string(s))
+}
+
+// GuardrailOptions describes a guardrail, a Deny statement for a list of
+// forbidden actions
+type GuardrailOptions struct {
+	Sid string
+	// Action patterns to deny, e.g. organizations:LeaveOrganization or
+	// iam:Delete*
+	Actions []string
+	// Principals exempt from the guardrail, as principal ARNs, which may
+	// contain wildcards, or 12 digit account ids
+	Exempt []string
+	// Create a resource statement, with the Principal "*", instead of an
+	// identity statement
+	ResourcePolicy bool
+}
+
+var accountIdPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+// NewGuardrail creates the Deny statement for a guardrail. Exemptions are
+// expressed as conditions on aws:PrincipalArn and aws:PrincipalAccount rather
+// than with NotPrincipal, which AWS recommends against: NotPrincipal does not
+// match assumed role sessions of an exempt role. ArnNotEquals is used for
+// principal ARNs unless one of them contains a wildcard.
+func NewGuardrail(opts GuardrailOptions) (*Statement, error) {
+	if len(opts.Actions) == 0 {
+		return nil, GuardrailError("no actions to deny")
+	}
+	stmt := NewStatement(WithEffect(Deny), WithActions(opts.Actions...), WithResources("*"))
+	if opts.Sid != "" {
+		stmt.SetSid(opts.Sid)
+	}
+	if opts.ResourcePolicy {
+		stmt.Kind = ResourceStatement
+		stmt.AddPrincipal("*")
+	}
+
+	arns := make([]string, 0)
+	accounts := make([]string, 0)
+	for _, exempt := range opts.Exempt {
+		switch {
+		case accountIdPattern.MatchString(exempt):
+			accounts = append(accounts, exempt)
+		case strings.HasPrefix(exempt, "arn:"):
+			arns = append(arns, exempt)
+		default:
+			return nil, GuardrailError(fmt.Sprintf("exempt principal %s is neither an ARN nor an account id", exempt))
+		}
+	}
+	if len(arns) > 0 {
+		op := ConditionArnNotEquals
+		for _, arn := range arns {
+			if strings.ContainsAny(arn, "*?") {
+				op = ConditionArnNotLike
+			}
+		}
+		stmt.AddConditionValues(op, VarPrincipalArn, arns...)
+	}
+	if len(accounts) > 0 {
+		stmt.AddConditionValues(ConditionStringNotEquals, VarPrincipalAccount, accounts...)
+	}
+	return stmt, nil
+}
@@ -0,0 +1,64 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestNewGuardrail(t *testing.T) {
+	tests := []struct {
+		opts     GuardrailOptions
+		expected string
+	}{
+		{
+			GuardrailOptions{Actions: []string{"organizations:LeaveOrganization"}},
+			`{"Effect":"Deny","Action":["organizations:LeaveOrganization"],"Resource":["*"]}`,
+		},
+		{
+			GuardrailOptions{
+				Sid:     "DenyIAMChanges",
+				Actions: []string{"iam:Create*", "iam:Delete*"},
+				Exempt:  []string{"arn:aws:iam::111122223333:role/admin", "111122223333"},
+			},
+			`{"Sid":"DenyIAMChanges","Effect":"Deny","Action":["iam:Create*","iam:Delete*"],"Resource":["*"],"Condition":{"ArnNotEquals":{"aws:PrincipalArn":["arn:aws:iam::111122223333:role/admin"]},"StringNotEquals":{"aws:PrincipalAccount":["111122223333"]}}}`,
+		},
+		{
+			GuardrailOptions{
+				Actions:        []string{"s3:DeleteBucket"},
+				Exempt:         []string{"arn:aws:iam::111122223333:role/break-glass-*"},
+				ResourcePolicy: true,
+			},
+			`{"Effect":"Deny","Principal":{"AWS":["*"]},"Action":["s3:DeleteBucket"],"Resource":["*"],"Condition":{"ArnNotLike":{"aws:PrincipalArn":["arn:aws:iam::111122223333:role/break-glass-*"]}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		stmt, err := NewGuardrail(test.opts)
+		if err != nil {
+			t.Fatalf("Failed creating guardrail: %s", err)
+		}
+		if err := stmt.Validate(); err != nil {
+			t.Errorf("Failed validating guardrail: %s", err)
+		}
+		data, err := stmt.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("Expected \n%s got \n%s", test.expected, data)
+		}
+	}
+
+	if _, err := NewGuardrail(GuardrailOptions{}); err == nil {
+		t.Errorf("Expected GuardrailError for missing actions")
+	}
+	if _, err := NewGuardrail(GuardrailOptions{Actions: []string{"*"}, Exempt: []string{"admin"}}); err == nil {
+		t.Errorf("Expected GuardrailError for invalid exempt principal")
+	}
+}
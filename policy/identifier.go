@@ -0,0 +1,144 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Identifier parsing error when a string is not a valid policy identifier
+type InvalidIdentifierError string
+
+func (s InvalidIdentifierError) Error() string {
+	return fmt.Sprintf("Invalid Policy Identifier %s", string(s))
+}
+
+// IdentifierKind is the source a policy identified by an Identifier comes from
+type IdentifierKind string
+
+const (
+	// A managed policy, Target is the policy ARN and Name the optional
+	// version id
+	IdentifierManaged IdentifierKind = "managed"
+	// An inline policy, Target is the ARN of the user, group or role and Name
+	// the policy name
+	IdentifierInline IdentifierKind = "inline"
+	// A resource-based policy, Target is the ARN of the resource
+	IdentifierResource IdentifierKind = "resource"
+	// A service control policy, Target is the policy id
+	IdentifierSCP IdentifierKind = "scp"
+	// A policy document read from a local file, Target is the path
+	IdentifierFile IdentifierKind = "file"
+)
+
+// Identifier references a policy from any source unambiguously. Its string
+// form is "kind:target" or "kind:target#name", e.g.
+//
+//	managed:arn:aws:iam::aws:policy/ReadOnlyAccess#v3
+//	inline:arn:aws:iam::123456789012:role/app#s3-access
+//	resource:arn:aws:s3:::bucket
+//	scp:p-examplepolicyid111
+//	file:policies/app.json
+type Identifier struct {
+	Kind   IdentifierKind
+	Target string
+	Name   string
+}
+
+// Identify a managed policy, the version may be empty
+func ManagedPolicyIdentifier(arn, version string) Identifier {
+	return Identifier{IdentifierManaged, arn, version}
+}
+
+// Identify an inline policy of a user, group or role
+func InlinePolicyIdentifier(principalArn, name string) Identifier {
+	return Identifier{IdentifierInline, principalArn, name}
+}
+
+// Identify the resource-based policy of a resource
+func ResourcePolicyIdentifier(resourceArn string) Identifier {
+	return Identifier{IdentifierResource, resourceArn, ""}
+}
+
+// Identify a service control policy
+func ServiceControlPolicyIdentifier(id string) Identifier {
+	return Identifier{IdentifierSCP, id, ""}
+}
+
+// Identify a policy document stored in a local file
+func FilePolicyIdentifier(path string) Identifier {
+	return Identifier{IdentifierFile, path, ""}
+}
+
+// String returns the string form of the identifier
+func (i Identifier) String() string {
+	if i.Name == "" {
+		return string(i.Kind) + ":" + i.Target
+	}
+	return string(i.Kind) + ":" + i.Target + "#" + i.Name
+}
+
+// ParseIdentifier parses the string form of an identifier
+func ParseIdentifier(s string) (Identifier, error) {
+	sep := strings.Index(s, ":")
+	if sep < 0 {
+		return Identifier{}, InvalidIdentifierError(s)
+	}
+	i := Identifier{Kind: IdentifierKind(s[:sep]), Target: s[sep+1:]}
+	switch i.Kind {
+	case IdentifierManaged, IdentifierInline:
+		if hash := strings.LastIndex(i.Target, "#"); hash >= 0 {
+			i.Target, i.Name = i.Target[:hash], i.Target[hash+1:]
+		}
+	case IdentifierResource, IdentifierSCP, IdentifierFile:
+	default:
+		return Identifier{}, InvalidIdentifierError(s)
+	}
+	if err := i.Validate(); err != nil {
+		return Identifier{}, InvalidIdentifierError(s)
+	}
+	return i, nil
+}
+
+// Validate checks that the identifier is complete for its kind
+func (i Identifier) Validate() error {
+	valid := i.Target != ""
+	switch i.Kind {
+	case IdentifierManaged:
+		valid = valid && strings.HasPrefix(i.Target, "arn:") && strings.Contains(i.Target, ":policy/")
+	case IdentifierInline:
+		valid = valid && strings.HasPrefix(i.Target, "arn:") && i.Name != ""
+	case IdentifierResource:
+		valid = valid && strings.HasPrefix(i.Target, "arn:")
+	case IdentifierSCP:
+		valid = valid && strings.HasPrefix(i.Target, "p-")
+	case IdentifierFile:
+	default:
+		valid = false
+	}
+	if !valid {
+		return InvalidIdentifierError(i.String())
+	}
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (i Identifier) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *Identifier) UnmarshalText(b []byte) error {
+	parsed, err := ParseIdentifier(string(b))
+	if err != nil {
+		return err
+	}
+	*i = parsed
+	return nil
+}
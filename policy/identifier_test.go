@@ -0,0 +1,80 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIdentifierString(t *testing.T) {
+	tests := []struct {
+		id       Identifier
+		expected string
+	}{
+		{ManagedPolicyIdentifier("arn:aws:iam::aws:policy/ReadOnlyAccess", "v3"), "managed:arn:aws:iam::aws:policy/ReadOnlyAccess#v3"},
+		{ManagedPolicyIdentifier("arn:aws:iam::aws:policy/ReadOnlyAccess", ""), "managed:arn:aws:iam::aws:policy/ReadOnlyAccess"},
+		{InlinePolicyIdentifier("arn:aws:iam::123456789012:role/app", "s3-access"), "inline:arn:aws:iam::123456789012:role/app#s3-access"},
+		{ResourcePolicyIdentifier("arn:aws:s3:::bucket"), "resource:arn:aws:s3:::bucket"},
+		{ServiceControlPolicyIdentifier("p-examplepolicyid111"), "scp:p-examplepolicyid111"},
+		{FilePolicyIdentifier("policies/app#1.json"), "file:policies/app#1.json"},
+	}
+
+	for _, test := range tests {
+		if test.id.String() != test.expected {
+			t.Errorf("Expected %s got %s", test.expected, test.id.String())
+		}
+		parsed, err := ParseIdentifier(test.expected)
+		if err != nil {
+			t.Errorf("Failed parsing %s: %s", test.expected, err)
+			continue
+		}
+		if parsed != test.id {
+			t.Errorf("Expected %#v got %#v", test.id, parsed)
+		}
+	}
+}
+
+func TestParseIdentifierErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"arn:aws:s3:::bucket",
+		"bucket:arn:aws:s3:::bucket",
+		"managed:arn:aws:iam::123456789012:role/app",
+		"inline:arn:aws:iam::123456789012:role/app",
+		"resource:bucket",
+		"scp:FullAWSAccess",
+		"file:",
+	}
+
+	for _, s := range invalid {
+		if _, err := ParseIdentifier(s); err == nil {
+			t.Errorf("Expected error parsing %s", s)
+		}
+	}
+}
+
+func TestIdentifierJSON(t *testing.T) {
+	ids := map[Identifier]int{ResourcePolicyIdentifier("arn:aws:s3:::bucket"): 1}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"resource:arn:aws:s3:::bucket":1}`
+	if string(data) != expected {
+		t.Errorf("Expected %s got %s", expected, string(data))
+	}
+
+	var loaded map[Identifier]int
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded[ResourcePolicyIdentifier("arn:aws:s3:::bucket")] != 1 {
+		t.Errorf("Expected identifier to round trip got %v", loaded)
+	}
+}
@@ -0,0 +1,35 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"iter"
+)
+
+// Statements returns an iterator over the statements of the policy
+func (p *Policy) Statements() iter.Seq[*Statement] {
+	return func(yield func(*Statement) bool) {
+		for _, stmt := range p.Statement {
+			if !yield(stmt) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns the statements of the policy for which keep returns true, in
+// policy order
+func (p *Policy) Filter(keep func(*Statement) bool) []*Statement {
+	result := make([]*Statement, 0)
+	for stmt := range p.Statements() {
+		if keep(stmt) {
+			result = append(result, stmt)
+		}
+	}
+	return result
+}
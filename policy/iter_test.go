@@ -0,0 +1,47 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatements(t *testing.T) {
+	p := Build().
+		Allow().Sid("a").Actions("s3:GetObject").
+		Deny().Sid("b").Actions("s3:*").
+		Allow().Sid("c").Actions("ec2:DescribeInstances").
+		Done()
+
+	sids := make([]string, 0)
+	for stmt := range p.Statements() {
+		sids = append(sids, *stmt.Sid)
+		if *stmt.Sid == "b" {
+			break
+		}
+	}
+	if strings.Join(sids, ",") != "a,b" {
+		t.Errorf("Expected a,b got %s", strings.Join(sids, ","))
+	}
+
+	got := p.Filter(func(s *Statement) bool {
+		if s.Effect != Allow {
+			return false
+		}
+		for _, action := range s.Action {
+			if strings.HasPrefix(action, "s3:") {
+				return true
+			}
+		}
+		return false
+	})
+	if len(got) != 1 || got[0] != p.Statement[0] {
+		t.Errorf("Expected only statement a got %v", got)
+	}
+}
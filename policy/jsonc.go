@@ -0,0 +1,77 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+// Create a policy from JSON that may contain // and /* */ comments and
+// trailing commas, as commonly used for policies kept in source control
+func LoadPolicyJSONC(b []byte) (*Policy, error) {
+	return LoadPolicyWithOptions(b, ParseOptions{JSONC: true})
+}
+
+// stripJSONC returns a copy of the document with comments and trailing commas
+// replaced by spaces. Newlines are kept, so offsets in the result match the
+// original document.
+func stripJSONC(b []byte) []byte {
+	out := make([]byte, len(b))
+	copy(out, b)
+
+	// Remove comments
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"':
+			i = skipString(out, i)
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+
+	// Remove trailing commas
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '"':
+			i = skipString(out, i)
+		case ',':
+			j := i + 1
+			for j < len(out) && (out[j] == ' ' || out[j] == '\t' || out[j] == '\r' || out[j] == '\n') {
+				j++
+			}
+			if j < len(out) && (out[j] == ']' || out[j] == '}') {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// skipString returns the index of the closing quote of the string starting at
+// index i
+func skipString(b []byte, i int) int {
+	for i++; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return i
+}
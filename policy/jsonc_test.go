@@ -0,0 +1,54 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadPolicyJSONC(t *testing.T) {
+	data := []byte(`{
+    // Allow reading the assets bucket
+    "Version": "2012-10-17",
+    "Statement": [
+        {
+            "Effect": "Allow", /* everyone */
+            "Principal": {"AWS": ["*"]},
+            "Action": [
+                "s3:GetObject",
+            ],
+            "Resource": ["arn:aws:s3:::assets//*", "arn:aws:s3:::a,]/*",],
+        },
+    ],
+}`)
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::assets//*","arn:aws:s3:::a,]/*"]}]}`
+
+	p, err := LoadPolicyJSONC(data)
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	assertPolicy(t, p, expected)
+
+	if _, err := LoadPolicy(data); err == nil {
+		t.Error("Expected error loading JSONC without JSONC option")
+	}
+}
+
+func TestLoadPolicyJSONCOffsets(t *testing.T) {
+	data := []byte(`{"Version": /* v */ "2012-10-17", "Statement": [{"Effect": "Maybe"}]}`)
+
+	_, err := LoadPolicyJSONC(data)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError got %v", err)
+	}
+	if parseErr.Offset != 59 {
+		t.Errorf("Expected error at offset 59 got %d", parseErr.Offset)
+	}
+}
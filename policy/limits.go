@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PolicyKind is the way a policy document is attached, which determines the
+// limits AWS applies to it
+type PolicyKind int
+
+const (
+	ManagedPolicy PolicyKind = iota
+	UserInlinePolicy
+	RoleInlinePolicy
+	GroupInlinePolicy
+	ServiceControlPolicy
+	KeyPolicy
+	BoundaryPolicy
+	InlineSessionPolicy
+)
+
+var policyKindNames = map[PolicyKind]string{
+	ManagedPolicy:        "managed",
+	UserInlinePolicy:     "user inline",
+	RoleInlinePolicy:     "role inline",
+	GroupInlinePolicy:    "group inline",
+	ServiceControlPolicy: "service control",
+	KeyPolicy:            "KMS key",
+	BoundaryPolicy:       "permissions boundary",
+	InlineSessionPolicy:  "inline session",
+}
+
+var policySizeLimits = map[PolicyKind]int{
+	ManagedPolicy:        6144,
+	UserInlinePolicy:     2048,
+	RoleInlinePolicy:     10240,
+	GroupInlinePolicy:    5120,
+	ServiceControlPolicy: 5120,
+	KeyPolicy:            32768,
+	BoundaryPolicy:       6144,
+	InlineSessionPolicy:  2048,
+}
+
+func (k PolicyKind) String() string {
+	if name, ok := policyKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("PolicyKind(%d)", int(k))
+}
+
+// SizeLimit returns the maximum number of non-whitespace characters AWS
+// accepts for a policy of this kind. For inline policies the limit applies to
+// all inline policies of the user, role or group combined.
+func (k PolicyKind) SizeLimit() int {
+	return policySizeLimits[k]
+}
+
+// PolicySizeError is returned when a policy exceeds the size limit for its kind
+type PolicySizeError struct {
+	Kind  PolicyKind
+	Size  int
+	Limit int
+}
+
+func (e *PolicySizeError) Error() string {
+	return fmt.Sprintf("Policy size of %d characters exceeds the %s policy limit of %d by %d", e.Size, e.Kind, e.Limit, e.Size-e.Limit)
+}
+
+// Size returns the number of characters of the policy that count towards the
+// AWS size limits, which excludes whitespace
+func (p *Policy) Size() (int, error) {
+	data, err := p.Get()
+	if err != nil {
+		return 0, err
+	}
+	return documentSize(data), nil
+}
+
+// Retrieve the policy as a JSON encoded string like Get, returns a
+// PolicySizeError if the policy is too large for the given kind
+func (p *Policy) GetWithLimits(kind PolicyKind) ([]byte, error) {
+	data, err := p.Get()
+	if err != nil {
+		return nil, err
+	}
+	if size, limit := documentSize(data), kind.SizeLimit(); size > limit {
+		return nil, &PolicySizeError{Kind: kind, Size: size, Limit: limit}
+	}
+	return data, nil
+}
+
+func documentSize(data []byte) int {
+	size := 0
+	for len(data) > 0 {
+		r, n := utf8.DecodeRune(data)
+		if !unicode.IsSpace(r) {
+			size++
+		}
+		data = data[n:]
+	}
+	return size
+}
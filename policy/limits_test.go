@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPolicySize(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddIdentityStatement()
+	stmt.SetSid("with spaces")
+	stmt.AddAction("s3:GetObject")
+
+	// {"Version":"2012-10-17","Statement":[{"Sid":"withspaces","Effect":"Deny","Action":["s3:GetObject"]}]}
+	size, err := p.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 101 {
+		t.Errorf("Expected size 101 got %d", size)
+	}
+}
+
+func TestGetWithLimits(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddIdentityStatement()
+	stmt.Effect = Allow
+	for i := 0; i < 200; i++ {
+		stmt.AddAction(fmt.Sprintf("s3:Action%d", i))
+	}
+
+	if _, err := p.GetWithLimits(RoleInlinePolicy); err != nil {
+		t.Errorf("Failed getting policy: %s", err)
+	}
+
+	_, err := p.GetWithLimits(UserInlinePolicy)
+	sizeErr, ok := err.(*PolicySizeError)
+	if !ok {
+		t.Fatalf("Expected PolicySizeError got %v", err)
+	}
+	size, _ := p.Size()
+	if sizeErr.Size != size || sizeErr.Limit != 2048 || sizeErr.Kind != UserInlinePolicy {
+		t.Errorf("Unexpected error %s", sizeErr)
+	}
+}
@@ -0,0 +1,207 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Severity ranks a Finding, from purely informational to fatal
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// FindingCode identifies the check that produced a Finding. Codes are stable
+// and can be used to suppress or escalate specific findings.
+type FindingCode string
+
+const (
+	// The statement is not valid for its kind, see Statement.Validate
+	CodeInvalidStatement FindingCode = "invalid-statement"
+	// The statement has neither Action nor NotAction
+	CodeMissingAction FindingCode = "missing-action"
+	// The statement has neither Resource nor NotResource in an identity policy
+	CodeMissingResource FindingCode = "missing-resource"
+	// An Allow statement grants all actions
+	CodeWildcardAction FindingCode = "wildcard-action"
+	// An Allow statement grants all actions of a service, e.g. s3:*
+	CodeServiceWildcardAction FindingCode = "service-wildcard-action"
+	// An Allow statement applies to all resources
+	CodeWildcardResource FindingCode = "wildcard-resource"
+	// An Allow statement applies to everyone without any condition
+	CodeWildcardPrincipal FindingCode = "wildcard-principal"
+	// An Allow statement uses NotAction, granting every action not listed
+	CodeAllowNotAction FindingCode = "allow-not-action"
+	// An Allow statement uses NotResource, granting every resource not listed
+	CodeAllowNotResource FindingCode = "allow-not-resource"
+	// A condition uses a service specific key of a service none of the
+	// statement's actions belong to, so the key is never present
+	CodeDeadConditionKey FindingCode = "dead-condition-key"
+	// Conditions on the same key can never be satisfied together
+	CodeContradictoryCondition FindingCode = "contradictory-condition"
+)
+
+// Finding is a single result of linting a policy
+type Finding struct {
+	Code     FindingCode
+	Severity Severity
+	// Index of the statement the finding applies to
+	Statement int
+	Message   string
+	// The offending statement with the issue fixed, as a JSON encoded
+	// statement. Placeholders in angle brackets, e.g. <account>, need to be
+	// filled in.
+	Remediation string
+	// Link to the AWS documentation on the issue
+	DocURL string
+
+	// Message format and arguments, used to localize the message
+	format string
+	args   []interface{}
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("Statement[%d]: %s: %s (%s)", f.Statement, f.Severity, f.Message, f.Code)
+}
+
+// Findings is the result of linting a policy
+type Findings []Finding
+
+// AtLeast returns the findings with the given severity or higher
+func (f Findings) AtLeast(s Severity) Findings {
+	result := make(Findings, 0)
+	for _, finding := range f {
+		if finding.Severity >= s {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// Errors returns the findings that make the policy unusable
+func (f Findings) Errors() Findings {
+	return f.AtLeast(SeverityError)
+}
+
+// Warnings returns the advisory findings, excluding errors
+func (f Findings) Warnings() Findings {
+	result := make(Findings, 0)
+	for _, finding := range f {
+		if finding.Severity == SeverityWarning {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// Lint checks the policy for errors and advisory issues such as over-broad
+// wildcards. Unlike Validate it reports all problems found, leaving it to the
+// caller which severity to fail on.
+func (p *Policy) Lint() Findings {
+	result := make(Findings, 0)
+	for i, stmt := range p.Statement {
+		for _, finding := range stmt.lint() {
+			finding.Statement = i
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+func (s *Statement) lint() Findings {
+	result := make(Findings, 0)
+	add := func(code FindingCode, severity Severity, fix func(*Statement), format string, args ...interface{}) {
+		result = append(result, s.finding(code, severity, fix, format, args...))
+	}
+
+	var errs ValidationErrors
+	errors.As(s.Validate(), &errs)
+	for _, err := range errs {
+		// Reported with their own codes below
+		if err == errMissingAction || err == errMissingResource {
+			continue
+		}
+		format, args := validationMessage(err)
+		f := s.finding(CodeInvalidStatement, SeverityError, validationFix(err), format, args...)
+		f.DocURL = validationDoc(err)
+		result = append(result, f)
+	}
+	if len(s.Action) == 0 && len(s.NotAction) == 0 {
+		add(CodeMissingAction, SeverityError, func(c *Statement) {
+			c.AddAction(placeholderAction)
+		}, "statement has no Action or NotAction")
+	}
+	if s.Kind != ResourceStatement && len(s.ResourceList()) == 0 && len(s.NotResource) == 0 {
+		add(CodeMissingResource, SeverityError, func(c *Statement) {
+			c.AddResource(placeholderResource)
+		}, "statement has no Resource or NotResource")
+	}
+	result = append(result, s.lintConditions()...)
+	if s.Effect != Allow {
+		return result
+	}
+
+	for i, action := range s.Action {
+		if action == "*" {
+			add(CodeWildcardAction, SeverityWarning, func(c *Statement) {
+				c.Action[i] = placeholderAction
+			}, "statement allows all actions")
+		} else if service, ok := strings.CutSuffix(action, ":*"); ok {
+			add(CodeServiceWildcardAction, SeverityInfo, func(c *Statement) {
+				c.Action[i] = service + ":<action>"
+			}, "statement allows all %s actions", service)
+		}
+	}
+	if s.Resource == "*" {
+		add(CodeWildcardResource, SeverityInfo, func(c *Statement) {
+			c.Resource = placeholderResource
+		}, "statement applies to all resources")
+	} else if i := index(s.Resources, "*"); i >= 0 {
+		add(CodeWildcardResource, SeverityInfo, func(c *Statement) {
+			c.Resources[i] = placeholderResource
+		}, "statement applies to all resources")
+	}
+	if s.Principal != nil && contains(s.Principal.Aws, "*") && len(s.Condition) == 0 {
+		add(CodeWildcardPrincipal, SeverityWarning, func(c *Statement) {
+			c.AddCondition(ConditionStringEquals, VarPrincipalOrgID, "<organization-id>")
+		}, "statement allows everyone without a condition")
+	}
+	if len(s.NotAction) > 0 {
+		add(CodeAllowNotAction, SeverityWarning, func(c *Statement) {
+			c.NotAction = nil
+			c.Action = []string{placeholderAction}
+		}, "statement allows all actions except those listed in NotAction")
+	}
+	if len(s.NotResource) > 0 {
+		add(CodeAllowNotResource, SeverityWarning, func(c *Statement) {
+			c.NotResource = nil
+			c.Resource = ""
+			c.Resources = []string{placeholderResource}
+		}, "statement allows all resources except those listed in NotResource")
+	}
+	return result
+}
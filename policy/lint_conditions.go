@@ -0,0 +1,205 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gwkunze/goiam/conditionkeys"
+)
+
+// Operators that require the key to equal one of the values, and the
+// operators negating them
+var negatedOperators = [][2]ConditionType{
+	{ConditionStringEquals, ConditionStringNotEquals},
+	{ConditionStringEqualsIgnoreCase, ConditionStringNotEqualsIgnoreCase},
+	{ConditionNumericEquals, ConditionNumericNotEquals},
+	{ConditionDateEquals, ConditionDateNotEquals},
+	{ConditionArnEquals, ConditionArnNotEquals},
+}
+
+// lintConditions looks for conditions that can never be satisfied: keys that
+// are never present for the statement's actions and contradicting conditions
+// on the same key. Conditions with a set operator or IfExists suffix are
+// ignored, they are satisfied when the key is absent.
+//
+// The findings have no remediation, removing the condition would grant more
+// than the author intended.
+func (s *Statement) lintConditions() Findings {
+	result := make(Findings, 0)
+	add := func(code FindingCode, format string, args ...interface{}) {
+		result = append(result, s.finding(code, SeverityWarning, nil, format, args...))
+	}
+
+	// Operators and their values by key, for operators that fail when the
+	// key is absent
+	keys := make(map[string]map[ConditionType][]string)
+	// Keys as written in the policy by lower case name
+	written := make(map[string]string)
+	for t, vars := range s.Condition {
+		if t.SetOperator() != "" || t.IsIfExists() {
+			continue
+		}
+		for key, values := range vars {
+			name := strings.ToLower(string(key))
+			if keys[name] == nil {
+				keys[name] = make(map[ConditionType][]string)
+			}
+			if w, ok := written[name]; !ok || string(key) < w {
+				written[name] = string(key)
+			}
+			keys[name][t] = values
+		}
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ops := keys[name]
+		key := written[name]
+		if _, isNull := ops[ConditionNull]; !isNull && !s.keyPresent(key) {
+			add(CodeDeadConditionKey, "condition key %s is never present for the actions of the statement", key)
+		}
+		if null, ok := ops[ConditionNull]; ok && len(null) == 1 && strings.EqualFold(null[0], "true") && len(ops) > 1 {
+			add(CodeContradictoryCondition, "condition key %s must be absent but is also compared", key)
+		}
+		for _, pair := range negatedOperators {
+			if subset(ops[pair[0]], ops[pair[1]], pair[0] == ConditionStringEqualsIgnoreCase) {
+				add(CodeContradictoryCondition, "condition key %s must equal and not equal the same values", key)
+			}
+		}
+		if emptyRange(ops) {
+			add(CodeContradictoryCondition, "condition key %s has an empty range", key)
+		}
+	}
+	return result
+}
+
+// Service specific keys present in requests to any service, set from the
+// credentials of an instance or function role
+var crossServiceKeys = []string{
+	conditionkeys.EC2RoleDelivery,
+	conditionkeys.EC2SourceInstanceARN,
+	conditionkeys.LambdaSourceFunctionArn,
+}
+
+// keyPresent reports whether a condition key can be present in a request for
+// one of the statement's actions. Only keys of the services in the
+// conditionkeys catalog are checked, e.g. s3:prefix is only present for
+// actions of s3. Global keys, keys of identity providers such as SAML:aud and
+// unknown keys may be present in any request.
+func (s *Statement) keyPresent(key string) bool {
+	service, _, ok := strings.Cut(strings.ToLower(key), ":")
+	if !ok || len(s.Action) == 0 || len(conditionkeys.Keys(service)) == 0 {
+		return true
+	}
+	for _, k := range crossServiceKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	for _, action := range s.Action {
+		prefix, _, _ := strings.Cut(strings.ToLower(action), ":")
+		if WildcardMatch(prefix, service) {
+			return true
+		}
+	}
+	return false
+}
+
+// subset reports whether every value of a is in b
+func subset(a, b []string, ignoreCase bool) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	for _, v := range a {
+		found := false
+		for _, w := range b {
+			if v == w || (ignoreCase && strings.EqualFold(v, w)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// bound is one end of the range of values allowed by a condition
+type bound struct {
+	value     float64
+	inclusive bool
+	set       bool
+}
+
+// widen extends the bound to include v, upper tells which end it is
+func (b *bound) widen(v float64, inclusive, upper bool) {
+	if !b.set || (upper && v > b.value) || (!upper && v < b.value) {
+		*b = bound{v, inclusive, true}
+	} else if v == b.value && inclusive {
+		b.inclusive = true
+	}
+}
+
+// emptyRange reports whether the less than and greater than conditions on a
+// key exclude every numeric or date value. With multiple values a condition
+// matches any of them, so the widest bound is used.
+func emptyRange(ops map[ConditionType][]string) bool {
+	bounds := []struct {
+		op        ConditionType
+		upper     bool
+		inclusive bool
+	}{
+		{ConditionNumericLessThan, true, false},
+		{ConditionNumericLessThanEquals, true, true},
+		{ConditionNumericGreaterThan, false, false},
+		{ConditionNumericGreaterThanEquals, false, true},
+		{ConditionDateLessThan, true, false},
+		{ConditionDateLessThanEquals, true, true},
+		{ConditionDateGreaterThan, false, false},
+		{ConditionDateGreaterThanEquals, false, true},
+	}
+	var lower, upper bound
+	for _, b := range bounds {
+		for _, value := range ops[b.op] {
+			v, err := parseBound(b.op, value)
+			if err != nil {
+				return false
+			}
+			if b.upper {
+				upper.widen(v, b.inclusive, true)
+			} else {
+				lower.widen(v, b.inclusive, false)
+			}
+		}
+	}
+	if !lower.set || !upper.set {
+		return false
+	}
+	return lower.value > upper.value || (lower.value == upper.value && !(lower.inclusive && upper.inclusive))
+}
+
+// parseBound parses a numeric condition value, or a date condition value into
+// seconds since the epoch
+func parseBound(op ConditionType, value string) (float64, error) {
+	if !strings.HasPrefix(string(op), "Date") {
+		return strconv.ParseFloat(value, 64)
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return float64(t.Unix()), nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
@@ -0,0 +1,217 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func findingCodes(findings Findings) []FindingCode {
+	codes := make([]FindingCode, len(findings))
+	for i, finding := range findings {
+		codes[i] = finding.Code
+	}
+	return codes
+}
+
+func TestLint(t *testing.T) {
+	p := NewPolicy()
+	public := p.AddStatement()
+	public.Effect = Allow
+	public.AddPrincipal("*")
+	public.AddAction("s3:*")
+	public.AddResource("*")
+
+	identity := p.AddIdentityStatement()
+	identity.Effect = Allow
+	identity.AddNotAction("iam:*")
+
+	deny := p.AddIdentityStatement()
+	deny.AddAction("*")
+	deny.AddResource("*")
+
+	findings := p.Lint()
+	expected := []FindingCode{
+		CodeServiceWildcardAction, CodeWildcardResource, CodeWildcardPrincipal,
+		CodeMissingResource, CodeAllowNotAction,
+	}
+	got := findingCodes(findings)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %v got %v", expected, got)
+			break
+		}
+	}
+	if findings[3].Statement != 1 {
+		t.Errorf("Expected finding for statement 1 got %d", findings[3].Statement)
+	}
+
+	if errors := findings.Errors(); len(errors) != 1 || errors[0].Code != CodeMissingResource {
+		t.Errorf("Expected a single missing-resource error got %v", errors)
+	}
+	if warnings := findings.Warnings(); len(warnings) != 2 {
+		t.Errorf("Expected 2 warnings got %v", warnings)
+	}
+	if len(findings.AtLeast(SeverityInfo)) != len(findings) {
+		t.Errorf("Expected all findings to be at least info")
+	}
+}
+
+func TestLintConditions(t *testing.T) {
+	trust := func(federated string, s *Statement) *Statement {
+		s.Kind = ResourceStatement
+		s.Principal = &Principal{Federated: []string{federated}}
+		return s
+	}
+	tests := []struct {
+		stmt     *Statement
+		expected []FindingCode
+	}{
+		{
+			NewStatement(WithActions("ec2:RunInstances"), WithResources("*"), WithCondition(ConditionStringLike, "s3:prefix", "home/*")),
+			[]FindingCode{CodeDeadConditionKey},
+		},
+		{
+			NewStatement(WithActions("s3:ListBucket"), WithResources("*"), WithCondition(ConditionStringLike, "s3:prefix", "home/*"), WithCondition(ConditionStringLike.IfExists(), "ec2:Region", "*")),
+			[]FindingCode{},
+		},
+		{
+			NewStatement(WithActions("*"), WithResources("*"), WithCondition(ConditionStringEquals, "aws:PrincipalTag/team", "red"), WithCondition(ConditionStringNotEquals, "aws:PrincipalTag/team", "red", "blue")),
+			[]FindingCode{CodeContradictoryCondition},
+		},
+		{
+			NewStatement(WithActions("*"), WithResources("*"), WithCondition(ConditionNull, VarSourceIp, "true"), WithCondition(ConditionIpAddress, VarSourceIp, "10.0.0.0/8")),
+			[]FindingCode{CodeContradictoryCondition},
+		},
+		{
+			NewStatement(WithActions("*"), WithResources("*"), WithCondition(ConditionNumericLessThan, VarMultiFactorAuthAge, "3600"), WithCondition(ConditionNumericGreaterThanEquals, VarMultiFactorAuthAge, "3600")),
+			[]FindingCode{CodeContradictoryCondition},
+		},
+		{
+			NewStatement(WithActions("*"), WithResources("*"), WithCondition(ConditionNumericLessThanEquals, VarMultiFactorAuthAge, "3600"), WithCondition(ConditionNumericGreaterThanEquals, VarMultiFactorAuthAge, "3600")),
+			[]FindingCode{},
+		},
+		{
+			NewStatement(WithActions("*"), WithResources("*"), WithCondition(ConditionDateLessThan, VarCurrentTime, "2020-01-01T00:00:00Z"), WithCondition(ConditionDateGreaterThan, VarCurrentTime, "2021-01-01T00:00:00Z")),
+			[]FindingCode{CodeContradictoryCondition},
+		},
+		{
+			trust("arn:aws:iam::111122223333:oidc-provider/oidc.eks.eu-west-1.amazonaws.com/id/EXAMPLE", NewStatement(
+				WithActions("sts:AssumeRoleWithWebIdentity"),
+				WithCondition(ConditionStringEquals, "oidc.eks.eu-west-1.amazonaws.com/id/EXAMPLE:sub", "system:serviceaccount:default:app"),
+			)),
+			[]FindingCode{},
+		},
+		{
+			trust("arn:aws:iam::111122223333:saml-provider/idp", NewStatement(
+				WithActions("sts:AssumeRoleWithSAML"),
+				WithCondition(ConditionStringEquals, "SAML:aud", "https://signin.aws.amazon.com/saml"),
+			)),
+			[]FindingCode{},
+		},
+		{
+			NewStatement(WithActions("s3:GetObject"), WithResources("*"), WithCondition(ConditionArnEquals, "ec2:SourceInstanceARN", "arn:aws:ec2:eu-west-1:111122223333:instance/i-0123456789abcdef0")),
+			[]FindingCode{},
+		},
+	}
+
+	for i, test := range tests {
+		got := findingCodes(test.stmt.lint())
+		if len(got) != len(test.expected) {
+			t.Errorf("%d: Expected %v got %v", i, test.expected, got)
+			continue
+		}
+		for j := range got {
+			if got[j] != test.expected[j] {
+				t.Errorf("%d: Expected %v got %v", i, test.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestLintConditionsMessage(t *testing.T) {
+	stmt := NewStatement(WithActions("ec2:RunInstances"), WithResources("*"), WithCondition(ConditionStringLike, "s3:Prefix", "home/*"))
+	findings := stmt.lint()
+	if len(findings) != 1 || findings[0].Message != "condition key s3:Prefix is never present for the actions of the statement" || findings[0].Remediation != "" {
+		t.Errorf("Expected a dead s3:Prefix finding without remediation got %v", findings)
+	}
+}
+
+func TestLintDocURL(t *testing.T) {
+	sid := "not valid"
+	stmt := NewStatement(WithEffect(Allow), WithActions("s3:GetObject"), WithNotActions("s3:PutObject"), WithResources("*"))
+	stmt.Sid = &sid
+	expected := []string{
+		userGuide + "reference_policies_elements_notaction.html",
+		userGuide + "reference_policies_elements_sid.html",
+	}
+	findings := make(Findings, 0)
+	for _, finding := range stmt.lint() {
+		if finding.Code == CodeInvalidStatement {
+			findings = append(findings, finding)
+		}
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings got %v", len(expected), findings)
+	}
+	for i, finding := range findings {
+		if finding.DocURL != expected[i] {
+			t.Errorf("Expected %s got %s for %s", expected[i], finding.DocURL, finding.Message)
+		}
+	}
+}
+
+func TestLintRemediation(t *testing.T) {
+	p := NewPolicy()
+	p.Append(NewStatement(WithEffect(Allow), WithActions("s3:GetObject", "*"), WithResources("arn:aws:s3:::bucket/*")))
+	p.Append(NewStatement(WithPrincipals("*"), WithActions("s3:PutObject")))
+	p.Append(NewStatement(WithActions("s3:*"), WithResources("*"), WithCondition(ConditionStringEquals, "ec2:Region", "eu-west-1")))
+	p.Statement[1].Kind = IdentityStatement
+
+	expected := []struct {
+		code        FindingCode
+		remediation string
+	}{
+		{CodeWildcardAction, `{"Effect":"Allow","Action":["s3:GetObject","\u003cservice\u003e:\u003caction\u003e"],"Resource":["arn:aws:s3:::bucket/*"]}`},
+		{CodeInvalidStatement, `{"Effect":"Deny","Action":["s3:PutObject"]}`},
+		{CodeMissingResource, `{"Effect":"Deny","Principal":{"AWS":["*"]},"Action":["s3:PutObject"],"Resource":["arn:aws:\u003cservice\u003e:\u003cregion\u003e:\u003caccount\u003e:\u003cresource\u003e"]}`},
+		{CodeDeadConditionKey, ``},
+	}
+	findings := p.Lint()
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings got %v", len(expected), findings)
+	}
+	for i, finding := range findings {
+		if finding.Code != expected[i].code || finding.Remediation != expected[i].remediation {
+			t.Errorf("Expected %s with remediation \n%s got %s with \n%s", expected[i].code, expected[i].remediation, finding.Code, finding.Remediation)
+		}
+		if finding.DocURL == "" {
+			t.Errorf("Expected a documentation link for %s", finding.Code)
+		}
+	}
+}
+
+func TestLintStatementLiteral(t *testing.T) {
+	p := NewPolicy()
+	p.Append(&Statement{Effect: Allow, Principal: &Principal{Aws: []string{"*"}}, Action: []string{"s3:GetObject"}, Resources: []string{"arn:aws:s3:::bucket/*"}})
+
+	expected := `{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"],"Condition":{"StringEquals":{"aws:PrincipalOrgID":["\u003corganization-id\u003e"]}}}`
+	for _, finding := range p.Lint() {
+		if finding.Code == CodeWildcardPrincipal {
+			if finding.Remediation != expected {
+				t.Errorf("Expected remediation \n%s got \n%s", expected, finding.Remediation)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected a %s finding", CodeWildcardPrincipal)
+}
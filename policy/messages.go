@@ -0,0 +1,108 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MessageCatalog translates finding messages. Keys are the English message
+// formats as used by the package, e.g. "statement allows all %s actions",
+// values the translated formats, which must use the same verbs in the same
+// order.
+type MessageCatalog map[string]string
+
+var (
+	catalogsMu sync.RWMutex
+	catalogs   = make(map[string]MessageCatalog)
+)
+
+// RegisterCatalog makes a message catalog available for a locale, e.g. "de"
+// or "pt-BR". Registering a catalog for a locale again replaces it.
+func RegisterCatalog(locale string, catalog MessageCatalog) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	catalogs[normalizeLocale(locale)] = catalog
+}
+
+// MessageFormats returns the English formats of all finding messages, the
+// keys a complete MessageCatalog contains
+func MessageFormats() []string {
+	formats := []string{
+		"statement has no Action or NotAction",
+		"statement has no Resource or NotResource",
+		"statement allows all actions",
+		"statement allows all %s actions",
+		"statement applies to all resources",
+		"statement allows everyone without a condition",
+		"statement allows all actions except those listed in NotAction",
+		"statement allows all resources except those listed in NotResource",
+		"condition key %s is never present for the actions of the statement",
+		"condition key %s must be absent but is also compared",
+		"condition key %s must equal and not equal the same values",
+		"condition key %s has an empty range",
+	}
+	return append(formats, validationFormats()...)
+}
+
+// validationFormats returns the formats of the findings for errors reported
+// by Statement.Validate
+func validationFormats() []string {
+	formats := []string{sidErrorFormat, policyVariableErrorFormat}
+	for _, err := range []InvalidStatementError{
+		errMissingAction, errActionNotAction, errMissingResource, errResourceNotResource,
+		errAllowNotPrincipal, errIdentityPrincipal, errSCPPrincipal, errMissingPrincipal,
+	} {
+		formats = append(formats, err.Error())
+	}
+	for _, reason := range []string{reasonDomain, reasonLabel, reasonFIPS, reasonNoService} {
+		formats = append(formats, servicePrincipalErrorFormat+reason)
+	}
+	return formats
+}
+
+// validationMessage returns the message format and arguments of the finding
+// for an error reported by Statement.Validate, each kind of error has its own
+// format
+func validationMessage(err error) (string, []interface{}) {
+	switch e := err.(type) {
+	case InvalidStatementError:
+		// The messages contain no verbs
+		return e.Error(), nil
+	case InvalidSidError:
+		return sidErrorFormat, []interface{}{string(e)}
+	case *InvalidPolicyVariableError:
+		return policyVariableErrorFormat, []interface{}{e.Element, e.Value}
+	case *InvalidServicePrincipalError:
+		return servicePrincipalErrorFormat + e.format, append([]interface{}{e.Principal}, e.args...)
+	}
+	return "%s", []interface{}{err}
+}
+
+// LocalizedMessage returns the message of the finding in the given locale. A
+// catalog registered for the language of the locale is used if there is none
+// for the locale itself, e.g. "de" for "de-AT". The English message is
+// returned if no catalog translates the message.
+func (f Finding) LocalizedMessage(locale string) string {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+	locale = normalizeLocale(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	for _, l := range []string{locale, language} {
+		if translated, ok := catalogs[l][f.format]; ok {
+			return fmt.Sprintf(translated, f.args...)
+		}
+	}
+	return f.Message
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
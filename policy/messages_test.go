@@ -0,0 +1,75 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestLocalizedMessage(t *testing.T) {
+	RegisterCatalog("de", MessageCatalog{
+		"statement allows all %s actions": "Anweisung erlaubt alle %s-Aktionen",
+	})
+	defer RegisterCatalog("de", nil)
+
+	findings := Build().Allow().Actions("s3:*", "*").Resources("arn:aws:s3:::bucket").Done().Lint()
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings got %v", findings)
+	}
+
+	tests := []struct {
+		finding  Finding
+		locale   string
+		expected string
+	}{
+		{findings[0], "de_AT", "Anweisung erlaubt alle s3-Aktionen"},
+		{findings[0], "fr", "statement allows all s3 actions"},
+		{findings[1], "de", "statement allows all actions"},
+	}
+	for _, test := range tests {
+		if got := test.finding.LocalizedMessage(test.locale); got != test.expected {
+			t.Errorf("Expected %s got %s", test.expected, got)
+		}
+	}
+
+	formats := MessageFormats()
+	for _, finding := range findings {
+		if !contains(formats, finding.format) {
+			t.Errorf("Expected %q in MessageFormats", finding.format)
+		}
+	}
+}
+
+func TestValidationMessages(t *testing.T) {
+	p := Build().
+		Allow().Sid("a-b").Actions("s3:GetObject").NotActions("s3:PutObject").Resources("arn:aws:s3:::${aws:username}").
+		Done()
+	p.Statement[0].Kind = ResourceStatement
+	p.Statement[0].AddServicePrincipal("s3-fips.amazonaws.com")
+	p.Statement[0].AddNotPrincipal("*")
+	p.Statement[0].AddNotResource("arn:aws:s3:::other")
+	p.Statement[0].AddCondition(ConditionNumericEquals, "s3:max-keys", "${aws:username}")
+
+	formats := MessageFormats()
+	seen := make(map[string]bool)
+	for _, finding := range p.Lint() {
+		if finding.Code != CodeInvalidStatement {
+			continue
+		}
+		if finding.format == "%s" || seen[finding.format] {
+			t.Errorf("Expected a format of its own for %s got %q", finding.Message, finding.format)
+		}
+		seen[finding.format] = true
+		if !contains(formats, finding.format) {
+			t.Errorf("Expected %q in MessageFormats", finding.format)
+		}
+	}
+	if len(seen) != 6 {
+		t.Errorf("Expected 6 validation findings got %d", len(seen))
+	}
+}
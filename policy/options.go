@@ -0,0 +1,96 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+// StatementOption configures a Statement created by NewStatement
+type StatementOption func(*Statement)
+
+// Create a new Statement independent of any policy, use Policy.Append to add
+// it to one. Without principal options an identity statement is created.
+func NewStatement(opts ...StatementOption) *Statement {
+	s := &Statement{
+		Kind:      IdentityStatement,
+		Action:    make([]string, 0, 1),
+		Condition: make(map[ConditionType]map[ConditionVariable][]string),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithSid sets the Sid of the statement
+func WithSid(sid string) StatementOption {
+	return func(s *Statement) {
+		s.SetSid(sid)
+	}
+}
+
+// WithEffect sets the Effect of the statement
+func WithEffect(e Effect) StatementOption {
+	return func(s *Statement) {
+		s.Effect = e
+	}
+}
+
+// WithPrincipals adds AWS principals, making it a resource statement
+func WithPrincipals(principals ...string) StatementOption {
+	return func(s *Statement) {
+		s.Kind = ResourceStatement
+		s.AddPrincipals(principals...)
+	}
+}
+
+// WithServicePrincipals adds service principals, making it a resource
+// statement
+func WithServicePrincipals(principals ...string) StatementOption {
+	return func(s *Statement) {
+		s.Kind = ResourceStatement
+		s.AddServicePrincipals(principals...)
+	}
+}
+
+// WithActions adds actions to the statement
+func WithActions(actions ...string) StatementOption {
+	return func(s *Statement) {
+		s.AddActions(actions...)
+	}
+}
+
+// WithNotActions adds actions to the NotAction list of the statement
+func WithNotActions(actions ...string) StatementOption {
+	return func(s *Statement) {
+		s.AddNotActions(actions...)
+	}
+}
+
+// WithResources adds resources to the statement
+func WithResources(resources ...string) StatementOption {
+	return func(s *Statement) {
+		s.AddResources(resources...)
+	}
+}
+
+// WithNotResources adds resources to the NotResource list of the statement
+func WithNotResources(resources ...string) StatementOption {
+	return func(s *Statement) {
+		s.AddNotResources(resources...)
+	}
+}
+
+// WithCondition adds a condition with one or more values to the statement
+func WithCondition(t ConditionType, key ConditionVariable, values ...string) StatementOption {
+	return func(s *Statement) {
+		s.AddConditionValues(t, key, values...)
+	}
+}
+
+// Append adds existing statements to the policy
+func (p *Policy) Append(stmts ...*Statement) {
+	p.Statement = append(p.Statement, stmts...)
+}
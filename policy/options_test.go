@@ -0,0 +1,34 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestNewStatement(t *testing.T) {
+	stmt := NewStatement(
+		WithSid("Read"),
+		WithEffect(Allow),
+		WithActions("s3:GetObject", "s3:ListBucket"),
+		WithResources("arn:aws:s3:::bucket", "arn:aws:s3:::bucket/*"),
+		WithCondition(ConditionStringEquals, "aws:PrincipalTag/team", "red", "blue"),
+	)
+	if err := stmt.Validate(); err != nil {
+		t.Errorf("Failed validating statement: %s", err)
+	}
+
+	p := NewPolicy()
+	p.Append(stmt, NewStatement(WithPrincipals("*"), WithNotActions("s3:GetObject")))
+	expected := `{"Version":"2012-10-17","Statement":[{"Sid":"Read","Effect":"Allow","Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::bucket","arn:aws:s3:::bucket/*"],"Condition":{"StringEquals":{"aws:PrincipalTag/team":["red","blue"]}}},{"Effect":"Deny","Principal":{"AWS":["*"]},"NotAction":["s3:GetObject"]}]}`
+
+	assertPolicy(t, p, expected)
+	if p.Statement[1].Kind != ResourceStatement {
+		t.Errorf("Expected resource statement got %v", p.Statement[1].Kind)
+	}
+}
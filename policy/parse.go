@@ -0,0 +1,452 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strict parsing error when a document contains an element that is not part
+// of the policy grammar
+type UnknownFieldError string
+
+func (s UnknownFieldError) Error() string {
+	return fmt.Sprintf("Unknown field %s", string(s))
+}
+
+// Strict parsing error when a single value is used where a list is expected
+type ScalarShorthandError string
+
+func (s ScalarShorthandError) Error() string {
+	return fmt.Sprintf("Scalar value used for %s", string(s))
+}
+
+// Strict parsing error when multiple statements use the same Sid
+type DuplicateSidError string
+
+func (s DuplicateSidError) Error() string {
+	return fmt.Sprintf("Duplicate Sid %s", string(s))
+}
+
+// Parsing error when an object in the document contains the same key more
+// than once
+type DuplicateKeyError string
+
+func (s DuplicateKeyError) Error() string {
+	return fmt.Sprintf("Duplicate key %s", string(s))
+}
+
+// ParseOptions controls how strictly policy documents are parsed. The zero
+// value accepts every document AWS accepts.
+type ParseOptions struct {
+	// Reject elements that are not part of the policy grammar
+	DisallowUnknownFields bool
+	// Reject single values where a list is expected, e.g. "Action": "s3:*"
+	// or "Principal": "*". A single Resource is accepted.
+	DisallowScalars bool
+	// Reject documents in which multiple statements use the same Sid
+	DisallowDuplicateSids bool
+	// Accept // and /* */ comments and trailing commas
+	JSONC bool
+	// Accept element names, principal types and condition operators in any
+	// case, e.g. "effect" or "stringequals", rewriting them to the proper case
+	CaseInsensitiveNames bool
+	// Reject element names, principal types and condition operators that are
+	// not written in the proper case. Without either option encoding/json
+	// matches element names case-insensitively.
+	DisallowCaseVariants bool
+	// The Codec to decode the document with, and to marshal the loaded
+	// policy with. Defaults to StdCodec.
+	Codec Codec
+}
+
+// StrictParseOptions enables all checks, suitable for enforcing policy hygiene
+// in CI pipelines
+var StrictParseOptions = ParseOptions{
+	DisallowUnknownFields: true,
+	DisallowScalars:       true,
+	DisallowDuplicateSids: true,
+	DisallowCaseVariants:  true,
+}
+
+var (
+	policyFields    = []string{"Version", "Id", "Statement"}
+	statementFields = []string{"Sid", "Effect", "Principal", "NotPrincipal", "Action", "NotAction", "Resource", "NotResource", "Condition"}
+	principalFields = []string{"AWS", "Service", "Federated", "CanonicalUser"}
+	// Resource is a string field, a single resource is not a shorthand
+	listFields = []string{"Action", "NotAction", "NotResource"}
+)
+
+// Create a policy from JSON using the given parse options
+func LoadPolicyWithOptions(b []byte, opts ParseOptions) (*Policy, error) {
+	if opts.JSONC {
+		b = stripJSONC(b)
+	}
+	c := defaultCodec(opts.Codec)
+	if opts.CaseInsensitiveNames || opts.DisallowCaseVariants {
+		var err error
+		if b, err = checkElementCase(b, opts.CaseInsensitiveNames, c); err != nil {
+			return nil, err
+		}
+	}
+	p := Policy{codec: opts.Codec}
+	err := c.Unmarshal(b, &p)
+	if err != nil {
+		return nil, locateError(b, err, c)
+	}
+	if err := checkDuplicateKeys(b, c); err != nil {
+		return nil, err
+	}
+	if opts.DisallowUnknownFields || opts.DisallowScalars {
+		var doc map[string]interface{}
+		if err := c.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		if err := checkDocument(doc, opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.DisallowDuplicateSids {
+		sids := make(map[string]bool)
+		for _, stmt := range p.Statement {
+			if stmt.Sid == nil {
+				continue
+			}
+			if sids[*stmt.Sid] {
+				return nil, DuplicateSidError(*stmt.Sid)
+			}
+			sids[*stmt.Sid] = true
+		}
+	}
+	return &p, nil
+}
+
+// Create a policy from JSON, rejecting unknown fields, scalar shorthands,
+// duplicate Sids and case variants of element names
+func LoadPolicyStrict(b []byte) (*Policy, error) {
+	return LoadPolicyWithOptions(b, StrictParseOptions)
+}
+
+func checkDocument(doc map[string]interface{}, opts ParseOptions) error {
+	if err := checkFields("", doc, policyFields, opts); err != nil {
+		return err
+	}
+	var statements []interface{}
+	switch v := doc["Statement"].(type) {
+	case []interface{}:
+		statements = v
+	case map[string]interface{}:
+		if opts.DisallowScalars {
+			return ScalarShorthandError("Statement")
+		}
+		statements = []interface{}{v}
+	}
+	for _, s := range statements {
+		stmt, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := checkStatement(stmt, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkStatement(stmt map[string]interface{}, opts ParseOptions) error {
+	if err := checkFields("Statement.", stmt, statementFields, opts); err != nil {
+		return err
+	}
+	for _, name := range []string{"Principal", "NotPrincipal"} {
+		if _, ok := stmt[name].(string); ok && opts.DisallowScalars {
+			return ScalarShorthandError("Statement." + name)
+		}
+		principal, ok := stmt[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := checkFields("Statement."+name+".", principal, principalFields, opts); err != nil {
+			return err
+		}
+		if err := checkLists("Statement."+name+".", principal, principalFields, opts); err != nil {
+			return err
+		}
+	}
+	if err := checkLists("Statement.", stmt, listFields, opts); err != nil {
+		return err
+	}
+	condition, _ := stmt["Condition"].(map[string]interface{})
+	for t, c := range condition {
+		vars, _ := c.(map[string]interface{})
+		for key := range vars {
+			if err := checkLists("Statement.Condition."+t+".", vars, []string{key}, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkFields(path string, v map[string]interface{}, fields []string, opts ParseOptions) error {
+	if !opts.DisallowUnknownFields {
+		return nil
+	}
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !contains(fields, key) {
+			return UnknownFieldError(path + key)
+		}
+	}
+	return nil
+}
+
+func checkLists(path string, v map[string]interface{}, fields []string, opts ParseOptions) error {
+	if !opts.DisallowScalars {
+		return nil
+	}
+	for _, key := range fields {
+		value, ok := v[key]
+		if !ok {
+			continue
+		}
+		if _, ok := value.([]interface{}); !ok {
+			return ScalarShorthandError(path + key)
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseError annotates an error encountered while loading a policy with the
+// location of the offending value in the document
+type ParseError struct {
+	// Path to the value, e.g. Statement[12].Condition.IpAddress
+	Path string
+	// Byte offset of the value in the document
+	Offset int64
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at %s (offset %d)", e.Err.Error(), e.Path, e.Offset)
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// locateError finds the value in the document that caused err and wraps err
+// in a ParseError. The deepest value that fails to decode on its own is
+// reported.
+func locateError(data []byte, err error, c Codec) error {
+	var found *ParseError
+	w := &jsonWalker{data: data, dec: c.NewTokenizer(data)}
+	w.visit = func(path []string, offset int64, raw []byte) bool {
+		if e := checkValue(path, raw, c); e != nil {
+			found = &ParseError{Path: formatPath(path), Offset: offset, Err: e}
+			return false
+		}
+		return true
+	}
+	werr := w.walk()
+	if syntaxErr, ok := werr.(*json.SyntaxError); ok && found == nil {
+		found = &ParseError{Path: formatPath(w.path), Offset: syntaxErr.Offset, Err: err}
+	}
+	if found == nil {
+		return err
+	}
+	return found
+}
+
+// checkDuplicateKeys returns a ParseError wrapping a DuplicateKeyError for the
+// first key that appears twice in the same object. encoding/json silently
+// keeps the last value, while AWS rejects such documents.
+func checkDuplicateKeys(data []byte, c Codec) error {
+	var found *ParseError
+	seen := make(map[string]bool)
+	w := &jsonWalker{data: data, dec: c.NewTokenizer(data)}
+	w.enter = func(path []string, offset int64) bool {
+		key := strings.Join(path, "\x00")
+		if seen[key] {
+			found = &ParseError{Path: formatPath(path), Offset: offset, Err: DuplicateKeyError(path[len(path)-1])}
+			return false
+		}
+		seen[key] = true
+		return true
+	}
+	w.walk()
+	if found == nil {
+		return nil
+	}
+	return found
+}
+
+// checkValue decodes a single value of the document into the type used for
+// it by the policy model
+func checkValue(path []string, raw []byte, c Codec) error {
+	var v interface{}
+	if len(path) >= 2 && strings.EqualFold(path[0], "Statement") && !isIndex(path[1]) {
+		// Single statement object instead of an array
+		path = append([]string{path[0], "[0]"}, path[1:]...)
+	}
+	inStatement := len(path) >= 2 && strings.EqualFold(path[0], "Statement") && isIndex(path[1])
+	switch {
+	case len(path) == 0:
+		v = &Policy{}
+	case len(path) == 1 && strings.EqualFold(path[0], "Version"):
+		v = new(PolicyVersion)
+	case len(path) == 1 && strings.EqualFold(path[0], "Statement"):
+		v = new(statementList)
+	case len(path) == 2 && inStatement:
+		v = &Statement{}
+	case len(path) == 3 && inStatement:
+		switch strings.ToLower(path[2]) {
+		case "sid":
+			v = new(string)
+		case "effect":
+			v = new(Effect)
+		case "principal", "notprincipal":
+			v = &Principal{}
+		case "action", "notaction", "resource", "notresource":
+			v = new(stringList)
+		case "condition":
+			v = &map[ConditionType]map[ConditionVariable]conditionValues{}
+		}
+	case len(path) == 4 && inStatement && strings.EqualFold(path[2], "Condition"):
+		if err := new(ConditionType).UnmarshalText([]byte(path[3])); err != nil {
+			return err
+		}
+		v = &map[ConditionVariable]conditionValues{}
+	case len(path) == 4 && inStatement:
+		switch strings.ToLower(path[2]) {
+		case "principal", "notprincipal":
+			v = new(stringList)
+		}
+	case len(path) == 5 && inStatement && strings.EqualFold(path[2], "Condition"):
+		var values conditionValues
+		if err := c.Unmarshal(raw, &values); err != nil {
+			return err
+		}
+		return validateConditionValues(ConditionType(path[3]), values)
+	}
+	if v == nil {
+		return nil
+	}
+	return c.Unmarshal(raw, v)
+}
+
+func isIndex(s string) bool {
+	return strings.HasPrefix(s, "[")
+}
+
+func formatPath(path []string) string {
+	var b bytes.Buffer
+	for i, s := range path {
+		if i > 0 && !isIndex(s) {
+			b.WriteByte('.')
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// errStopWalk is returned by jsonWalker.walk when visiting was stopped
+var errStopWalk = errors.New("walk stopped")
+
+// jsonWalker visits every value of a JSON document together with its path and
+// byte offset. enter is called before the children of a value are walked and
+// visit after, key is called for every object key with its raw quoted bytes.
+// Any of them may be nil.
+type jsonWalker struct {
+	data  []byte
+	dec   Tokenizer
+	path  []string
+	enter func(path []string, offset int64) bool
+	visit func(path []string, offset int64, raw []byte) bool
+	key   func(path []string, offset int64, raw []byte) bool
+}
+
+func (w *jsonWalker) walk() error {
+	return w.value()
+}
+
+func (w *jsonWalker) value() error {
+	start := w.dec.InputOffset()
+	for start < int64(len(w.data)) && strings.IndexByte(" \t\r\n:,", w.data[start]) >= 0 {
+		start++
+	}
+	if w.enter != nil && !w.enter(w.path, start) {
+		return errStopWalk
+	}
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for w.dec.More() {
+			keyStart := w.dec.InputOffset()
+			for keyStart < int64(len(w.data)) && strings.IndexByte(" \t\r\n,", w.data[keyStart]) >= 0 {
+				keyStart++
+			}
+			key, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			if w.key != nil && !w.key(append(w.path, key.(string)), keyStart, w.data[keyStart:w.dec.InputOffset()]) {
+				return errStopWalk
+			}
+			if err := w.child(key.(string)); err != nil {
+				return err
+			}
+		}
+		if _, err := w.dec.Token(); err != nil {
+			return err
+		}
+	case json.Delim('['):
+		for i := 0; w.dec.More(); i++ {
+			if err := w.child(fmt.Sprintf("[%d]", i)); err != nil {
+				return err
+			}
+		}
+		if _, err := w.dec.Token(); err != nil {
+			return err
+		}
+	}
+	if w.visit != nil && !w.visit(w.path, start, w.data[start:w.dec.InputOffset()]) {
+		return errStopWalk
+	}
+	return nil
+}
+
+func (w *jsonWalker) child(name string) error {
+	w.path = append(w.path, name)
+	if err := w.value(); err != nil {
+		return err
+	}
+	w.path = w.path[:len(w.path)-1]
+	return nil
+}
@@ -0,0 +1,222 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadPolicyScalars(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::bucket/*","NotResource":"arn:aws:s3:::bucket/secret/*"},{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":["sts:AssumeRole"],"Resource":["*"]}]}`)
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":"arn:aws:s3:::bucket/*","NotResource":["arn:aws:s3:::bucket/secret/*"]},{"Effect":"Allow","Principal":{"Service":["ec2.amazonaws.com"]},"Action":["sts:AssumeRole"],"Resource":["*"]}]}`
+
+	p, err := LoadPolicy(data)
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	assertPolicy(t, p, expected)
+
+	_, err = LoadPolicyStrict(data)
+	if _, ok := err.(ScalarShorthandError); err == nil || !ok {
+		t.Errorf("Expected ScalarShorthandError got %v", err)
+	}
+}
+
+func TestLoadPolicyInvalidPrincipal(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"arn:aws:iam::123456789012:root","Action":["*"],"Resource":["*"]}]}`)
+
+	_, err := LoadPolicy(data)
+	var principalErr InvalidPrincipalError
+	if !errors.As(err, &principalErr) {
+		t.Errorf("Expected InvalidPrincipalError got %v", err)
+	}
+}
+
+func TestLoadPolicyStrict(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Sid":"a","Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["*"],"Condition":{"Bool":{"aws:SecureTransport":["true"]}}}]}`)
+
+	_, err := LoadPolicyStrict(data)
+	if err != nil {
+		t.Errorf("Failed loading policy: %s", err)
+	}
+}
+
+func TestLoadPolicyStrictErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		err  error
+	}{
+		{
+			`{"Version":"2012-10-17","Foo":"bar","Statement":[]}`,
+			UnknownFieldError("Foo"),
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Actions":["*"],"Resource":["*"]}]}`,
+			UnknownFieldError("Statement.Actions"),
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"],"Users":["*"]},"Action":["*"],"Resource":["*"]}]}`,
+			UnknownFieldError("Statement.Principal.Users"),
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"*"},"Action":["*"],"Resource":["*"]}]}`,
+			ScalarShorthandError("Statement.Principal.AWS"),
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":["*"],"Resource":["*"]}]}`,
+			ScalarShorthandError("Statement.Principal"),
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","NotPrincipal":"*","Action":["*"],"Resource":["*"]}]}`,
+			ScalarShorthandError("Statement.NotPrincipal"),
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["*"],"Resource":["*"],"Condition":{"Bool":{"aws:SecureTransport":"true"}}}]}`,
+			ScalarShorthandError("Statement.Condition.Bool.aws:SecureTransport"),
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Sid":"a","Effect":"Allow","Action":["*"],"Resource":["*"]},{"Sid":"a","Effect":"Deny","Action":["*"],"Resource":["*"]}]}`,
+			DuplicateSidError("a"),
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := LoadPolicy([]byte(test.data)); err != nil {
+			t.Errorf("Failed loading policy %s: %s", test.data, err)
+		}
+		_, err := LoadPolicyStrict([]byte(test.data))
+		if err != test.err {
+			t.Errorf("Expected %v got %v", test.err, err)
+		}
+	}
+}
+
+func TestLoadPolicyWithOptions(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Sid":"a","Effect":"Allow","Action":"*","Resource":["*"]},{"Sid":"a","Effect":"Deny","Action":["*"],"Resource":["*"]}]}`)
+
+	_, err := LoadPolicyWithOptions(data, ParseOptions{DisallowUnknownFields: true})
+	if err != nil {
+		t.Errorf("Failed loading policy: %s", err)
+	}
+
+	_, err = LoadPolicyWithOptions(data, ParseOptions{DisallowDuplicateSids: true})
+	if err != DuplicateSidError("a") {
+		t.Errorf("Expected DuplicateSidError got %v", err)
+	}
+}
+
+func TestParseErrorLocation(t *testing.T) {
+	tests := []struct {
+		data   string
+		path   string
+		offset int64
+	}{
+		{
+			`{"Version":"2012-10-18","Statement":[]}`,
+			"Version",
+			11,
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow"},{"Effect":"Maybe"}]}`,
+			"Statement[1].Effect",
+			66,
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":[1]}]}`,
+			"Statement[0].Action",
+			64,
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Condition":{"IpAddress":{"aws:SourceIp":[null]}}}]}`,
+			"Statement[0].Condition.IpAddress.aws:SourceIp",
+			96,
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["*"}]}`,
+			"Statement[0].Action",
+			69,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := LoadPolicy([]byte(test.data))
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Expected ParseError got %v", err)
+			continue
+		}
+		if parseErr.Path != test.path || parseErr.Offset != test.offset {
+			t.Errorf("Expected error at %s (offset %d) got %s (offset %d)", test.path, test.offset, parseErr.Path, parseErr.Offset)
+		}
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		data string
+		path string
+		key  DuplicateKeyError
+	}{
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["*"],"Effect":"Deny"}]}`,
+			"Statement[0].Effect",
+			"Effect",
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Condition":{"IpAddress":{"aws:SourceIp":["10.0.0.0/8"],"aws:SourceIp":["0.0.0.0/0"]}}}]}`,
+			"Statement[0].Condition.IpAddress.aws:SourceIp",
+			"aws:SourceIp",
+		},
+		{
+			`{"Version":"2012-10-17","Statement":[],"Statement":[]}`,
+			"Statement",
+			"Statement",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := LoadPolicy([]byte(test.data))
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Expected ParseError got %v", err)
+			continue
+		}
+		if parseErr.Path != test.path || parseErr.Err != test.key {
+			t.Errorf("Expected %s at %s got %s at %s", test.key, test.path, parseErr.Err, parseErr.Path)
+		}
+	}
+
+	data := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["*"]},{"Effect":"Allow","Action":["*"]}]}`
+	if _, err := LoadPolicy([]byte(data)); err != nil {
+		t.Errorf("Failed loading policy: %s", err)
+	}
+}
+
+func TestLoadPolicySingleStatement(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["*"]}}`)
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["*"]}]}`
+
+	p, err := LoadPolicy(data)
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	assertPolicy(t, p, expected)
+
+	_, err = LoadPolicyStrict(data)
+	if err != ScalarShorthandError("Statement") {
+		t.Errorf("Expected ScalarShorthandError got %v", err)
+	}
+
+	data = []byte(`{"Version":"2012-10-17","Statement":{"Effect":"Maybe"}}`)
+	_, err = LoadPolicy(data)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Path != "Statement.Effect" {
+		t.Errorf("Expected ParseError at Statement.Effect got %v", err)
+	}
+}
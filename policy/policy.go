@@ -10,8 +10,11 @@
 package policy
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // PolicyVersion unmarshaling error when an invalid policy document version is
@@ -34,6 +37,36 @@ func (s InvalidEffectError) Error() string {
 	return fmt.Sprintf("Invalid Effect %s", string(s))
 }
 
+// Condition unmarshaling error when an unknown condition operator is used
+type InvalidConditionTypeError string
+
+func (s InvalidConditionTypeError) Error() string {
+	return fmt.Sprintf("Invalid Condition Type %s", string(s))
+}
+
+// Condition variable unmarshaling error when a condition key is empty
+type InvalidConditionVariableError string
+
+func (s InvalidConditionVariableError) Error() string {
+	return fmt.Sprintf("Invalid Condition Variable %q", string(s))
+}
+
+// Principal unmarshaling error when a principal is given as a string other
+// than "*"
+type InvalidPrincipalError string
+
+func (s InvalidPrincipalError) Error() string {
+	return fmt.Sprintf("Invalid Principal %s", string(s))
+}
+
+// Condition unmarshaling error when a condition value is not a string, number
+// or boolean
+type InvalidConditionValueError string
+
+func (s InvalidConditionValueError) Error() string {
+	return fmt.Sprintf("Invalid Condition Value %s", string(s))
+}
+
 // PolicyVersion represents the version of an IAM policy document. It will
 // always be "2012-10-17". A document using the older "2008-10-17" version
 // will automatically by 'upgraded'
@@ -85,16 +118,87 @@ func (e *Effect) UnmarshalJSON(b []byte) error {
 	return InvalidEffectError(s)
 }
 
+func (e Effect) String() string {
+	if bool(e) {
+		return "Allow"
+	}
+	return "Deny"
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (e Effect) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (e *Effect) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "Allow":
+		*e = Allow
+	case "Deny":
+		*e = Deny
+	default:
+		return InvalidEffectError(b)
+	}
+	return nil
+}
+
 // The person or persons who receive or are denied permission according to the
 // policy
 type Principal struct {
-	Aws []string `json:"AWS"`
+	Aws           []string `json:"AWS"`
+	Service       []string `json:",omitempty"`
+	Federated     []string `json:",omitempty"`
+	CanonicalUser []string `json:",omitempty"`
 }
 
 func NewPrincipal() *Principal {
 	return &Principal{
-		make([]string, 0),
+		Aws: make([]string, 0),
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface. Empty lists are left
+// out.
+func (p Principal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Aws           []string `json:"AWS,omitempty"`
+		Service       []string `json:",omitempty"`
+		Federated     []string `json:",omitempty"`
+		CanonicalUser []string `json:",omitempty"`
+	}{p.Aws, p.Service, p.Federated, p.CanonicalUser})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Besides the object
+// form it accepts the "*" shorthand for everyone.
+func (p *Principal) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil && string(b) != "null" {
+		if s != "*" {
+			return InvalidPrincipalError(b)
+		}
+		*p = Principal{Aws: []string{"*"}}
+		return nil
+	}
+	var v struct {
+		Aws           stringList `json:"AWS"`
+		Service       stringList
+		Federated     stringList
+		CanonicalUser stringList
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*p = Principal{
+		Aws:           []string(v.Aws),
+		Service:       []string(v.Service),
+		Federated:     []string(v.Federated),
+		CanonicalUser: []string(v.CanonicalUser),
+	}
+	if p.Aws == nil {
+		p.Aws = make([]string, 0)
 	}
+	return nil
 }
 
 // ConditionType represents all the possible comparison types for the
@@ -128,11 +232,155 @@ const (
 	ConditionArnLike                   ConditionType = "ArnLike"
 	ConditionArnNotLike                ConditionType = "ArnNotLike"
 	ConditionNull                      ConditionType = "Null"
+	ConditionBinaryEquals              ConditionType = "BinaryEquals"
 )
 
+var conditionTypes = map[ConditionType]bool{
+	ConditionStringEquals:              true,
+	ConditionStringNotEquals:           true,
+	ConditionStringEqualsIgnoreCase:    true,
+	ConditionStringNotEqualsIgnoreCase: true,
+	ConditionStringLike:                true,
+	ConditionStringNotLike:             true,
+	ConditionNumericEquals:             true,
+	ConditionNumericNotEquals:          true,
+	ConditionNumericLessThan:           true,
+	ConditionNumericLessThanEquals:     true,
+	ConditionNumericGreaterThan:        true,
+	ConditionNumericGreaterThanEquals:  true,
+	ConditionDateEquals:                true,
+	ConditionDateNotEquals:             true,
+	ConditionDateLessThan:              true,
+	ConditionDateLessThanEquals:        true,
+	ConditionDateGreaterThan:           true,
+	ConditionDateGreaterThanEquals:     true,
+	ConditionBool:                      true,
+	ConditionIpAddress:                 true,
+	ConditionNotIpAddress:              true,
+	ConditionArnEquals:                 true,
+	ConditionArnNotEquals:              true,
+	ConditionArnLike:                   true,
+	ConditionArnNotLike:                true,
+	ConditionNull:                      true,
+	ConditionBinaryEquals:              true,
+}
+
+const conditionIfExists = "IfExists"
+
+// IfExists returns the ConditionType with the IfExists suffix, making the
+// condition match when the key is not present in the request context
+func (c ConditionType) IfExists() ConditionType {
+	if c.IsIfExists() {
+		return c
+	}
+	return c + conditionIfExists
+}
+
+// IsIfExists reports whether the ConditionType has the IfExists suffix
+func (c ConditionType) IsIfExists() bool {
+	return strings.HasSuffix(string(c), conditionIfExists)
+}
+
+// Operator returns the comparison operator of the ConditionType, without set
+// operator qualifier and IfExists suffix
+func (c ConditionType) Operator() ConditionType {
+	return ConditionType(strings.TrimSuffix(string(c.BaseType()), conditionIfExists))
+}
+
+// Valid reports whether the ConditionType is a known comparison operator,
+// optionally qualified with a set operator and/or IfExists suffix
+func (c ConditionType) Valid() bool {
+	switch c.SetOperator() {
+	case "", ForAllValues, ForAnyValue:
+	default:
+		return false
+	}
+	op := c.Operator()
+	if !conditionTypes[op] {
+		return false
+	}
+	return !(op == ConditionNull && c.IsIfExists())
+}
+
+func (c ConditionType) String() string {
+	return string(c)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (c ConditionType) MarshalText() ([]byte, error) {
+	if !c.Valid() {
+		return nil, InvalidConditionTypeError(c)
+	}
+	return []byte(c), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (c *ConditionType) UnmarshalText(b []byte) error {
+	t := ConditionType(b)
+	if !t.Valid() {
+		return InvalidConditionTypeError(t)
+	}
+	*c = t
+	return nil
+}
+
+// ConditionSetOperator qualifies a ConditionType for use with multivalued
+// context keys, e.g. "ForAllValues:StringEquals"
+type ConditionSetOperator string
+
+const (
+	ForAllValues ConditionSetOperator = "ForAllValues"
+	ForAnyValue  ConditionSetOperator = "ForAnyValue"
+)
+
+// SetOperator returns the set operator the ConditionType is qualified with, or
+// an empty string if it has none
+func (c ConditionType) SetOperator() ConditionSetOperator {
+	if i := strings.Index(string(c), ":"); i >= 0 {
+		return ConditionSetOperator(c[:i])
+	}
+	return ""
+}
+
+// BaseType returns the ConditionType without its set operator qualifier
+func (c ConditionType) BaseType() ConditionType {
+	if i := strings.Index(string(c), ":"); i >= 0 {
+		return c[i+1:]
+	}
+	return c
+}
+
+// WithSetOperator returns the ConditionType qualified with the given set
+// operator, replacing any existing qualifier
+func (c ConditionType) WithSetOperator(o ConditionSetOperator) ConditionType {
+	if o == "" {
+		return c.BaseType()
+	}
+	return ConditionType(string(o) + ":" + string(c.BaseType()))
+}
+
 // ConditionVariable represent the available variables used in Conditions
 type ConditionVariable string
 
+func (v ConditionVariable) String() string {
+	return string(v)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (v ConditionVariable) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Any
+// condition key is accepted, only empty keys are rejected.
+func (v *ConditionVariable) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		return InvalidConditionVariableError("")
+	}
+	*v = ConditionVariable(b)
+	return nil
+}
+
 const (
 	VarCurrentTime        ConditionVariable = "aws:CurrentTime"
 	VarEpochTime          ConditionVariable = "aws:EpochTime"
@@ -146,16 +394,376 @@ const (
 	VarUsername           ConditionVariable = "aws:username"
 )
 
+// Global condition keys added since the original set, see
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_condition-keys.html
+const (
+	// Properties of the principal
+	VarPrincipalArn              ConditionVariable = "aws:PrincipalArn"
+	VarPrincipalAccount          ConditionVariable = "aws:PrincipalAccount"
+	VarPrincipalOrgID            ConditionVariable = "aws:PrincipalOrgID"
+	VarPrincipalOrgPaths         ConditionVariable = "aws:PrincipalOrgPaths"
+	VarPrincipalIsAWSService     ConditionVariable = "aws:PrincipalIsAWSService"
+	VarPrincipalServiceName      ConditionVariable = "aws:PrincipalServiceName"
+	VarPrincipalServiceNamesList ConditionVariable = "aws:PrincipalServiceNamesList"
+	VarSourceIdentity            ConditionVariable = "aws:SourceIdentity"
+	VarFederatedProvider         ConditionVariable = "aws:FederatedProvider"
+
+	// Properties of the role session
+	VarMultiFactorAuthPresent ConditionVariable = "aws:MultiFactorAuthPresent"
+	VarTokenIssueTime         ConditionVariable = "aws:TokenIssueTime"
+	VarAssumedRoot            ConditionVariable = "aws:AssumedRoot"
+	VarEc2InstanceSourceVpc   ConditionVariable = "aws:Ec2InstanceSourceVpc"
+	VarEc2InstanceSourceIp    ConditionVariable = "aws:Ec2InstanceSourcePrivateIPv4"
+
+	// Properties of the network
+	VarSourceVpc   ConditionVariable = "aws:SourceVpc"
+	VarSourceVpce  ConditionVariable = "aws:SourceVpce"
+	VarVpcSourceIp ConditionVariable = "aws:VpcSourceIp"
+
+	// Properties of the resource
+	VarResourceAccount  ConditionVariable = "aws:ResourceAccount"
+	VarResourceOrgID    ConditionVariable = "aws:ResourceOrgID"
+	VarResourceOrgPaths ConditionVariable = "aws:ResourceOrgPaths"
+
+	// Properties of the request
+	VarRequestedRegion ConditionVariable = "aws:RequestedRegion"
+	VarSourceAccount   ConditionVariable = "aws:SourceAccount"
+	VarSourceOrgID     ConditionVariable = "aws:SourceOrgID"
+	VarSourceOrgPaths  ConditionVariable = "aws:SourceOrgPaths"
+	VarViaAWSService   ConditionVariable = "aws:ViaAWSService"
+	VarCalledVia       ConditionVariable = "aws:CalledVia"
+	VarCalledViaFirst  ConditionVariable = "aws:CalledViaFirst"
+	VarCalledViaLast   ConditionVariable = "aws:CalledViaLast"
+	VarReferer         ConditionVariable = "aws:Referer"
+	VarTagKeys         ConditionVariable = "aws:TagKeys"
+)
+
+// RequestTag returns the condition key for the value of the tag tagKey passed
+// in the request, e.g. aws:RequestTag/Team
+func RequestTag(tagKey string) ConditionVariable {
+	return ConditionVariable("aws:RequestTag/" + tagKey)
+}
+
+// ResourceTag returns the condition key for the value of the tag tagKey on
+// the resource, e.g. aws:ResourceTag/Team
+func ResourceTag(tagKey string) ConditionVariable {
+	return ConditionVariable("aws:ResourceTag/" + tagKey)
+}
+
+// PrincipalTag returns the condition key for the value of the tag tagKey on
+// the principal making the request, e.g. aws:PrincipalTag/Team
+func PrincipalTag(tagKey string) ConditionVariable {
+	return ConditionVariable("aws:PrincipalTag/" + tagKey)
+}
+
+// stringList unmarshals a list of strings that may also be given as a single
+// string, as AWS accepts both forms
+type stringList []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *stringList) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*l = stringList{s}
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// conditionValues unmarshals the values of a single condition key. Besides an
+// array of strings it accepts a single value, and coerces JSON booleans and
+// numbers to their string form.
+type conditionValues []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *conditionValues) UnmarshalJSON(b []byte) error {
+	var raw interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		items = []interface{}{raw}
+	}
+	values := make(conditionValues, 0, len(items))
+	for _, item := range items {
+		switch i := item.(type) {
+		case string:
+			values = append(values, i)
+		case bool:
+			values = append(values, strconv.FormatBool(i))
+		case json.Number:
+			values = append(values, i.String())
+		default:
+			return InvalidConditionValueError(b)
+		}
+	}
+	*v = values
+	return nil
+}
+
+// StatementKind tells whether a Statement is part of a resource-based policy,
+// which names the Principal it applies to, or an identity-based policy or
+// service control policy, which must not contain a Principal
+type StatementKind int
+
+const (
+	ResourceStatement StatementKind = iota
+	IdentityStatement
+	ServiceControlStatement
+)
+
+// Statement validation error
+type InvalidStatementError string
+
+func (s InvalidStatementError) Error() string {
+	return fmt.Sprintf("Invalid Statement: %s", string(s))
+}
+
 // The main element of a single Policy Statement
 type Statement struct {
-	Sid          *string `json:",omitempty"`
+	Kind         StatementKind `json:"-"`
+	Sid          *string       `json:",omitempty"`
 	Effect       Effect
-	Principal    *Principal
+	Principal    *Principal `json:",omitempty"`
 	NotPrincipal *Principal `json:",omitempty"`
 	Action       []string
 	NotAction    []string `json:",omitempty"`
-	Resource     string
-	Condition    map[ConditionType]map[ConditionVariable][]string `json:",omitempty"`
+	// A single resource, marshaled as a string when Resources is empty
+	Resource string
+	// The resources when the statement has a list of them, AddResource and
+	// AddResources append to it. ResourceList returns Resource and Resources
+	// together.
+	Resources   []string                                         `json:"-"`
+	NotResource []string                                         `json:",omitempty"`
+	Condition   map[ConditionType]map[ConditionVariable][]string `json:",omitempty"`
+}
+
+// ResourceList returns the resources of the statement, Resource followed by
+// Resources
+func (s *Statement) ResourceList() []string {
+	if s.Resource == "" {
+		return s.Resources
+	}
+	return append([]string{s.Resource}, s.Resources...)
+}
+
+// MarshalJSON implements the json.Marshaler interface. Empty principals, lists
+// and conditions are left out, AWS rejects documents containing them.
+func (s Statement) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Sid          *string `json:",omitempty"`
+		Effect       Effect
+		Principal    *Principal                                       `json:",omitempty"`
+		NotPrincipal *Principal                                       `json:",omitempty"`
+		Action       []string                                         `json:",omitempty"`
+		NotAction    []string                                         `json:",omitempty"`
+		Resource     interface{}                                      `json:",omitempty"`
+		NotResource  []string                                         `json:",omitempty"`
+		Condition    map[ConditionType]map[ConditionVariable][]string `json:",omitempty"`
+	}{
+		Sid:         s.Sid,
+		Effect:      s.Effect,
+		Action:      s.Action,
+		NotAction:   s.NotAction,
+		NotResource: s.NotResource,
+	}
+	switch {
+	case len(s.Resources) > 0:
+		v.Resource = s.ResourceList()
+	case s.Resource != "":
+		v.Resource = s.Resource
+	}
+	if !s.Principal.empty() {
+		v.Principal = s.Principal
+	}
+	if !s.NotPrincipal.empty() {
+		v.NotPrincipal = s.NotPrincipal
+	}
+	for t, vars := range s.Condition {
+		for key, values := range vars {
+			if len(values) == 0 {
+				continue
+			}
+			if v.Condition == nil {
+				v.Condition = make(map[ConditionType]map[ConditionVariable][]string)
+			}
+			if v.Condition[t] == nil {
+				v.Condition[t] = make(map[ConditionVariable][]string)
+			}
+			v.Condition[t][key] = values
+		}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *Statement) UnmarshalJSON(b []byte) error {
+	type statement Statement
+	v := struct {
+		*statement
+		Action      stringList
+		NotAction   stringList
+		Resource    json.RawMessage
+		NotResource stringList
+		Condition   map[ConditionType]map[ConditionVariable]conditionValues
+	}{statement: (*statement)(s)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if s.Principal == nil && s.NotPrincipal == nil {
+		s.Kind = IdentityStatement
+	}
+	s.Action = []string(v.Action)
+	s.NotAction = []string(v.NotAction)
+	// A single resource is kept in Resource, a list in Resources, so the
+	// statement is marshaled in the same form
+	s.Resource, s.Resources = "", nil
+	if len(v.Resource) > 0 && string(v.Resource) != "null" {
+		if json.Unmarshal(v.Resource, &s.Resource) != nil {
+			if err := json.Unmarshal(v.Resource, &s.Resources); err != nil {
+				return err
+			}
+		}
+	}
+	s.NotResource = []string(v.NotResource)
+	s.Condition = make(map[ConditionType]map[ConditionVariable][]string, len(v.Condition))
+	for t, vars := range v.Condition {
+		s.Condition[t] = make(map[ConditionVariable][]string, len(vars))
+		for key, values := range vars {
+			if err := validateConditionValues(t, values); err != nil {
+				return err
+			}
+			s.Condition[t][key] = values
+		}
+	}
+	return nil
+}
+
+// validateConditionValues checks the values of a condition are valid for its
+// operator
+func validateConditionValues(t ConditionType, values []string) error {
+	if t.Operator() == ConditionNull {
+		for _, value := range values {
+			if !strings.EqualFold(value, "true") && !strings.EqualFold(value, "false") {
+				return InvalidConditionValueError(value)
+			}
+		}
+	}
+	return nil
+}
+
+// Statement validation error when the Sid contains characters other than
+// letters and digits
+type InvalidSidError string
+
+const sidErrorFormat = "Invalid Sid %q: only letters and digits are allowed"
+
+func (s InvalidSidError) Error() string {
+	return fmt.Sprintf(sidErrorFormat, string(s))
+}
+
+// Errors reported by Statement.Validate
+var (
+	errMissingAction       = InvalidStatementError("statement has no Action or NotAction")
+	errActionNotAction     = InvalidStatementError("statement has both Action and NotAction")
+	errMissingResource     = InvalidStatementError("statement has no Resource or NotResource")
+	errResourceNotResource = InvalidStatementError("statement has both Resource and NotResource")
+	errAllowNotPrincipal   = InvalidStatementError("NotPrincipal can not be used with Allow")
+	errIdentityPrincipal   = InvalidStatementError("identity statement contains a Principal")
+	errSCPPrincipal        = InvalidStatementError("service control policy statement contains a Principal")
+	errMissingPrincipal    = InvalidStatementError("resource statement has no Principal")
+)
+
+// ValidationErrors holds all problems found by Statement.Validate
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual errors, for use with errors.Is and errors.As
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// Validate checks that the statement can be used in a policy of its kind and
+// returns all problems found as ValidationErrors:
+//   - identity and service control statements must not contain a Principal
+//     or NotPrincipal, resource statements must name at least one
+//   - service principals must be valid, see ParseServicePrincipal
+//   - exactly one of Action and NotAction must be used
+//   - at most one of Resource and NotResource must be used, identity and
+//     service control statements need one of them
+//   - NotPrincipal can not be combined with Allow
+//   - the Sid may only contain letters and digits
+func (s *Statement) Validate() error {
+	errs := make(ValidationErrors, 0)
+	hasPrincipal := !s.Principal.empty() || !s.NotPrincipal.empty()
+	if s.Kind == IdentityStatement && hasPrincipal {
+		errs = append(errs, errIdentityPrincipal)
+	}
+	if s.Kind == ServiceControlStatement && hasPrincipal {
+		errs = append(errs, errSCPPrincipal)
+	}
+	if s.Kind == ResourceStatement && !hasPrincipal {
+		errs = append(errs, errMissingPrincipal)
+	}
+	for _, p := range []*Principal{s.Principal, s.NotPrincipal} {
+		if p == nil {
+			continue
+		}
+		for _, service := range p.Service {
+			if _, err := ParseServicePrincipal(service); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	switch {
+	case len(s.Action) == 0 && len(s.NotAction) == 0:
+		errs = append(errs, errMissingAction)
+	case len(s.Action) > 0 && len(s.NotAction) > 0:
+		errs = append(errs, errActionNotAction)
+	}
+	switch {
+	case len(s.ResourceList()) == 0 && len(s.NotResource) == 0 && s.Kind != ResourceStatement:
+		errs = append(errs, errMissingResource)
+	case len(s.ResourceList()) > 0 && len(s.NotResource) > 0:
+		errs = append(errs, errResourceNotResource)
+	}
+	if s.Effect == Allow && !s.NotPrincipal.empty() {
+		errs = append(errs, errAllowNotPrincipal)
+	}
+	if s.Sid != nil && strings.IndexFunc(*s.Sid, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) >= 0 {
+		errs = append(errs, InvalidSidError(*s.Sid))
+	}
+	errs = append(errs, s.validateVariables()...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (p *Principal) empty() bool {
+	return p == nil || len(p.Aws)+len(p.Service)+len(p.Federated)+len(p.CanonicalUser) == 0
 }
 
 // Set the Statement's Sid
@@ -165,9 +773,20 @@ func (s *Statement) SetSid(id string) {
 
 // Add an extra person to the Principal list
 func (s *Statement) AddPrincipal(p string) {
+	if s.Principal == nil {
+		s.Principal = NewPrincipal()
+	}
 	s.Principal.Aws = append(s.Principal.Aws, p)
 }
 
+// Add an AWS service to the Principal list
+func (s *Statement) AddServicePrincipal(p string) {
+	if s.Principal == nil {
+		s.Principal = NewPrincipal()
+	}
+	s.Principal.Service = append(s.Principal.Service, p)
+}
+
 // Add an extra person to the NotPrincipal list
 func (s *Statement) AddNotPrincipal(p string) {
 	if s.NotPrincipal == nil {
@@ -186,8 +805,21 @@ func (s *Statement) AddNotAction(a string) {
 	s.NotAction = append(s.NotAction, a)
 }
 
+// Add a Resource to Resources
+func (s *Statement) AddResource(r string) {
+	s.Resources = append(s.Resources, r)
+}
+
+// Add a NotResource
+func (s *Statement) AddNotResource(r string) {
+	s.NotResource = append(s.NotResource, r)
+}
+
 // Add a Condition to the statement
 func (s *Statement) AddCondition(t ConditionType, key ConditionVariable, value string) {
+	if s.Condition == nil {
+		s.Condition = make(map[ConditionType]map[ConditionVariable][]string)
+	}
 	if _, ok := s.Condition[t]; !ok {
 		s.Condition[t] = make(map[ConditionVariable][]string)
 	}
@@ -197,11 +829,135 @@ func (s *Statement) AddCondition(t ConditionType, key ConditionVariable, value s
 	s.Condition[t][key] = append(s.Condition[t][key], value)
 }
 
+// Add a Null condition to the statement, which checks whether the key is
+// absent (mustBeNull true) or present (mustBeNull false) in the request
+func (s *Statement) AddNullCondition(key ConditionVariable, mustBeNull bool) {
+	s.AddCondition(ConditionNull, key, strconv.FormatBool(mustBeNull))
+}
+
+// Add a Condition qualified with a set operator to the statement
+func (s *Statement) AddSetCondition(o ConditionSetOperator, t ConditionType, key ConditionVariable, value string) {
+	s.AddCondition(t.WithSetOperator(o), key, value)
+}
+
+// Add multiple people to the Principal list
+func (s *Statement) AddPrincipals(p ...string) {
+	for _, principal := range p {
+		s.AddPrincipal(principal)
+	}
+}
+
+// Add multiple AWS services to the Principal list
+func (s *Statement) AddServicePrincipals(p ...string) {
+	for _, principal := range p {
+		s.AddServicePrincipal(principal)
+	}
+}
+
+// Add multiple people to the NotPrincipal list
+func (s *Statement) AddNotPrincipals(p ...string) {
+	for _, principal := range p {
+		s.AddNotPrincipal(principal)
+	}
+}
+
+// Add multiple Actions
+func (s *Statement) AddActions(a ...string) {
+	s.Action = append(s.Action, a...)
+}
+
+// Add multiple NotActions
+func (s *Statement) AddNotActions(a ...string) {
+	s.NotAction = append(s.NotAction, a...)
+}
+
+// Add multiple Resources
+func (s *Statement) AddResources(r ...string) {
+	s.Resources = append(s.Resources, r...)
+}
+
+// Add multiple NotResources
+func (s *Statement) AddNotResources(r ...string) {
+	s.NotResource = append(s.NotResource, r...)
+}
+
+// Add a Condition with multiple values to the statement
+func (s *Statement) AddConditionValues(t ConditionType, key ConditionVariable, values ...string) {
+	for _, value := range values {
+		s.AddCondition(t, key, value)
+	}
+}
+
+// WithSid sets the Sid, returns the Statement for chaining
+func (s *Statement) WithSid(id string) *Statement {
+	s.SetSid(id)
+	return s
+}
+
+// WithEffect sets the Effect, returns the Statement for chaining
+func (s *Statement) WithEffect(e Effect) *Statement {
+	s.Effect = e
+	return s
+}
+
+// WithPrincipal adds a person to the Principal list, returns the Statement
+// for chaining
+func (s *Statement) WithPrincipal(p string) *Statement {
+	s.AddPrincipal(p)
+	return s
+}
+
+// WithServicePrincipal adds an AWS service to the Principal list, returns the
+// Statement for chaining
+func (s *Statement) WithServicePrincipal(p string) *Statement {
+	s.AddServicePrincipal(p)
+	return s
+}
+
+// WithNotPrincipal adds a person to the NotPrincipal list, returns the
+// Statement for chaining
+func (s *Statement) WithNotPrincipal(p string) *Statement {
+	s.AddNotPrincipal(p)
+	return s
+}
+
+// WithAction adds an Action, returns the Statement for chaining
+func (s *Statement) WithAction(a string) *Statement {
+	s.AddAction(a)
+	return s
+}
+
+// WithNotAction adds a NotAction, returns the Statement for chaining
+func (s *Statement) WithNotAction(a string) *Statement {
+	s.AddNotAction(a)
+	return s
+}
+
+// WithResource adds a Resource, returns the Statement for chaining
+func (s *Statement) WithResource(r string) *Statement {
+	s.AddResource(r)
+	return s
+}
+
+// WithNotResource adds a NotResource, returns the Statement for chaining
+func (s *Statement) WithNotResource(r string) *Statement {
+	s.AddNotResource(r)
+	return s
+}
+
+// WithCondition adds a Condition, returns the Statement for chaining
+func (s *Statement) WithCondition(t ConditionType, key ConditionVariable, value string) *Statement {
+	s.AddCondition(t, key, value)
+	return s
+}
+
 // Policy is a complete IAM Policy document
 type Policy struct {
 	Version   PolicyVersion
 	Id        *string `json:",omitempty"`
 	Statement []*Statement
+
+	codec Codec
 }
 
 // Create a new empty Policy
@@ -209,14 +965,46 @@ func NewPolicy() *Policy {
 	return &Policy{Statement: make([]*Statement, 0, 1)}
 }
 
-// Create a policy from JSON
+// Create a policy from JSON. Any document accepted by AWS is accepted, use
+// LoadPolicyStrict to also enforce a consistent style.
 func LoadPolicy(b []byte) (*Policy, error) {
-	p := Policy{}
-	err := json.Unmarshal(b, &p)
-	if err != nil {
-		return nil, err
+	return LoadPolicyWithOptions(b, ParseOptions{})
+}
+
+// statementList unmarshals the Statement element, which may also be given as a
+// single statement object
+type statementList []*Statement
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *statementList) UnmarshalJSON(b []byte) error {
+	if len(bytes.TrimSpace(b)) > 0 && bytes.TrimSpace(b)[0] == '{' {
+		stmt := &Statement{}
+		if err := json.Unmarshal(b, stmt); err != nil {
+			return err
+		}
+		*l = statementList{stmt}
+		return nil
 	}
-	return &p, nil
+	var statements []*Statement
+	if err := json.Unmarshal(b, &statements); err != nil {
+		return err
+	}
+	*l = statements
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *Policy) UnmarshalJSON(b []byte) error {
+	type policy Policy
+	v := struct {
+		*policy
+		Statement statementList
+	}{policy: (*policy)(p)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	p.Statement = []*Statement(v.Statement)
+	return nil
 }
 
 // Set the Id of a policy
@@ -235,14 +1023,82 @@ func (p *Policy) AddStatement() *Statement {
 	return statement
 }
 
+// Add a new (empty) Statement for an identity-based policy to the Policy,
+// returns the new Statement. Identity statements have no Principal.
+func (p *Policy) AddIdentityStatement() *Statement {
+	statement := &Statement{
+		Kind:      IdentityStatement,
+		Action:    make([]string, 0, 1),
+		Condition: make(map[ConditionType]map[ConditionVariable][]string),
+	}
+	p.Statement = append(p.Statement, statement)
+	return statement
+}
+
+// StatementBySid returns the first statement with the given Sid, or nil if
+// there is none
+func (p *Policy) StatementBySid(sid string) *Statement {
+	if i := p.statementIndex(sid); i >= 0 {
+		return p.Statement[i]
+	}
+	return nil
+}
+
+// RemoveStatement removes the first statement with the given Sid, returns
+// whether a statement was removed
+func (p *Policy) RemoveStatement(sid string) bool {
+	i := p.statementIndex(sid)
+	if i < 0 {
+		return false
+	}
+	p.Statement = append(p.Statement[:i], p.Statement[i+1:]...)
+	return true
+}
+
+// ReplaceStatement replaces the first statement with the given Sid by stmt,
+// keeping its position in the policy. Returns whether a statement was
+// replaced.
+func (p *Policy) ReplaceStatement(sid string, stmt *Statement) bool {
+	i := p.statementIndex(sid)
+	if i < 0 {
+		return false
+	}
+	p.Statement[i] = stmt
+	return true
+}
+
+func (p *Policy) statementIndex(sid string) int {
+	for i, stmt := range p.Statement {
+		if stmt.Sid != nil && *stmt.Sid == sid {
+			return i
+		}
+	}
+	return -1
+}
+
+// Validate checks all statements of the policy, returns the first error found
+func (p *Policy) Validate() error {
+	for _, stmt := range p.Statement {
+		if err := stmt.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Retrieve the policy as a JSON encoded string, ready for use in AWS API calls
 func (p *Policy) Get() ([]byte, error) {
-	result, err := json.Marshal(p)
+	result, err := p.getCodec().Marshal(p)
 	return result, err
 }
 
 // Retrieve the policy as a formatted JSON encoded string
 func (p *Policy) String() string {
-	result, _ := json.MarshalIndent(p, "", "    ")
-	return string(result)
+	data, err := p.getCodec().Marshal(p)
+	if err != nil {
+		return ""
+	}
+	var result bytes.Buffer
+	json.Indent(&result, data, "", "    ")
+	return result.String()
 }
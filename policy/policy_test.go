@@ -9,6 +9,7 @@ package policy
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 )
 
@@ -121,7 +122,7 @@ func TestEmptyPolicy(t *testing.T) {
 func TestEmptyStatement(t *testing.T) {
 	p := NewPolicy()
 	p.AddStatement()
-	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":[]},"Action":[],"Resource":""}]}`
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny"}]}`
 
 	assertPolicy(t, p, expected)
 }
@@ -130,7 +131,7 @@ func TestAllowStatement(t *testing.T) {
 	p := NewPolicy()
 	stmt := p.AddStatement()
 	stmt.Effect = Allow
-	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":[]},"Action":[],"Resource":""}]}`
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow"}]}`
 
 	assertPolicy(t, p, expected)
 }
@@ -139,7 +140,21 @@ func TestPrincipalStatement(t *testing.T) {
 	p := NewPolicy()
 	stmt := p.AddStatement()
 	stmt.AddPrincipal("*")
-	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":["*"]},"Action":[],"Resource":""}]}`
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":["*"]}}]}`
+
+	assertPolicy(t, p, expected)
+}
+
+func TestServicePrincipalStatement(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.AddServicePrincipal("cloudfront.amazonaws.com")
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"Service":["cloudfront.amazonaws.com"]}}]}`
+
+	assertPolicy(t, p, expected)
+
+	stmt.AddPrincipal("*")
+	expected = `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":["*"],"Service":["cloudfront.amazonaws.com"]}}]}`
 
 	assertPolicy(t, p, expected)
 }
@@ -148,7 +163,7 @@ func TestNotPrincipalStatement(t *testing.T) {
 	p := NewPolicy()
 	stmt := p.AddStatement()
 	stmt.AddNotPrincipal("*")
-	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":[]},"NotPrincipal":{"AWS":["*"]},"Action":[],"Resource":""}]}`
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","NotPrincipal":{"AWS":["*"]}}]}`
 
 	assertPolicy(t, p, expected)
 }
@@ -157,7 +172,7 @@ func TestActionStatement(t *testing.T) {
 	p := NewPolicy()
 	stmt := p.AddStatement()
 	stmt.AddAction("*")
-	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":[]},"Action":["*"],"Resource":""}]}`
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":["*"]}]}`
 
 	assertPolicy(t, p, expected)
 }
@@ -166,7 +181,7 @@ func TestNotActionStatement(t *testing.T) {
 	p := NewPolicy()
 	stmt := p.AddStatement()
 	stmt.AddNotAction("*")
-	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":[]},"Action":[],"NotAction":["*"],"Resource":""}]}`
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","NotAction":["*"]}]}`
 
 	assertPolicy(t, p, expected)
 }
@@ -175,7 +190,329 @@ func TestConditionStatement(t *testing.T) {
 	p := NewPolicy()
 	stmt := p.AddStatement()
 	stmt.AddCondition("ArnEquals", "aws:SourceArn", "arn:sns:foo")
-	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":[]},"Action":[],"Resource":"","Condition":{"ArnEquals":{"aws:SourceArn":["arn:sns:foo"]}}}]}`
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Condition":{"ArnEquals":{"aws:SourceArn":["arn:sns:foo"]}}}]}`
+
+	assertPolicy(t, p, expected)
+}
+
+func TestConditionSetOperator(t *testing.T) {
+	c := ConditionStringEquals.WithSetOperator(ForAllValues)
+	if c != "ForAllValues:StringEquals" {
+		t.Errorf("Expected ForAllValues:StringEquals got %s", c)
+	}
+	if c.SetOperator() != ForAllValues {
+		t.Errorf("Expected %s got %s", ForAllValues, c.SetOperator())
+	}
+	if c.BaseType() != ConditionStringEquals {
+		t.Errorf("Expected %s got %s", ConditionStringEquals, c.BaseType())
+	}
+	if c.WithSetOperator(ForAnyValue) != "ForAnyValue:StringEquals" {
+		t.Errorf("Expected ForAnyValue:StringEquals got %s", c.WithSetOperator(ForAnyValue))
+	}
+	if c.WithSetOperator("") != ConditionStringEquals {
+		t.Errorf("Expected %s got %s", ConditionStringEquals, c.WithSetOperator(""))
+	}
+	if ConditionStringLike.SetOperator() != "" {
+		t.Errorf("Expected no set operator got %s", ConditionStringLike.SetOperator())
+	}
+}
+
+func TestSetConditionStatement(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.AddSetCondition(ForAnyValue, ConditionStringLike, "aws:TagKeys", "team*")
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Condition":{"ForAnyValue:StringLike":{"aws:TagKeys":["team*"]}}}]}`
+
+	assertPolicy(t, p, expected)
+
+	loaded, err := LoadPolicy([]byte(expected))
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	assertPolicy(t, loaded, expected)
+}
+
+func TestConditionIfExists(t *testing.T) {
+	c := ConditionStringEquals.IfExists()
+	if c != "StringEqualsIfExists" {
+		t.Errorf("Expected StringEqualsIfExists got %s", c)
+	}
+	if c.IfExists() != c {
+		t.Errorf("Expected %s got %s", c, c.IfExists())
+	}
+	if !c.IsIfExists() || ConditionStringEquals.IsIfExists() {
+		t.Error("IsIfExists returned wrong result")
+	}
+	c = ConditionArnLike.WithSetOperator(ForAllValues).IfExists()
+	if c != "ForAllValues:ArnLikeIfExists" {
+		t.Errorf("Expected ForAllValues:ArnLikeIfExists got %s", c)
+	}
+	if c.Operator() != ConditionArnLike {
+		t.Errorf("Expected %s got %s", ConditionArnLike, c.Operator())
+	}
+}
+
+func TestConditionTypeValid(t *testing.T) {
+	valid := []ConditionType{
+		ConditionStringEquals,
+		"StringEqualsIfExists",
+		"ForAnyValue:StringLike",
+		"ForAllValues:NumericLessThanIfExists",
+		ConditionNull,
+	}
+	for _, c := range valid {
+		if !c.Valid() {
+			t.Errorf("Expected %s to be valid", c)
+		}
+	}
+	invalid := []ConditionType{
+		"",
+		"StringEqual",
+		"StringEqualsIfExistsIfExists",
+		"ForSomeValues:StringEquals",
+		"NullIfExists",
+	}
+	for _, c := range invalid {
+		if c.Valid() {
+			t.Errorf("Expected %s to be invalid", c)
+		}
+	}
+}
+
+func TestConditionTypeUnmarshal(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["*"],"Resource":["*"],"Condition":{"StringEqualsIfExists":{"aws:username":["bob"]}}}]}`)
+	p, err := LoadPolicy(data)
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	assertPolicy(t, p, string(data))
+
+	data = []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["*"],"Resource":["*"],"Condition":{"StringEqualz":{"aws:username":["bob"]}}}]}`)
+	_, err = LoadPolicy(data)
+	var typeErr InvalidConditionTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("Expected InvalidConditionTypeError got %v", err)
+	}
+}
+
+func TestConditionValueCoercion(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["*"],"Resource":["*"],"Condition":{"Bool":{"aws:SecureTransport":true},"NumericLessThan":{"aws:MultiFactorAuthAge":[3600,"7200.5"]},"IpAddress":{"aws:SourceIp":"10.0.0.0/8"}}}]}`)
+	p, err := LoadPolicy(data)
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["*"],"Resource":["*"],"Condition":{"Bool":{"aws:SecureTransport":["true"]},"IpAddress":{"aws:SourceIp":["10.0.0.0/8"]},"NumericLessThan":{"aws:MultiFactorAuthAge":["3600","7200.5"]}}}]}`
+	assertPolicy(t, p, expected)
+
+	data = []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["*"],"Resource":["*"],"Condition":{"Bool":{"aws:SecureTransport":{"a":"b"}}}}]}`)
+	_, err = LoadPolicy(data)
+	var valueErr InvalidConditionValueError
+	if !errors.As(err, &valueErr) {
+		t.Errorf("Expected InvalidConditionValueError got %v", err)
+	}
+}
+
+func TestIdentityStatement(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddIdentityStatement()
+	stmt.Effect = Allow
+	stmt.AddAction("s3:GetObject")
+	stmt.AddResource("*")
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["*"]}]}`
+
+	assertPolicy(t, p, expected)
+
+	if err := p.Validate(); err != nil {
+		t.Errorf("Failed validating identity statement: %s", err)
+	}
+
+	stmt.AddPrincipal("*")
+	var stmtErr InvalidStatementError
+	if !errors.As(p.Validate(), &stmtErr) {
+		t.Errorf("Expected InvalidStatementError got %v", p.Validate())
+	}
+}
+
+func TestResourceStatementValidate(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.AddAction("sts:AssumeRole")
+	var stmtErr InvalidStatementError
+	if !errors.As(stmt.Validate(), &stmtErr) {
+		t.Errorf("Expected InvalidStatementError got %v", stmt.Validate())
+	}
+
+	stmt.AddNotPrincipal("arn:aws:iam::123456789012:root")
+	if err := stmt.Validate(); err != nil {
+		t.Errorf("Failed validating resource statement: %s", err)
+	}
+}
+
+func TestLoadStatementKind(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["*"],"Resource":["*"]},{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["*"],"Resource":["*"]}]}`)
+
+	p, err := LoadPolicy(data)
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	if p.Statement[0].Kind != IdentityStatement || p.Statement[1].Kind != ResourceStatement {
+		t.Errorf("Expected identity and resource statement got %v and %v", p.Statement[0].Kind, p.Statement[1].Kind)
+	}
+	assertPolicy(t, p, string(data))
+}
+
+func TestNullCondition(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.AddNullCondition("aws:TokenIssueTime", true)
+	stmt.AddNullCondition(VarSourceIp, false)
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Condition":{"Null":{"aws:SourceIp":["false"],"aws:TokenIssueTime":["true"]}}}]}`
 
 	assertPolicy(t, p, expected)
 }
+
+func TestNullConditionUnmarshal(t *testing.T) {
+	data := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":["*"],"Resource":["*"],"Condition":{"Null":{"aws:TokenIssueTime":[true, "False"]}}}]}`)
+	if _, err := LoadPolicy(data); err != nil {
+		t.Errorf("Failed loading policy: %s", err)
+	}
+
+	data = []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":["*"],"Resource":["*"],"Condition":{"Null":{"aws:TokenIssueTime":["yes"]}}}]}`)
+	_, err := LoadPolicy(data)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Err != InvalidConditionValueError("yes") {
+		t.Fatalf("Expected InvalidConditionValueError got %v", err)
+	}
+	if parseErr.Path != "Statement[0].Condition.Null.aws:TokenIssueTime" {
+		t.Errorf("Expected error at Statement[0].Condition.Null.aws:TokenIssueTime got %s", parseErr.Path)
+	}
+}
+
+func TestTextEncoding(t *testing.T) {
+	var e Effect
+	if err := e.UnmarshalText([]byte("Allow")); err != nil || e != Allow {
+		t.Errorf("Expected Allow got %s (%v)", e, err)
+	}
+	if err := e.UnmarshalText([]byte("allow")); err != InvalidEffectError("allow") {
+		t.Errorf("Expected InvalidEffectError got %v", err)
+	}
+	if text, _ := Deny.MarshalText(); string(text) != "Deny" {
+		t.Errorf("Expected Deny got %s", text)
+	}
+
+	if _, err := ConditionType("StringEqual").MarshalText(); err != InvalidConditionTypeError("StringEqual") {
+		t.Errorf("Expected InvalidConditionTypeError got %v", err)
+	}
+	if text, _ := ConditionStringEquals.IfExists().MarshalText(); string(text) != "StringEqualsIfExists" {
+		t.Errorf("Expected StringEqualsIfExists got %s", text)
+	}
+
+	var v ConditionVariable
+	if err := v.UnmarshalText([]byte("aws:SourceIp")); err != nil || v != VarSourceIp {
+		t.Errorf("Expected %s got %s (%v)", VarSourceIp, v, err)
+	}
+	if err := v.UnmarshalText(nil); err != InvalidConditionVariableError("") {
+		t.Errorf("Expected InvalidConditionVariableError got %v", err)
+	}
+}
+
+func TestTagConditionVariables(t *testing.T) {
+	tests := []struct {
+		got      ConditionVariable
+		expected string
+	}{
+		{RequestTag("Team"), "aws:RequestTag/Team"},
+		{ResourceTag("cost-center"), "aws:ResourceTag/cost-center"},
+		{PrincipalTag("team"), "aws:PrincipalTag/team"},
+	}
+
+	for _, test := range tests {
+		if string(test.got) != test.expected {
+			t.Errorf("Expected %s got %s", test.expected, test.got)
+		}
+	}
+}
+
+func TestChainedStatement(t *testing.T) {
+	p := NewPolicy()
+	p.AddIdentityStatement().WithSid("Read").WithEffect(Allow).WithAction("s3:GetObject").WithResource("arn:aws:s3:::bucket/*").WithCondition(ConditionBool, VarSecureTransport, "true")
+	expected := `{"Version":"2012-10-17","Statement":[{"Sid":"Read","Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"],"Condition":{"Bool":{"aws:SecureTransport":["true"]}}}]}`
+
+	assertPolicy(t, p, expected)
+}
+
+func TestStatementBySid(t *testing.T) {
+	p := NewPolicy()
+	for _, sid := range []string{"a", "b", "c"} {
+		p.AddIdentityStatement().WithSid(sid).WithAction("s3:" + sid)
+	}
+
+	if stmt := p.StatementBySid("b"); stmt == nil || stmt.Action[0] != "s3:b" {
+		t.Errorf("Expected statement b got %v", stmt)
+	}
+	if stmt := p.StatementBySid("d"); stmt != nil {
+		t.Errorf("Expected no statement got %v", stmt)
+	}
+
+	replacement := (&Statement{Kind: IdentityStatement}).WithSid("B").WithAction("s3:B")
+	if !p.ReplaceStatement("b", replacement) || p.Statement[1] != replacement {
+		t.Errorf("Expected statement b to be replaced")
+	}
+	if p.ReplaceStatement("b", replacement) {
+		t.Errorf("Expected no statement b to replace")
+	}
+
+	if !p.RemoveStatement("a") || len(p.Statement) != 2 || *p.Statement[0].Sid != "B" {
+		t.Errorf("Expected statement a to be removed")
+	}
+	if p.RemoveStatement("a") {
+		t.Errorf("Expected no statement a to remove")
+	}
+}
+
+func TestBulkAdd(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.AddPrincipals("arn:aws:iam::111122223333:root", "arn:aws:iam::444455556666:root")
+	stmt.AddActions("s3:GetObject", "s3:PutObject")
+	stmt.AddResources("arn:aws:s3:::a/*", "arn:aws:s3:::b/*")
+	stmt.AddConditionValues(ConditionStringEquals, PrincipalTag("team"), "red", "blue")
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":["arn:aws:iam::111122223333:root","arn:aws:iam::444455556666:root"]},"Action":["s3:GetObject","s3:PutObject"],"Resource":["arn:aws:s3:::a/*","arn:aws:s3:::b/*"],"Condition":{"StringEquals":{"aws:PrincipalTag/team":["red","blue"]}}}]}`
+
+	assertPolicy(t, p, expected)
+}
+
+func TestValidateErrors(t *testing.T) {
+	stmt := NewStatement(
+		WithSid("read-only"),
+		WithEffect(Allow),
+		WithActions("s3:GetObject"),
+		WithNotActions("s3:PutObject"),
+		WithResources("*"),
+		WithNotResources("arn:aws:s3:::secret/*"),
+	)
+	stmt.Kind = ResourceStatement
+	stmt.AddNotPrincipal("arn:aws:iam::123456789012:root")
+
+	var errs ValidationErrors
+	if !errors.As(stmt.Validate(), &errs) {
+		t.Fatalf("Expected ValidationErrors got %v", stmt.Validate())
+	}
+	expected := ValidationErrors{errActionNotAction, errResourceNotResource, errAllowNotPrincipal, InvalidSidError("read-only")}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %v got %v", expected, errs)
+	}
+	for i := range expected {
+		if errs[i] != expected[i] {
+			t.Errorf("Expected %v got %v", expected[i], errs[i])
+		}
+	}
+	if !errors.Is(stmt.Validate(), errAllowNotPrincipal) {
+		t.Errorf("Expected errors.Is to find the NotPrincipal error")
+	}
+
+	stmt = NewStatement(WithSid("ReadOnly1"), WithActions("s3:GetObject"))
+	if !errors.Is(stmt.Validate(), errMissingResource) {
+		t.Errorf("Expected missing Resource error got %v", stmt.Validate())
+	}
+}
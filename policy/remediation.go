@@ -0,0 +1,146 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Placeholders used in remediations where the correct value can not be
+// derived from the statement
+const (
+	placeholderAction    = "<service>:<action>"
+	placeholderResource  = "arn:aws:<service>:<region>:<account>:<resource>"
+	placeholderPrincipal = "arn:aws:iam::<account>:root"
+)
+
+const userGuide = "https://docs.aws.amazon.com/IAM/latest/UserGuide/"
+
+var findingDocs = map[FindingCode]string{
+	CodeInvalidStatement:       userGuide + "reference_policies_elements_principal.html",
+	CodeMissingAction:          userGuide + "reference_policies_elements_action.html",
+	CodeMissingResource:        userGuide + "reference_policies_elements_resource.html",
+	CodeWildcardAction:         userGuide + "best-practices.html#grant-least-privilege",
+	CodeServiceWildcardAction:  userGuide + "best-practices.html#grant-least-privilege",
+	CodeWildcardResource:       userGuide + "reference_policies_elements_resource.html",
+	CodeWildcardPrincipal:      userGuide + "reference_policies_elements_principal.html",
+	CodeAllowNotAction:         userGuide + "reference_policies_elements_notaction.html",
+	CodeAllowNotResource:       userGuide + "reference_policies_elements_notresource.html",
+	CodeDeadConditionKey:       userGuide + "reference_policies_condition-keys.html",
+	CodeContradictoryCondition: userGuide + "reference_policies_elements_condition_operators.html",
+}
+
+// finding creates a Finding for the statement. The remediation is created by
+// applying fix to a copy of the statement, fix may be nil if there is none.
+func (s *Statement) finding(code FindingCode, severity Severity, fix func(*Statement), format string, args ...interface{}) Finding {
+	f := Finding{
+		Code:     code,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		DocURL:   findingDocs[code],
+		format:   format,
+		args:     args,
+	}
+	if fix != nil {
+		c := s.Clone()
+		fix(c)
+		if data, err := json.Marshal(c); err == nil {
+			f.Remediation = string(data)
+		}
+	}
+	return f
+}
+
+// validationDoc returns the documentation of the policy element an error
+// reported by Statement.Validate is about
+func validationDoc(err error) string {
+	switch err {
+	case errActionNotAction:
+		return userGuide + "reference_policies_elements_notaction.html"
+	case errResourceNotResource:
+		return userGuide + "reference_policies_elements_notresource.html"
+	case errAllowNotPrincipal:
+		return userGuide + "reference_policies_elements_notprincipal.html"
+	}
+	if _, ok := err.(*InvalidPolicyVariableError); ok {
+		return userGuide + "reference_policies_variables.html"
+	}
+	if _, ok := err.(InvalidSidError); ok {
+		return userGuide + "reference_policies_elements_sid.html"
+	}
+	return findingDocs[CodeInvalidStatement]
+}
+
+// validationFix returns the remediation for an error reported by
+// Statement.Validate
+func validationFix(err error) func(*Statement) {
+	switch err {
+	case errActionNotAction:
+		return func(s *Statement) { s.NotAction = nil }
+	case errResourceNotResource:
+		return func(s *Statement) { s.NotResource = nil }
+	case errAllowNotPrincipal:
+		return func(s *Statement) {
+			s.NotPrincipal = nil
+			if s.Principal.empty() {
+				s.AddPrincipal(placeholderPrincipal)
+			}
+		}
+	}
+	if e, ok := err.(*InvalidPolicyVariableError); ok {
+		return removeVariable(e)
+	}
+	if _, ok := err.(InvalidSidError); ok {
+		return func(s *Statement) {
+			s.SetSid(strings.Map(func(r rune) rune {
+				if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+					return r
+				}
+				return -1
+			}, *s.Sid))
+		}
+	}
+	return fixPrincipals
+}
+
+// fixPrincipals makes the principals match the statement kind: identity and
+// service control statements lose their principals, resource statements get
+// a placeholder. Invalid service principals are dropped.
+func fixPrincipals(s *Statement) {
+	if s.Kind != ResourceStatement {
+		s.Principal = nil
+		s.NotPrincipal = nil
+		return
+	}
+	for _, p := range []*Principal{s.Principal, s.NotPrincipal} {
+		if p == nil {
+			continue
+		}
+		valid := make([]string, 0, len(p.Service))
+		for _, service := range p.Service {
+			if _, err := ParseServicePrincipal(service); err == nil {
+				valid = append(valid, service)
+			}
+		}
+		p.Service = valid
+	}
+	if s.Principal.empty() && s.NotPrincipal.empty() {
+		s.AddPrincipal(placeholderPrincipal)
+	}
+}
+
+func index(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,163 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// MarshalOptions controls how Render formats a policy. The zero value
+// produces the same output as Get.
+type MarshalOptions struct {
+	// Indentation used for each nesting level, no indentation is added when
+	// empty
+	Indent string
+	// Emit lists that hold a single value as that value, e.g. "Action":
+	// "s3:*" instead of "Action": ["s3:*"]. The Statement list is never
+	// collapsed.
+	CollapseScalars bool
+	// Emit object keys in alphabetical order instead of grammar order
+	SortKeys bool
+	// Do not escape <, > and & in strings
+	DisableHTMLEscape bool
+}
+
+// Retrieve the policy as a JSON encoded string formatted according to opts
+func (p *Policy) Render(opts MarshalOptions) ([]byte, error) {
+	data, err := p.getCodec().Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := readNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := root.write(&buf, "", opts); err != nil {
+		return nil, err
+	}
+	if opts.Indent == "" {
+		return buf.Bytes(), nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", opts.Indent); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// renderNode is a decoded JSON value that keeps the order of object keys
+type renderNode struct {
+	// Scalar value, nil for arrays and objects
+	value    json.Token
+	array    []*renderNode
+	isArray  bool
+	keys     []string
+	children []*renderNode
+	isObject bool
+}
+
+func readNode(dec *json.Decoder) (*renderNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('['):
+		n := &renderNode{isArray: true, array: make([]*renderNode, 0)}
+		for dec.More() {
+			child, err := readNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.array = append(n.array, child)
+		}
+		_, err := dec.Token()
+		return n, err
+	case json.Delim('{'):
+		n := &renderNode{isObject: true}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			child, err := readNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.keys = append(n.keys, key.(string))
+			n.children = append(n.children, child)
+		}
+		_, err := dec.Token()
+		return n, err
+	}
+	return &renderNode{value: tok}, nil
+}
+
+func (n *renderNode) write(w *bytes.Buffer, key string, opts MarshalOptions) error {
+	switch {
+	case n.isArray:
+		if opts.CollapseScalars && key != "Statement" && len(n.array) == 1 {
+			if _, ok := n.array[0].value.(string); ok {
+				return n.array[0].write(w, "", opts)
+			}
+		}
+		w.WriteByte('[')
+		for i, child := range n.array {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := child.write(w, "", opts); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case n.isObject:
+		order := make([]int, len(n.keys))
+		for i := range order {
+			order[i] = i
+		}
+		if opts.SortKeys {
+			sort.SliceStable(order, func(i, j int) bool { return n.keys[order[i]] < n.keys[order[j]] })
+		}
+		w.WriteByte('{')
+		for i, idx := range order {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeScalar(w, n.keys[idx], opts); err != nil {
+				return err
+			}
+			w.WriteByte(':')
+			if err := n.children[idx].write(w, n.keys[idx], opts); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+	default:
+		return writeScalar(w, n.value, opts)
+	}
+	return nil
+}
+
+func writeScalar(w io.Writer, v interface{}, opts MarshalOptions) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(!opts.DisableHTMLEscape)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
@@ -0,0 +1,59 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	p := NewPolicy()
+	stmt := p.AddStatement()
+	stmt.Effect = Allow
+	stmt.AddPrincipal("*")
+	stmt.AddAction("s3:GetObject")
+	stmt.AddAction("s3:PutObject")
+	stmt.AddResource("arn:aws:s3:::bucket/<prefix>/*")
+	stmt.AddCondition(ConditionBool, VarSecureTransport, "true")
+
+	tests := []struct {
+		opts     MarshalOptions
+		expected string
+	}{
+		{
+			MarshalOptions{},
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject","s3:PutObject"],"Resource":["arn:aws:s3:::bucket/\u003cprefix\u003e/*"],"Condition":{"Bool":{"aws:SecureTransport":["true"]}}}]}`,
+		},
+		{
+			MarshalOptions{CollapseScalars: true, DisableHTMLEscape: true},
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"*"},"Action":["s3:GetObject","s3:PutObject"],"Resource":"arn:aws:s3:::bucket/<prefix>/*","Condition":{"Bool":{"aws:SecureTransport":"true"}}}]}`,
+		},
+		{
+			MarshalOptions{SortKeys: true, CollapseScalars: true},
+			`{"Statement":[{"Action":["s3:GetObject","s3:PutObject"],"Condition":{"Bool":{"aws:SecureTransport":"true"}},"Effect":"Allow","Principal":{"AWS":"*"},"Resource":"arn:aws:s3:::bucket/\u003cprefix\u003e/*"}],"Version":"2012-10-17"}`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := p.Render(test.opts)
+		if err != nil {
+			t.Fatalf("Failed rendering policy: %s", err)
+		}
+		if string(got) != test.expected {
+			t.Errorf("Expected \n%s got \n%s", test.expected, got)
+		}
+	}
+
+	got, err := p.Render(MarshalOptions{Indent: "    "})
+	if err != nil {
+		t.Fatalf("Failed rendering policy: %s", err)
+	}
+	if string(got) != p.String() {
+		t.Errorf("Expected \n%s got \n%s", p.String(), got)
+	}
+}
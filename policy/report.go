@@ -0,0 +1,104 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// ServiceUsage summarizes how a set of policies references a single service
+type ServiceUsage struct {
+	// The service prefix, e.g. s3, or * for statements matching all actions
+	Service string
+	// Number of Allow and Deny statements referencing the service
+	Allow, Deny int
+	// Number of statements referencing the service with a wildcard action,
+	// e.g. s3:* or s3:Get*, or through NotAction
+	Wildcard int
+	// Principals of the resource statements referencing the service
+	Principals []string
+}
+
+// ServiceReport lists the services referenced by a set of policies, sorted by
+// service prefix
+type ServiceReport []ServiceUsage
+
+// NewServiceReport creates a ServiceReport over the statements of the
+// policies. Services are taken from the Action and NotAction elements, a
+// statement is counted once per service.
+func NewServiceReport(policies ...*Policy) ServiceReport {
+	usage := make(map[string]*ServiceUsage)
+	principals := make(map[string]map[string]bool)
+	for _, p := range policies {
+		for _, stmt := range p.Statement {
+			wildcards := make(map[string]bool)
+			for _, action := range stmt.Action {
+				service, name, _ := strings.Cut(strings.ToLower(action), ":")
+				wildcards[service] = wildcards[service] || strings.ContainsAny(name, "*?") || action == "*"
+			}
+			for _, action := range stmt.NotAction {
+				service, _, _ := strings.Cut(strings.ToLower(action), ":")
+				wildcards[service] = true
+			}
+			for service, wildcard := range wildcards {
+				u, ok := usage[service]
+				if !ok {
+					u = &ServiceUsage{Service: service}
+					usage[service] = u
+					principals[service] = make(map[string]bool)
+				}
+				if stmt.Effect == Allow {
+					u.Allow++
+				} else {
+					u.Deny++
+				}
+				if wildcard {
+					u.Wildcard++
+				}
+				for _, principal := range []*Principal{stmt.Principal, stmt.NotPrincipal} {
+					if principal == nil {
+						continue
+					}
+					for _, list := range [][]string{principal.Aws, principal.Service, principal.Federated, principal.CanonicalUser} {
+						for _, name := range list {
+							principals[service][name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	report := make(ServiceReport, 0, len(usage))
+	for service, u := range usage {
+		u.Principals = make([]string, 0, len(principals[service]))
+		for name := range principals[service] {
+			u.Principals = append(u.Principals, name)
+		}
+		sort.Strings(u.Principals)
+		report = append(report, *u)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Service < report[j].Service })
+	return report
+}
+
+// String renders the report as a table
+func (r ServiceReport) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tALLOW\tDENY\tWILDCARD\tPRINCIPALS")
+	for _, u := range r {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", u.Service, u.Allow, u.Deny, u.Wildcard, len(u.Principals))
+	}
+	w.Flush()
+	return buf.String()
+}
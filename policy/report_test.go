@@ -0,0 +1,42 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestServiceReport(t *testing.T) {
+	bucket := Build().
+		Allow().Principal("arn:aws:iam::111122223333:root").Actions("s3:GetObject", "s3:List*").Resources("arn:aws:s3:::bucket").
+		Deny().Principal("*").Actions("s3:*").Resources("arn:aws:s3:::bucket").
+		Done()
+	role := Build().
+		Allow().Actions("S3:GetObject", "kms:Decrypt").Resources("*").
+		Deny().NotActions("iam:*").Resources("*").
+		Done()
+
+	report := NewServiceReport(bucket, role)
+	expected := ServiceReport{
+		{Service: "iam", Deny: 1, Wildcard: 1, Principals: []string{}},
+		{Service: "kms", Allow: 1, Principals: []string{}},
+		{Service: "s3", Allow: 2, Deny: 1, Wildcard: 2, Principals: []string{"*", "arn:aws:iam::111122223333:root"}},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Expected %v got %v", expected, report)
+	}
+
+	table := "SERVICE  ALLOW  DENY  WILDCARD  PRINCIPALS\n" +
+		"iam      0      1     1         0\n" +
+		"kms      1      0     0         0\n" +
+		"s3       2      1     2         2\n"
+	if report.String() != table {
+		t.Errorf("Expected \n%s got \n%s", table, report.String())
+	}
+}
@@ -0,0 +1,107 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"strings"
+)
+
+// Deduction is a reason a statement lost points in a Score
+type Deduction struct {
+	// Index of the statement in the policy
+	Statement int
+	Reason    string
+	Points    int
+}
+
+// Score rates how closely a policy follows least privilege, from 0 for
+// unrestricted access to 100
+type Score struct {
+	Value      int
+	Deductions []Deduction
+}
+
+// Points deducted from a statement by the privilege score rubric
+const (
+	scoreAllActions        = 40
+	scoreServiceActions    = 20
+	scoreActionWildcard    = 5
+	scoreNotAction         = 30
+	scoreAllResources      = 15
+	scoreNotResource       = 15
+	scoreNoCondition       = 5
+	scorePublic            = 40
+	scoreConditionalPublic = 10
+)
+
+// PrivilegeScore rates the policy on a scale from 0 to 100. Every Allow
+// statement starts at 100 and loses points for:
+//
+//	all actions (*)                      40
+//	all actions of a service (s3:*)      20 per service
+//	other action wildcards (s3:Get*)      5 per action
+//	NotAction                            30
+//	all resources (*)                    15
+//	NotResource                          15
+//	no conditions                         5
+//	public principal (*)                 40, or 10 with conditions
+//
+// The policy score is the score of its worst statement, a statement scores
+// at least 0. Deny statements do not affect the score.
+func (p *Policy) PrivilegeScore() Score {
+	score := Score{Value: 100, Deductions: make([]Deduction, 0)}
+	for i, stmt := range p.Statement {
+		if stmt.Effect != Allow {
+			continue
+		}
+		value := 100
+		deduct := func(points int, reason string) {
+			score.Deductions = append(score.Deductions, Deduction{i, reason, points})
+			value -= points
+		}
+
+		for _, action := range stmt.Action {
+			_, name, _ := strings.Cut(action, ":")
+			switch {
+			case action == "*":
+				deduct(scoreAllActions, "allows all actions")
+			case name == "*":
+				deduct(scoreServiceActions, "allows all actions of "+action[:len(action)-2])
+			case strings.ContainsAny(name, "*?"):
+				deduct(scoreActionWildcard, "allows actions matching "+action)
+			}
+		}
+		if len(stmt.NotAction) > 0 {
+			deduct(scoreNotAction, "allows all actions except NotAction")
+		}
+		if contains(stmt.ResourceList(), "*") {
+			deduct(scoreAllResources, "applies to all resources")
+		}
+		if len(stmt.NotResource) > 0 {
+			deduct(scoreNotResource, "applies to all resources except NotResource")
+		}
+		if len(stmt.Condition) == 0 {
+			deduct(scoreNoCondition, "has no conditions")
+		}
+		if stmt.Principal != nil && contains(stmt.Principal.Aws, "*") {
+			if len(stmt.Condition) == 0 {
+				deduct(scorePublic, "allows everyone")
+			} else {
+				deduct(scoreConditionalPublic, "allows everyone matching its conditions")
+			}
+		}
+
+		if value < 0 {
+			value = 0
+		}
+		if value < score.Value {
+			score.Value = value
+		}
+	}
+	return score
+}
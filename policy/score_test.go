@@ -0,0 +1,54 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestPrivilegeScore(t *testing.T) {
+	tests := []struct {
+		policy     *Policy
+		value      int
+		deductions int
+	}{
+		{
+			Build().Allow().Actions("s3:GetObject").Resources("arn:aws:s3:::bucket/*").Condition(ConditionBool, VarSecureTransport, "true").Done(),
+			100, 0,
+		},
+		{
+			Build().Allow().Actions("s3:Get*", "s3:List*").Resources("arn:aws:s3:::bucket/*").Done(),
+			85, 3,
+		},
+		{
+			Build().
+				Allow().Actions("s3:GetObject").Resources("arn:aws:s3:::bucket/*").Condition(ConditionBool, VarSecureTransport, "true").
+				Allow().Principal("*").Actions("s3:*").Resources("*").Done(),
+			20, 4,
+		},
+		{
+			Build().Allow().Actions("*").NotActions("iam:*").Resources("*").Done(),
+			10, 4,
+		},
+		{
+			Build().Allow().Principal("*").Actions("*").Resources("*").Done(),
+			0, 4,
+		},
+		{
+			Build().Deny().Actions("*").Resources("*").Done(),
+			100, 0,
+		},
+	}
+
+	for i, test := range tests {
+		score := test.policy.PrivilegeScore()
+		if score.Value != test.value || len(score.Deductions) != test.deductions {
+			t.Errorf("%d: Expected score %d with %d deductions got %d with %v", i, test.value, test.deductions, score.Value, score.Deductions)
+		}
+	}
+}
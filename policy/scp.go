@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+// SCP is an AWS Organizations service control policy. SCPs use the policy
+// grammar without the Principal and NotPrincipal elements, as they apply to
+// all principals of the accounts they are attached to.
+type SCP struct {
+	*Policy
+}
+
+// Create a new empty service control policy
+func NewSCP() *SCP {
+	return &SCP{NewPolicy()}
+}
+
+// Create a service control policy from JSON, the policy is validated
+func LoadSCP(b []byte) (*SCP, error) {
+	p, err := LoadPolicy(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range p.Statement {
+		stmt.Kind = ServiceControlStatement
+	}
+	scp := &SCP{p}
+	if err := scp.Validate(); err != nil {
+		return nil, err
+	}
+	return scp, nil
+}
+
+// Add a new (empty) Statement to the policy, returns the new Statement
+func (p *SCP) AddStatement() *Statement {
+	statement := p.Policy.AddIdentityStatement()
+	statement.Kind = ServiceControlStatement
+	return statement
+}
+
+// Validate checks that no statement contains a Principal or NotPrincipal and
+// that the policy does not exceed the service control policy size limit
+func (p *SCP) Validate() error {
+	for _, stmt := range p.Statement {
+		if stmt.Kind != ServiceControlStatement {
+			return InvalidStatementError("statement is not a service control policy statement")
+		}
+	}
+	if err := p.Policy.Validate(); err != nil {
+		return err
+	}
+	_, err := p.Policy.GetWithLimits(ServiceControlPolicy)
+	return err
+}
+
+// Retrieve the policy as a JSON encoded string, returns an error if the
+// policy is not valid
+func (p *SCP) Get() ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p.Policy.Get()
+}
@@ -0,0 +1,51 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSCP(t *testing.T) {
+	p := NewSCP()
+	stmt := p.AddStatement()
+	stmt.SetSid("DenyLeaveOrganization")
+	stmt.AddAction("organizations:LeaveOrganization")
+	stmt.AddResource("*")
+	expected := `{"Version":"2012-10-17","Statement":[{"Sid":"DenyLeaveOrganization","Effect":"Deny","Action":["organizations:LeaveOrganization"],"Resource":["*"]}]}`
+
+	got, err := p.Get()
+	if err != nil {
+		t.Fatalf("Failed getting policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+
+	stmt.AddPrincipal("*")
+	if _, err := p.Get(); err == nil {
+		t.Error("Expected error for statement with a Principal")
+	}
+}
+
+func TestLoadSCP(t *testing.T) {
+	p, err := LoadSCP([]byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"ec2:*","Resource":"*","Condition":{"StringNotEquals":{"aws:RequestedRegion":["eu-west-1"]}}}]}`))
+	if err != nil {
+		t.Fatalf("Failed loading policy: %s", err)
+	}
+	if p.Statement[0].Kind != ServiceControlStatement {
+		t.Errorf("Expected ServiceControlStatement got %v", p.Statement[0].Kind)
+	}
+
+	_, err = LoadSCP([]byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":"*","Action":"ec2:*","Resource":"*"}]}`))
+	var stmtErr InvalidStatementError
+	if !errors.As(err, &stmtErr) {
+		t.Errorf("Expected InvalidStatementError got %v", err)
+	}
+}
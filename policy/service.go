@@ -0,0 +1,125 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Validation error when a Service principal is not a valid service principal
+type InvalidServicePrincipalError struct {
+	Principal string
+	Reason    string
+
+	// Reason format and arguments, used to localize the message
+	format string
+	args   []interface{}
+}
+
+const servicePrincipalErrorFormat = "Invalid Service Principal %s: "
+
+func (e *InvalidServicePrincipalError) Error() string {
+	return fmt.Sprintf(servicePrincipalErrorFormat+"%s", e.Principal, e.Reason)
+}
+
+// Reasons a service principal is invalid
+const (
+	reasonDomain    = "not in the domain of an AWS partition"
+	reasonLabel     = "invalid label %q"
+	reasonFIPS      = "FIPS endpoints are not service principals"
+	reasonNoService = "missing service name"
+)
+
+// ServicePrincipal is a parsed service principal, e.g. lambda.amazonaws.com
+// or the regional states.eu-west-1.amazonaws.com
+type ServicePrincipal struct {
+	// The service part of the principal, which may contain dots, e.g.
+	// delivery.logs
+	Service string
+	// The region of a regional service principal, empty for global ones
+	Region string
+	// The domain suffix of the partition, e.g. amazonaws.com, or
+	// amazonaws.com.cn for the China regions
+	Domain string
+}
+
+// Service principal domains by partition: aws and aws-us-gov, aws-cn,
+// aws-iso, aws-iso-b, aws-iso-e, aws-iso-f and aws-eusc. Suffixes of other
+// domains must come after them.
+var servicePrincipalDomains = []string{
+	"amazonaws.com.cn",
+	"amazonaws.com",
+	"c2s.ic.gov",
+	"sc2s.sgov.gov",
+	"cloud.adc-e.uk",
+	"csp.hci.ic.gov",
+	"amazonaws.eu",
+}
+
+var (
+	regionPattern       = regexp.MustCompile(`^([a-z]{2}|eusc-[a-z]{2})(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`)
+	serviceLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+)
+
+// ParseServicePrincipal parses a service principal. Besides the global form
+// <service>.amazonaws.com it accepts regional principals of the form
+// <service>.<region>.amazonaws.com and principals in the other partitions,
+// such as amazonaws.com.cn for China and c2s.ic.gov or sc2s.sgov.gov for the
+// ISO partitions, see servicePrincipalDomains. FIPS endpoint host names such as
+// s3-fips.us-east-1.amazonaws.com are rejected, service principals are the
+// same for FIPS and non-FIPS endpoints.
+func ParseServicePrincipal(s string) (ServicePrincipal, error) {
+	invalid := func(format string, args ...interface{}) (ServicePrincipal, error) {
+		return ServicePrincipal{}, &InvalidServicePrincipalError{Principal: s, Reason: fmt.Sprintf(format, args...), format: format, args: args}
+	}
+
+	var p ServicePrincipal
+	for _, domain := range servicePrincipalDomains {
+		if strings.HasSuffix(s, "."+domain) {
+			p.Domain = domain
+			break
+		}
+	}
+	if p.Domain == "" {
+		return invalid(reasonDomain)
+	}
+
+	labels := strings.Split(strings.TrimSuffix(s, "."+p.Domain), ".")
+	for _, label := range labels {
+		if !serviceLabelPattern.MatchString(label) {
+			return invalid(reasonLabel, label)
+		}
+		if label == "fips" || strings.HasSuffix(label, "-fips") || strings.HasPrefix(label, "fips-") {
+			return invalid(reasonFIPS)
+		}
+	}
+	if len(labels) > 1 && regionPattern.MatchString(labels[len(labels)-1]) {
+		p.Region = labels[len(labels)-1]
+		labels = labels[:len(labels)-1]
+	}
+	if regionPattern.MatchString(labels[0]) {
+		return invalid(reasonNoService)
+	}
+	p.Service = strings.Join(labels, ".")
+	return p, nil
+}
+
+// String returns the principal in the form used in policies
+func (p ServicePrincipal) String() string {
+	if p.Region == "" {
+		return p.Service + "." + p.Domain
+	}
+	return p.Service + "." + p.Region + "." + p.Domain
+}
+
+// IsRegional returns whether the principal is specific to a single region
+func (p ServicePrincipal) IsRegional() bool {
+	return p.Region != ""
+}
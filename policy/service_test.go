@@ -0,0 +1,79 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseServicePrincipal(t *testing.T) {
+	tests := []struct {
+		principal string
+		expected  ServicePrincipal
+	}{
+		{"lambda.amazonaws.com", ServicePrincipal{"lambda", "", "amazonaws.com"}},
+		{"states.eu-west-1.amazonaws.com", ServicePrincipal{"states", "eu-west-1", "amazonaws.com"}},
+		{"delivery.logs.amazonaws.com", ServicePrincipal{"delivery.logs", "", "amazonaws.com"}},
+		{"logs.us-gov-west-1.amazonaws.com", ServicePrincipal{"logs", "us-gov-west-1", "amazonaws.com"}},
+		{"ec2.amazonaws.com.cn", ServicePrincipal{"ec2", "", "amazonaws.com.cn"}},
+		{"ec2.c2s.ic.gov", ServicePrincipal{"ec2", "", "c2s.ic.gov"}},
+		{"logs.us-iso-east-1.c2s.ic.gov", ServicePrincipal{"logs", "us-iso-east-1", "c2s.ic.gov"}},
+		{"lambda.sc2s.sgov.gov", ServicePrincipal{"lambda", "", "sc2s.sgov.gov"}},
+		{"states.us-isob-east-1.sc2s.sgov.gov", ServicePrincipal{"states", "us-isob-east-1", "sc2s.sgov.gov"}},
+		{"s3.cloud.adc-e.uk", ServicePrincipal{"s3", "", "cloud.adc-e.uk"}},
+		{"sns.csp.hci.ic.gov", ServicePrincipal{"sns", "", "csp.hci.ic.gov"}},
+		{"logs.eusc-de-east-1.amazonaws.eu", ServicePrincipal{"logs", "eusc-de-east-1", "amazonaws.eu"}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseServicePrincipal(test.principal)
+		if err != nil {
+			t.Errorf("Failed parsing %s: %s", test.principal, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("Expected %#v got %#v", test.expected, got)
+		}
+		if got.String() != test.principal {
+			t.Errorf("Expected %s got %s", test.principal, got.String())
+		}
+	}
+}
+
+func TestParseServicePrincipalErrors(t *testing.T) {
+	for _, principal := range []string{
+		"*",
+		"lambda",
+		"lambda.example.com",
+		"Lambda.amazonaws.com",
+		"s3-fips.us-east-1.amazonaws.com",
+		"eu-west-1.amazonaws.com",
+		"states..amazonaws.com",
+		"lambda.ic.gov",
+	} {
+		if _, err := ParseServicePrincipal(principal); err == nil {
+			t.Errorf("Expected InvalidServicePrincipalError for %s", principal)
+		}
+	}
+}
+
+func TestValidateServicePrincipal(t *testing.T) {
+	stmt := NewPolicy().AddStatement()
+	stmt.AddServicePrincipal("states.eu-west-1.amazonaws.com")
+	stmt.AddAction("sts:AssumeRole")
+	if err := stmt.Validate(); err != nil {
+		t.Errorf("Failed validating statement: %s", err)
+	}
+
+	stmt.AddServicePrincipal("states-fips.us-east-1.amazonaws.com")
+	var principalErr *InvalidServicePrincipalError
+	if !errors.As(stmt.Validate(), &principalErr) {
+		t.Errorf("Expected InvalidServicePrincipalError got %v", stmt.Validate())
+	}
+}
@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"unicode"
+)
+
+// PolicyDecoder reads policy documents one at a time from a stream containing
+// either a JSON array of documents or newline delimited documents (NDJSON)
+type PolicyDecoder struct {
+	// Options used to parse each document, must be set before the first call
+	// to Next. With JSONC the whole stream is read and comments and trailing
+	// commas are removed before the first document is decoded, as the stream
+	// can not be split into documents otherwise.
+	Options ParseOptions
+
+	r       *bufio.Reader
+	dec     *json.Decoder
+	inArray bool
+}
+
+// DecodePolicies returns a PolicyDecoder reading from r
+func DecodePolicies(r io.Reader) *PolicyDecoder {
+	return &PolicyDecoder{r: bufio.NewReader(r)}
+}
+
+// Next returns the next policy in the stream, or io.EOF when there are no
+// more policies
+func (d *PolicyDecoder) Next() (*Policy, error) {
+	if d.dec == nil {
+		if err := d.start(); err != nil {
+			return nil, err
+		}
+	}
+	if d.inArray && !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return nil, err
+		}
+		d.inArray = false
+		return nil, io.EOF
+	}
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return LoadPolicyWithOptions(raw, d.Options)
+}
+
+// start detects whether the stream holds an array or separate documents
+func (d *PolicyDecoder) start() error {
+	if d.Options.JSONC {
+		data, err := io.ReadAll(d.r)
+		if err != nil {
+			return err
+		}
+		d.r = bufio.NewReader(bytes.NewReader(stripJSONC(data)))
+	}
+	for {
+		r, _, err := d.r.ReadRune()
+		if err != nil {
+			return err
+		}
+		if !unicode.IsSpace(r) {
+			d.r.UnreadRune()
+			break
+		}
+	}
+	d.dec = json.NewDecoder(d.r)
+	b, err := d.r.Peek(1)
+	if err != nil {
+		return err
+	}
+	if b[0] == '[' {
+		if _, err := d.dec.Token(); err != nil {
+			return err
+		}
+		d.inArray = true
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func decodeAll(t *testing.T, data string) []*Policy {
+	d := DecodePolicies(strings.NewReader(data))
+	result := make([]*Policy, 0)
+	for {
+		p, err := d.Next()
+		if err == io.EOF {
+			return result
+		}
+		if err != nil {
+			t.Fatalf("Failed decoding policy %d: %s", len(result), err)
+		}
+		result = append(result, p)
+	}
+}
+
+func TestDecodePoliciesArray(t *testing.T) {
+	data := `
+	[
+		{"Version":"2012-10-17","Id":"a","Statement":[]},
+		{"Version":"2012-10-17","Id":"b","Statement":[]}
+	]`
+
+	policies := decodeAll(t, data)
+	if len(policies) != 2 || *policies[0].Id != "a" || *policies[1].Id != "b" {
+		t.Errorf("Expected policies a and b got %v", policies)
+	}
+}
+
+func TestDecodePoliciesNDJSON(t *testing.T) {
+	data := `{"Version":"2012-10-17","Id":"a","Statement":[]}
+{"Version":"2012-10-17","Id":"b","Statement":[]}
+{"Version":"2012-10-17","Id":"c","Statement":[]}
+`
+
+	policies := decodeAll(t, data)
+	if len(policies) != 3 || *policies[2].Id != "c" {
+		t.Errorf("Expected policies a, b and c got %v", policies)
+	}
+}
+
+func TestDecodePoliciesEmpty(t *testing.T) {
+	if policies := decodeAll(t, "[]"); len(policies) != 0 {
+		t.Errorf("Expected no policies got %v", policies)
+	}
+	if policies := decodeAll(t, " \n"); len(policies) != 0 {
+		t.Errorf("Expected no policies got %v", policies)
+	}
+}
+
+func TestDecodePoliciesJSONC(t *testing.T) {
+	data := `// Policies of the team
+	[
+		{"Version":"2012-10-17","Id":"a","Statement":[]}, // first
+		/* second */ {"Version":"2012-10-17","Id":"b","Statement":[],},
+	]`
+
+	d := DecodePolicies(strings.NewReader(data))
+	d.Options.JSONC = true
+	ids := make([]string, 0)
+	for {
+		p, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed decoding policy %d: %s", len(ids), err)
+		}
+		ids = append(ids, *p.Id)
+	}
+	if strings.Join(ids, ",") != "a,b" {
+		t.Errorf("Expected policies a and b got %v", ids)
+	}
+}
+
+func TestDecodePoliciesError(t *testing.T) {
+	d := DecodePolicies(strings.NewReader(`[{"Version":"2012-10-17","Statement":[]},{"Version":"2008-01-01","Statement":[]}]`))
+	if _, err := d.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Next(); err == nil {
+		t.Error("Expected error decoding invalid policy")
+	}
+
+	d = DecodePolicies(strings.NewReader(`{"Version":"2012-10-17","Statement":[],"Statement":[]}`))
+	d.Options = StrictParseOptions
+	if _, err := d.Next(); err == nil {
+		t.Error("Expected error decoding policy with duplicate keys")
+	}
+}
@@ -0,0 +1,135 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is an IAM role with its policies, as input for CheckTrustReciprocity
+type Role struct {
+	Arn string
+	// The identity policies attached to the role, managed and inline
+	IdentityPolicies []*Policy
+	// The trust policy of the role
+	TrustPolicy *Policy
+}
+
+// TrustAsymmetryKind tells which side of a role assumption is missing
+type TrustAsymmetryKind int
+
+const (
+	// The source role may assume the target role, but the target role does
+	// not trust it
+	AssumeNotTrusted TrustAsymmetryKind = iota
+	// The target role trusts the source role, but the source role may not
+	// assume it
+	OrphanedTrust
+)
+
+// TrustAsymmetry is a role assumption only one of the two roles allows
+type TrustAsymmetry struct {
+	Kind   TrustAsymmetryKind
+	Source string
+	Target string
+}
+
+func (a TrustAsymmetry) String() string {
+	if a.Kind == AssumeNotTrusted {
+		return fmt.Sprintf("%s may assume %s, which does not trust it", a.Source, a.Target)
+	}
+	return fmt.Sprintf("%s trusts %s, which may not assume it", a.Target, a.Source)
+}
+
+// CheckTrustReciprocity reports the role assumptions between the given roles
+// that are allowed by only one side: the identity policies of the source role
+// allow sts:AssumeRole on the target role but the trust policy of the target
+// role does not trust the source role, or the other way around. Trust granted
+// to an entire account is not reported as orphaned, as it delegates the
+// decision to the identity policies of that account. Conditions are not
+// evaluated, Deny statements are only taken into account if they have no
+// conditions.
+func CheckTrustReciprocity(roles []Role) []TrustAsymmetry {
+	result := make([]TrustAsymmetry, 0)
+	for _, source := range roles {
+		for _, target := range roles {
+			if source.Arn == target.Arn {
+				continue
+			}
+			allowed := source.allowsAssume(target.Arn)
+			trusted, explicit := target.trusts(source.Arn)
+			if allowed && !trusted {
+				result = append(result, TrustAsymmetry{AssumeNotTrusted, source.Arn, target.Arn})
+			}
+			if !allowed && explicit {
+				result = append(result, TrustAsymmetry{OrphanedTrust, source.Arn, target.Arn})
+			}
+		}
+	}
+	return result
+}
+
+const assumeRoleAction = "sts:AssumeRole"
+
+// allowsAssume reports whether the identity policies of the role allow it to
+// assume the target role
+func (r Role) allowsAssume(target string) bool {
+	allowed := false
+	for _, p := range r.IdentityPolicies {
+		for _, stmt := range p.Statement {
+			if !stmt.statementMatches(assumeRoleAction, target) {
+				continue
+			}
+			if stmt.Effect == Deny && len(stmt.Condition) == 0 {
+				return false
+			}
+			if stmt.Effect == Allow {
+				allowed = true
+			}
+		}
+	}
+	return allowed
+}
+
+// trusts reports whether the trust policy of the role allows the source role
+// to assume it, and whether it does so by naming the source role explicitly
+func (r Role) trusts(source string) (trusted, explicit bool) {
+	if r.TrustPolicy == nil {
+		return false, false
+	}
+	account := arnAccount(source)
+	for _, stmt := range r.TrustPolicy.Statement {
+		if stmt.Principal == nil || !matchesList(stmt.Action, stmt.NotAction, assumeRoleAction, matchAction) {
+			continue
+		}
+		for _, principal := range stmt.Principal.Aws {
+			match := principal == source
+			if !match && principal != "*" && principal != account && principal != "arn:aws:iam::"+account+":root" {
+				continue
+			}
+			if stmt.Effect == Deny && len(stmt.Condition) == 0 {
+				return false, false
+			}
+			if stmt.Effect == Allow {
+				trusted = true
+				explicit = explicit || match
+			}
+		}
+	}
+	return trusted, explicit
+}
+
+// arnAccount returns the account id of an ARN
+func arnAccount(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
@@ -0,0 +1,75 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		expected   bool
+	}{
+		{"*", "", true},
+		{"arn:aws:iam::*:role/*", "arn:aws:iam::123456789012:role/path/app", true},
+		{"arn:aws:iam::123456789012:role/app-?", "arn:aws:iam::123456789012:role/app-1", true},
+		{"arn:aws:iam::123456789012:role/app-?", "arn:aws:iam::123456789012:role/app-10", false},
+		{"a*b*c", "abxbxc", true},
+		{"a*b*c", "abxbx", false},
+	}
+	for _, test := range tests {
+		if got := WildcardMatch(test.pattern, test.s); got != test.expected {
+			t.Errorf("Expected %v for %s matching %s got %v", test.expected, test.s, test.pattern, got)
+		}
+	}
+}
+
+func TestCheckTrustReciprocity(t *testing.T) {
+	const (
+		deployer = "arn:aws:iam::111122223333:role/deployer"
+		app      = "arn:aws:iam::111122223333:role/app"
+		audit    = "arn:aws:iam::444455556666:role/audit"
+		reader   = "arn:aws:iam::111122223333:role/reader"
+	)
+	roles := []Role{
+		{
+			Arn:              deployer,
+			IdentityPolicies: []*Policy{Build().Allow().Actions("sts:assumerole").Resources("arn:aws:iam::111122223333:role/*").Done()},
+			TrustPolicy:      Build().Allow().ServicePrincipal("codebuild.amazonaws.com").Actions("sts:AssumeRole").Done(),
+		},
+		{
+			Arn:         app,
+			TrustPolicy: Build().Allow().Principal(deployer).Actions("sts:AssumeRole").Done(),
+		},
+		{
+			Arn:         reader,
+			TrustPolicy: Build().Allow().Principal(audit).Actions("sts:AssumeRole").Done(),
+		},
+		{
+			Arn: audit,
+			IdentityPolicies: []*Policy{Build().
+				Allow().Actions("sts:AssumeRole").Resources("*").
+				Deny().Actions("sts:*").Resources(reader).
+				Done()},
+			TrustPolicy: Build().Allow().Principal("111122223333").Actions("sts:AssumeRole").Done(),
+		},
+	}
+
+	expected := []TrustAsymmetry{
+		{AssumeNotTrusted, deployer, reader},
+		{AssumeNotTrusted, audit, deployer},
+		{AssumeNotTrusted, audit, app},
+		{OrphanedTrust, audit, reader},
+	}
+	got := CheckTrustReciprocity(roles)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v got %v", expected, got)
+	}
+}
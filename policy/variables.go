@@ -0,0 +1,136 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Validation error when a policy variable is used in an element that does not
+// support variables
+type InvalidPolicyVariableError struct {
+	Element string
+	Value   string
+}
+
+const policyVariableErrorFormat = "Policy variable in %s %q, variables can only be used in Resource, NotResource and string or ARN condition values"
+
+func (e *InvalidPolicyVariableError) Error() string {
+	return fmt.Sprintf(policyVariableErrorFormat, e.Element, e.Value)
+}
+
+var variablePattern = regexp.MustCompile(`\$\{[^}]*\}`)
+
+// Var returns a policy variable referencing the condition key, e.g.
+// ${aws:username}. AWS replaces the variable with the value of the key in the
+// request context.
+func Var(key ConditionVariable) string {
+	return "${" + string(key) + "}"
+}
+
+// VarDefault returns a policy variable referencing the condition key with a
+// default value used when the key is not present in the request context, e.g.
+// ${aws:PrincipalTag/team, 'none'}
+func VarDefault(key ConditionVariable, value string) string {
+	return fmt.Sprintf("${%s, '%s'}", key, value)
+}
+
+// Condition operators whose values may contain policy variables
+var variableOperators = map[ConditionType]bool{
+	ConditionStringEquals:              true,
+	ConditionStringNotEquals:           true,
+	ConditionStringEqualsIgnoreCase:    true,
+	ConditionStringNotEqualsIgnoreCase: true,
+	ConditionStringLike:                true,
+	ConditionStringNotLike:             true,
+	ConditionArnEquals:                 true,
+	ConditionArnNotEquals:              true,
+	ConditionArnLike:                   true,
+	ConditionArnNotLike:                true,
+}
+
+// validateVariables returns an error for each policy variable used in an
+// element that does not support them
+func (s *Statement) validateVariables() []error {
+	errs := make([]error, 0)
+	check := func(element string, values []string) {
+		for _, value := range values {
+			if variablePattern.MatchString(value) {
+				errs = append(errs, &InvalidPolicyVariableError{element, value})
+			}
+		}
+	}
+
+	check("Action", s.Action)
+	check("NotAction", s.NotAction)
+	for _, p := range []*Principal{s.Principal, s.NotPrincipal} {
+		if p == nil {
+			continue
+		}
+		element := "Principal"
+		if p == s.NotPrincipal {
+			element = "NotPrincipal"
+		}
+		check(element, p.Aws)
+		check(element, p.Service)
+		check(element, p.Federated)
+		check(element, p.CanonicalUser)
+	}
+	conditions := len(errs)
+	for t, keys := range s.Condition {
+		for key, values := range keys {
+			check("Condition key", []string{string(key)})
+			if !variableOperators[t.Operator()] {
+				check("Condition "+string(t), values)
+			}
+		}
+	}
+	// Conditions are maps, sort their errors for a stable order
+	sort.Slice(errs[conditions:], func(i, j int) bool {
+		return errs[conditions+i].Error() < errs[conditions+j].Error()
+	})
+	return errs
+}
+
+// removeVariable removes the value of a misplaced policy variable from the
+// statement
+func removeVariable(e *InvalidPolicyVariableError) func(*Statement) {
+	return func(s *Statement) {
+		s.Action = without(s.Action, e.Value)
+		s.NotAction = without(s.NotAction, e.Value)
+		for _, p := range []*Principal{s.Principal, s.NotPrincipal} {
+			if p != nil {
+				p.Aws = without(p.Aws, e.Value)
+				p.Service = without(p.Service, e.Value)
+				p.Federated = without(p.Federated, e.Value)
+				p.CanonicalUser = without(p.CanonicalUser, e.Value)
+			}
+		}
+		for t, keys := range s.Condition {
+			for key, values := range keys {
+				if string(key) == e.Value {
+					delete(keys, key)
+				} else if !variableOperators[t.Operator()] {
+					keys[key] = without(values, e.Value)
+				}
+			}
+		}
+	}
+}
+
+func without(list []string, value string) []string {
+	result := list[:0]
+	for _, v := range list {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVar(t *testing.T) {
+	if got := Var(VarUsername); got != "${aws:username}" {
+		t.Errorf("Expected ${aws:username} got %s", got)
+	}
+	if got := VarDefault(PrincipalTag("team"), "none"); got != "${aws:PrincipalTag/team, 'none'}" {
+		t.Errorf("Expected ${aws:PrincipalTag/team, 'none'} got %s", got)
+	}
+}
+
+func TestValidateVariables(t *testing.T) {
+	home := "arn:aws:s3:::bucket/home/" + Var(VarUsername) + "/*"
+	valid := NewStatement(
+		WithEffect(Allow),
+		WithActions("s3:GetObject"),
+		WithResources(home),
+		WithCondition(ConditionStringLike, "s3:prefix", "home/"+Var(VarUsername)+"/*"),
+		WithCondition(ConditionArnEquals, VarSourceArn, "arn:aws:sns:*:*:"+VarDefault(PrincipalTag("team"), "none")),
+	)
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected no error got %s", err)
+	}
+
+	invalid := NewStatement(
+		WithEffect(Allow),
+		WithActions("s3:"+Var(VarUsername)),
+		WithResources(home),
+		WithCondition(ConditionNumericLessThan, VarMultiFactorAuthAge, Var(PrincipalTag("maxage"))),
+		WithCondition(ConditionStringEquals, ConditionVariable(Var(VarUsername)), "bob"),
+	)
+	var errs ValidationErrors
+	if !errors.As(invalid.Validate(), &errs) || len(errs) != 3 {
+		t.Fatalf("Expected 3 errors got %v", errs)
+	}
+	expected := []InvalidPolicyVariableError{
+		{"Action", "s3:${aws:username}"},
+		{"Condition NumericLessThan", "${aws:PrincipalTag/maxage}"},
+		{"Condition key", "${aws:username}"},
+	}
+	for i, err := range errs {
+		var e *InvalidPolicyVariableError
+		if !errors.As(err, &e) || *e != expected[i] {
+			t.Errorf("Expected %v got %v", expected[i], err)
+		}
+	}
+
+	p := NewPolicy()
+	p.Append(invalid)
+	findings := p.Lint()
+	if len(findings) != 3 || findings[0].Remediation != `{"Effect":"Allow","Resource":["arn:aws:s3:::bucket/home/${aws:username}/*"],"Condition":{"NumericLessThan":{"aws:MultiFactorAuthAge":["${aws:PrincipalTag/maxage}"]},"StringEquals":{"${aws:username}":["bob"]}}}` {
+		t.Errorf("Expected the variable to be removed from Action got %v", findings)
+	}
+}
@@ -0,0 +1,73 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package policy
+
+import (
+	"strings"
+)
+
+// WildcardMatch reports whether s matches the IAM wildcard pattern, in which
+// * matches any sequence of characters, including none and including /, and ?
+// matches any single character
+func WildcardMatch(pattern, s string) bool {
+	// Position in pattern and s to resume from after the last *
+	star, next := -1, 0
+	p, i := 0, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, i
+			p++
+		case star >= 0:
+			next++
+			p, i = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchAction reports whether the action matches the action pattern, action
+// names are case-insensitive
+func matchAction(pattern, action string) bool {
+	return WildcardMatch(strings.ToLower(pattern), strings.ToLower(action))
+}
+
+// statementMatches reports whether the statement applies to the action and
+// resource, taking Action, NotAction, Resource and NotResource into account.
+// Conditions are not evaluated.
+func (s *Statement) statementMatches(action, resource string) bool {
+	if !matchesList(s.Action, s.NotAction, action, matchAction) {
+		return false
+	}
+	return matchesList(s.ResourceList(), s.NotResource, resource, WildcardMatch)
+}
+
+func matchesList(list, notList []string, value string, match func(pattern, value string) bool) bool {
+	if len(notList) > 0 {
+		for _, pattern := range notList {
+			if match(pattern, value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, pattern := range list {
+		if match(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package route53policy provides minimal policies for DNS-01 certificate
+// validation flows (cert-manager, ACME clients) using Route53 and ACM
+package route53policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// ErrNoHostedZones is returned when a DNS-01 policy is requested without any
+// hosted zones to scope it to
+var ErrNoHostedZones = errors.New("No hosted zones given")
+
+const (
+	varRecordNames policy.ConditionVariable = "route53:ChangeResourceRecordSetsNormalizedRecordNames"
+	varRecordTypes policy.ConditionVariable = "route53:ChangeResourceRecordSetsRecordTypes"
+)
+
+// HostedZoneArn returns the ARN of a Route53 hosted zone, the id may be given
+// with or without the "/hostedzone/" prefix returned by the Route53 API
+func HostedZoneArn(id string) string {
+	return "arn:aws:route53:::hostedzone/" + strings.TrimPrefix(id, "/hostedzone/")
+}
+
+// NewDNS01Policy creates a policy allowing the TXT records used for DNS-01
+// challenges to be managed in the given hosted zones. When domains are given
+// only the "_acme-challenge" records of those domains may be changed,
+// otherwise any "_acme-challenge" record in the zones.
+func NewDNS01Policy(hostedZoneIds []string, domains []string) (*policy.Policy, error) {
+	if len(hostedZoneIds) == 0 {
+		return nil, ErrNoHostedZones
+	}
+
+	p := policy.NewPolicy()
+
+	stmt := p.AddIdentityStatement()
+	stmt.SetSid("GetChange")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.Route53GetChange)
+	stmt.AddResource("arn:aws:route53:::change/*")
+
+	stmt = p.AddIdentityStatement()
+	stmt.SetSid("ListHostedZones")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.Route53ListHostedZonesByName)
+	stmt.AddResource("*")
+
+	stmt = p.AddIdentityStatement()
+	stmt.SetSid("ChangeChallengeRecords")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.Route53ChangeResourceRecordSets)
+	stmt.AddAction(actions.Route53ListResourceRecordSets)
+	for _, id := range hostedZoneIds {
+		stmt.AddResource(HostedZoneArn(id))
+	}
+	stmt.AddSetCondition(policy.ForAllValues, policy.ConditionStringEquals, varRecordTypes, "TXT")
+	if len(domains) == 0 {
+		stmt.AddSetCondition(policy.ForAllValues, policy.ConditionStringLike, varRecordNames, "_acme-challenge.*")
+	}
+	for _, domain := range domains {
+		name := "_acme-challenge." + strings.TrimSuffix(strings.ToLower(domain), ".")
+		stmt.AddSetCondition(policy.ForAllValues, policy.ConditionStringEquals, varRecordNames, name)
+	}
+
+	return p, nil
+}
+
+// Add the statements needed to request and manage DNS validated ACM
+// certificates in the given account and region to the policy
+func AddACMStatements(p *policy.Policy, account, region string) {
+	stmt := p.AddIdentityStatement()
+	stmt.SetSid("RequestCertificate")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.ACMRequestCertificate)
+	stmt.AddAction(actions.ACMListCertificates)
+	stmt.AddResource("*")
+
+	stmt = p.AddIdentityStatement()
+	stmt.SetSid("ManageCertificates")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.ACMDescribeCertificate)
+	stmt.AddAction(actions.ACMGetCertificate)
+	stmt.AddAction(actions.ACMAddTagsToCertificate)
+	stmt.AddAction(actions.ACMDeleteCertificate)
+	stmt.AddResource(fmt.Sprintf("arn:aws:acm:%s:%s:certificate/*", region, account))
+}
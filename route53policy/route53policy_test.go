@@ -0,0 +1,86 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package route53policy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func assertPolicy(t *testing.T, p *policy.Policy, expected string) {
+	data, err := p.Get()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got := string(data)
+
+	if got != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+}
+
+func TestHostedZoneArn(t *testing.T) {
+	expected := "arn:aws:route53:::hostedzone/Z123"
+	if got := HostedZoneArn("Z123"); got != expected {
+		t.Errorf("Expected %s got %s", expected, got)
+	}
+	if got := HostedZoneArn("/hostedzone/Z123"); got != expected {
+		t.Errorf("Expected %s got %s", expected, got)
+	}
+}
+
+func TestNewDNS01PolicyWithoutZones(t *testing.T) {
+	_, err := NewDNS01Policy(nil, nil)
+	if err != ErrNoHostedZones {
+		t.Errorf("Expected ErrNoHostedZones got %v", err)
+	}
+}
+
+func TestNewDNS01Policy(t *testing.T) {
+	p, err := NewDNS01Policy([]string{"Z123"}, []string{"Example.com."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"GetChange","Effect":"Allow","Action":["route53:GetChange"],"Resource":["arn:aws:route53:::change/*"]},` +
+		`{"Sid":"ListHostedZones","Effect":"Allow","Action":["route53:ListHostedZonesByName"],"Resource":["*"]},` +
+		`{"Sid":"ChangeChallengeRecords","Effect":"Allow","Action":["route53:ChangeResourceRecordSets","route53:ListResourceRecordSets"],"Resource":["arn:aws:route53:::hostedzone/Z123"],` +
+		`"Condition":{"ForAllValues:StringEquals":{"route53:ChangeResourceRecordSetsNormalizedRecordNames":["_acme-challenge.example.com"],"route53:ChangeResourceRecordSetsRecordTypes":["TXT"]}}}]}`
+
+	assertPolicy(t, p, expected)
+}
+
+func TestNewDNS01PolicyAnyDomain(t *testing.T) {
+	p, err := NewDNS01Policy([]string{"Z123", "Z456"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Statement) != 3 {
+		t.Fatalf("Expected 3 statements got %d", len(p.Statement))
+	}
+	resources := p.Statement[2].Resources
+	if len(resources) != 2 || resources[1] != "arn:aws:route53:::hostedzone/Z456" {
+		t.Errorf("Expected both hosted zones got %v", resources)
+	}
+	cond := p.Statement[2].Condition["ForAllValues:StringLike"]["route53:ChangeResourceRecordSetsNormalizedRecordNames"]
+	if len(cond) != 1 || cond[0] != "_acme-challenge.*" {
+		t.Errorf("Expected _acme-challenge.* got %v", cond)
+	}
+}
+
+func TestAddACMStatements(t *testing.T) {
+	p := policy.NewPolicy()
+	AddACMStatements(p, "111122223333", "eu-west-1")
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"RequestCertificate","Effect":"Allow","Action":["acm:RequestCertificate","acm:ListCertificates"],"Resource":["*"]},` +
+		`{"Sid":"ManageCertificates","Effect":"Allow","Action":["acm:DescribeCertificate","acm:GetCertificate","acm:AddTagsToCertificate","acm:DeleteCertificate"],"Resource":["arn:aws:acm:eu-west-1:111122223333:certificate/*"]}]}`
+
+	assertPolicy(t, p, expected)
+}
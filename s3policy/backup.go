@@ -0,0 +1,247 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package s3policy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// Error returned when a backup policy grants an action it should not
+type BackupPolicyError string
+
+func (s BackupPolicyError) Error() string {
+	return fmt.Sprintf("Backup policy grants %s", string(s))
+}
+
+// ErrRetentionDays is returned when backups would not have to be retained for
+// at least a day
+var ErrRetentionDays = errors.New("Backup retention must be at least one day")
+
+// Object lock retention modes
+const (
+	LockModeGovernance = "GOVERNANCE"
+	LockModeCompliance = "COMPLIANCE"
+)
+
+// Actions that modify or remove backups
+var (
+	backupWriteActions = []string{
+		actions.S3PutObject,
+		actions.S3PutObjectRetention,
+		actions.S3PutObjectLegalHold,
+	}
+	backupDeleteActions = []string{
+		actions.S3DeleteObject,
+		actions.S3DeleteObjectVersion,
+		actions.S3BypassGovernanceRetention,
+	}
+)
+
+// BackupOptions describes where backups are stored and how they are locked
+type BackupOptions struct {
+	Bucket string
+	// Key prefix the backups are stored under, may be empty
+	Prefix string
+	// Object lock mode new backups must use, LockModeCompliance if empty
+	LockMode string
+	// Minimum number of days new backups must be retained, at least 1
+	RetentionDays int
+}
+
+func (o BackupOptions) objectArn() string {
+	return fmt.Sprintf("arn:aws:s3:::%s/%s*", o.Bucket, o.Prefix)
+}
+
+func (o BackupOptions) bucketArn() string {
+	return "arn:aws:s3:::" + o.Bucket
+}
+
+// NewBackupPolicies generates a matched pair of policies for backup tooling: a
+// writer policy that can store new locked backups but never delete them, and a
+// restore policy that can read backups but never write them.
+func NewBackupPolicies(opts BackupOptions) (writer, restore *policy.Policy, err error) {
+	if opts.RetentionDays < 1 {
+		return nil, nil, ErrRetentionDays
+	}
+	mode := opts.LockMode
+	if mode == "" {
+		mode = LockModeCompliance
+	}
+
+	writer = policy.NewPolicy()
+	stmt := writer.AddIdentityStatement()
+	stmt.SetSid("PutLockedBackups")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.S3PutObject)
+	stmt.AddResource(opts.objectArn())
+	stmt.AddCondition(policy.ConditionStringEquals, conditionkeys.S3ObjectLockMode, mode)
+	stmt.AddCondition(policy.ConditionNumericGreaterThanEquals, conditionkeys.S3ObjectLockRemainingRetentionDays, strconv.Itoa(opts.RetentionDays))
+
+	stmt = writer.AddIdentityStatement()
+	stmt.SetSid("ListBackups")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.S3ListBucket)
+	stmt.AddResource(opts.bucketArn())
+
+	stmt = writer.AddIdentityStatement()
+	stmt.SetSid("DenyDelete")
+	stmt.Effect = policy.Deny
+	for _, action := range backupDeleteActions {
+		stmt.AddAction(action)
+	}
+	stmt.AddResource(opts.objectArn())
+
+	restore = policy.NewPolicy()
+	stmt = restore.AddIdentityStatement()
+	stmt.SetSid("ReadBackups")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.S3GetObject)
+	stmt.AddAction(actions.S3GetObjectVersion)
+	stmt.AddResource(opts.objectArn())
+
+	stmt = restore.AddIdentityStatement()
+	stmt.SetSid("ListBackups")
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.S3ListBucket)
+	stmt.AddAction(actions.S3ListBucketVersions)
+	stmt.AddResource(opts.bucketArn())
+
+	stmt = restore.AddIdentityStatement()
+	stmt.SetSid("DenyWrite")
+	stmt.Effect = policy.Deny
+	for _, action := range backupWriteActions {
+		stmt.AddAction(action)
+	}
+	for _, action := range backupDeleteActions {
+		stmt.AddAction(action)
+	}
+	stmt.AddResource(opts.objectArn())
+
+	if err := ValidateBackupPolicies(opts, writer, restore); err != nil {
+		return nil, nil, err
+	}
+	return writer, restore, nil
+}
+
+// ValidateBackupPolicies checks that the writer policy does not allow deleting
+// the backups described by opts and that the restore policy does not allow any
+// write or delete action on them, so the two policies are disjoint on write
+// actions. Allow statements for other resources are ignored, and actions are
+// not reported when a Deny statement without conditions covers all backups.
+func ValidateBackupPolicies(opts BackupOptions, writer, restore *policy.Policy) error {
+	backups := opts.objectArn()
+	if action := allowedAction(writer, backupDeleteActions, backups); action != "" {
+		return BackupPolicyError(action)
+	}
+	if action := allowedAction(restore, backupWriteActions, backups); action != "" {
+		return BackupPolicyError(action)
+	}
+	if action := allowedAction(restore, backupDeleteActions, backups); action != "" {
+		return BackupPolicyError(action)
+	}
+	return nil
+}
+
+// allowedAction returns the first of the given actions the policy allows on
+// some of the objects matched by the resource pattern
+func allowedAction(p *policy.Policy, actions []string, resource string) string {
+	for _, action := range actions {
+		allowed, denied := false, false
+		for _, stmt := range p.Statement {
+			if !matchesAction(stmt, action) {
+				continue
+			}
+			if stmt.Effect == policy.Allow && mayApply(stmt, resource) {
+				allowed = true
+			}
+			if stmt.Effect == policy.Deny && len(stmt.Condition) == 0 && appliesToAll(stmt, resource) {
+				denied = true
+			}
+		}
+		if allowed && !denied {
+			return action
+		}
+	}
+	return ""
+}
+
+// matchesAction reports whether the statement's Action or NotAction covers
+// the action
+func matchesAction(stmt *policy.Statement, action string) bool {
+	if len(stmt.NotAction) > 0 {
+		return !matchesAny(stmt.NotAction, action)
+	}
+	return matchesAny(stmt.Action, action)
+}
+
+// mayApply reports whether the statement may apply to some of the resources
+// matched by the pattern
+func mayApply(stmt *policy.Statement, pattern string) bool {
+	if len(stmt.NotResource) > 0 {
+		for _, excluded := range stmt.NotResource {
+			if policy.WildcardMatch(excluded, pattern) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, resource := range stmt.ResourceList() {
+		if overlaps(resource, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesToAll reports whether the statement applies to every resource
+// matched by the pattern
+func appliesToAll(stmt *policy.Statement, pattern string) bool {
+	if len(stmt.NotResource) > 0 {
+		for _, excluded := range stmt.NotResource {
+			if overlaps(excluded, pattern) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, resource := range stmt.ResourceList() {
+		if policy.WildcardMatch(resource, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether two patterns may match the same resource, which is
+// assumed when the parts before their first wildcards are compatible
+func overlaps(a, b string) bool {
+	if policy.WildcardMatch(a, b) || policy.WildcardMatch(b, a) {
+		return true
+	}
+	i, j := strings.IndexAny(a, "*?"), strings.IndexAny(b, "*?")
+	if i < 0 || j < 0 {
+		return false
+	}
+	return strings.HasPrefix(a[:i], b[:j]) || strings.HasPrefix(b[:j], a[:i])
+}
+
+func matchesAny(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if policy.WildcardMatch(strings.ToLower(pattern), strings.ToLower(action)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package s3policy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestNewBackupPolicies(t *testing.T) {
+	writer, restore, err := NewBackupPolicies(BackupOptions{Bucket: "backups", Prefix: "db/", RetentionDays: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"PutLockedBackups","Effect":"Allow","Action":["s3:PutObject"],"Resource":["arn:aws:s3:::backups/db/*"],"Condition":{"NumericGreaterThanEquals":{"s3:object-lock-remaining-retention-days":["30"]},"StringEquals":{"s3:object-lock-mode":["COMPLIANCE"]}}},` +
+		`{"Sid":"ListBackups","Effect":"Allow","Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::backups"]},` +
+		`{"Sid":"DenyDelete","Effect":"Deny","Action":["s3:DeleteObject","s3:DeleteObjectVersion","s3:BypassGovernanceRetention"],"Resource":["arn:aws:s3:::backups/db/*"]}]}`
+	assertPolicy(t, writer, expected)
+
+	expected = `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"ReadBackups","Effect":"Allow","Action":["s3:GetObject","s3:GetObjectVersion"],"Resource":["arn:aws:s3:::backups/db/*"]},` +
+		`{"Sid":"ListBackups","Effect":"Allow","Action":["s3:ListBucket","s3:ListBucketVersions"],"Resource":["arn:aws:s3:::backups"]},` +
+		`{"Sid":"DenyWrite","Effect":"Deny","Action":["s3:PutObject","s3:PutObjectRetention","s3:PutObjectLegalHold","s3:DeleteObject","s3:DeleteObjectVersion","s3:BypassGovernanceRetention"],"Resource":["arn:aws:s3:::backups/db/*"]}]}`
+	assertPolicy(t, restore, expected)
+
+	if _, _, err := NewBackupPolicies(BackupOptions{Bucket: "backups"}); err != ErrRetentionDays {
+		t.Errorf("Expected ErrRetentionDays got %v", err)
+	}
+}
+
+func TestValidateBackupPolicies(t *testing.T) {
+	opts := BackupOptions{Bucket: "backups", LockMode: LockModeGovernance, RetentionDays: 7}
+	writer, restore, err := NewBackupPolicies(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Denied by the DenyWrite statement
+	stmt := restore.AddIdentityStatement()
+	stmt.Effect = policy.Allow
+	stmt.AddAction("s3:Put*")
+	stmt.AddResource("*")
+	if err := ValidateBackupPolicies(opts, writer, restore); err != nil {
+		t.Errorf("Expected no error got %v", err)
+	}
+	restore.RemoveStatement("DenyWrite")
+	if err := ValidateBackupPolicies(opts, writer, restore); err != BackupPolicyError("s3:PutObject") {
+		t.Errorf("Expected BackupPolicyError got %v", err)
+	}
+
+	writer, restore, _ = NewBackupPolicies(opts)
+	writer.RemoveStatement("DenyDelete")
+	// Other buckets are out of scope, and [ is not a wildcard
+	stmt = writer.AddIdentityStatement()
+	stmt.Effect = policy.Allow
+	stmt.AddAction("s3:Delete[O]bjectVersion")
+	stmt.AddResource("arn:aws:s3:::backups/*")
+	if err := ValidateBackupPolicies(opts, writer, restore); err != nil {
+		t.Errorf("Expected no error got %v", err)
+	}
+	stmt.Action = []string{"s3:DeleteObject"}
+	stmt.Resources = []string{"arn:aws:s3:::scratch/*"}
+	if err := ValidateBackupPolicies(opts, writer, restore); err != nil {
+		t.Errorf("Expected no error got %v", err)
+	}
+
+	stmt.Action = nil
+	stmt.AddNotAction("s3:Get*")
+	stmt.Resources = []string{"arn:aws:s3:::backups/2026/*"}
+	if err := ValidateBackupPolicies(opts, writer, restore); err != BackupPolicyError("s3:DeleteObject") {
+		t.Errorf("Expected BackupPolicyError got %v", err)
+	}
+}
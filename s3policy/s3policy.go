@@ -0,0 +1,63 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package s3policy provides helpers to generate and check common S3 bucket
+// policy statements
+package s3policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+// CloudFrontServicePrincipal is the service principal used by CloudFront
+// origin access control
+const CloudFrontServicePrincipal = "cloudfront.amazonaws.com"
+
+// The principal ARN prefix of legacy CloudFront origin access identities
+const legacyOAIPrincipalPrefix = "arn:aws:iam::cloudfront:user/CloudFront Origin Access Identity "
+
+// DistributionArn returns the ARN of a CloudFront distribution
+func DistributionArn(account, distributionId string) string {
+	return fmt.Sprintf("arn:aws:cloudfront::%s:distribution/%s", account, distributionId)
+}
+
+// Add a statement to the bucket policy allowing the CloudFront distribution to
+// read objects from the bucket using origin access control (OAC), returns the
+// new Statement
+func AddCloudFrontOAC(p *policy.Policy, bucket, distributionArn string) *policy.Statement {
+	stmt := CloudFrontOAC(bucket, distributionArn)
+	p.Append(stmt)
+	return stmt
+}
+
+// LegacyOAIStatements returns the statements of the policy that grant access
+// to a legacy CloudFront origin access identity (OAI). These should be
+// migrated to origin access control. Older policies granted OAIs by their S3
+// canonical user id, which can not be told apart from other canonical users,
+// so every statement with a CanonicalUser principal is returned as well.
+func LegacyOAIStatements(p *policy.Policy) []*policy.Statement {
+	result := make([]*policy.Statement, 0)
+	for _, stmt := range p.Statement {
+		if stmt.Principal == nil {
+			continue
+		}
+		if len(stmt.Principal.CanonicalUser) > 0 {
+			result = append(result, stmt)
+			continue
+		}
+		for _, principal := range stmt.Principal.Aws {
+			if strings.HasPrefix(principal, legacyOAIPrincipalPrefix) {
+				result = append(result, stmt)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package s3policy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func assertPolicy(t *testing.T, p *policy.Policy, expected string) {
+	data, err := p.Get()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got := string(data)
+
+	if got != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+}
+
+func TestDistributionArn(t *testing.T) {
+	expected := "arn:aws:cloudfront::111122223333:distribution/EDFDVBD6EXAMPLE"
+	got := DistributionArn("111122223333", "EDFDVBD6EXAMPLE")
+
+	if got != expected {
+		t.Errorf("Expected %s got %s", expected, got)
+	}
+}
+
+func TestAddCloudFrontOAC(t *testing.T) {
+	p := policy.NewPolicy()
+	AddCloudFrontOAC(p, "bucket", DistributionArn("111122223333", "EDFDVBD6EXAMPLE"))
+	expected := `{"Version":"2012-10-17","Statement":[{"Sid":"AllowCloudFrontServicePrincipalReadOnly","Effect":"Allow","Principal":{"Service":["cloudfront.amazonaws.com"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"],"Condition":{"StringEquals":{"aws:SourceArn":["arn:aws:cloudfront::111122223333:distribution/EDFDVBD6EXAMPLE"]}}}]}`
+
+	assertPolicy(t, p, expected)
+}
+
+func TestLegacyOAIStatements(t *testing.T) {
+	p := policy.NewPolicy()
+	AddCloudFrontOAC(p, "bucket", DistributionArn("111122223333", "EDFDVBD6EXAMPLE"))
+	legacy := p.AddStatement()
+	legacy.Effect = policy.Allow
+	legacy.AddPrincipal("arn:aws:iam::cloudfront:user/CloudFront Origin Access Identity E2QWRUHAPOMQZL")
+	legacy.AddAction("s3:GetObject")
+	legacy.AddResource("arn:aws:s3:::bucket/*")
+
+	canonical := p.AddStatement()
+	canonical.Effect = policy.Allow
+	canonical.Principal = &policy.Principal{CanonicalUser: []string{"79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be"}}
+	canonical.AddAction("s3:GetObject")
+	canonical.AddResource("arn:aws:s3:::bucket/*")
+
+	got := LegacyOAIStatements(p)
+	if len(got) != 2 || got[0] != legacy || got[1] != canonical {
+		t.Errorf("Expected only the legacy OAI statements got %v", got)
+	}
+}
@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package s3policy
+
+import (
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+func bucketArns(bucket string) []string {
+	return []string{"arn:aws:s3:::" + bucket, "arn:aws:s3:::" + bucket + "/*"}
+}
+
+// DenyInsecureTransport returns a bucket policy statement denying all
+// requests to the bucket that are not made over TLS
+func DenyInsecureTransport(bucket string) *policy.Statement {
+	return policy.NewStatement(
+		policy.WithSid("DenyInsecureTransport"),
+		policy.WithEffect(policy.Deny),
+		policy.WithPrincipals("*"),
+		policy.WithActions(actions.S3All),
+		policy.WithResources(bucketArns(bucket)...),
+		policy.WithCondition(policy.ConditionBool, policy.VarSecureTransport, "false"),
+	)
+}
+
+// DenyOutsideVpcEndpoints returns a bucket policy statement denying all
+// requests to the bucket that are not made through one of the VPC endpoints.
+// This includes requests from the console and from administrators, so make
+// sure they can still manage the bucket through an endpoint. At least one
+// endpoint is required, without the condition the statement would deny every
+// request.
+func DenyOutsideVpcEndpoints(bucket, vpceId string, vpceIds ...string) *policy.Statement {
+	stmt := policy.NewStatement(
+		policy.WithSid("DenyOutsideVpcEndpoints"),
+		policy.WithEffect(policy.Deny),
+		policy.WithPrincipals("*"),
+		policy.WithActions(actions.S3All),
+		policy.WithResources(bucketArns(bucket)...),
+	)
+	stmt.AddConditionValues(policy.ConditionStringNotEquals, policy.VarSourceVpce, append([]string{vpceId}, vpceIds...)...)
+	return stmt
+}
+
+// CloudFrontOAC returns a bucket policy statement allowing the CloudFront
+// distribution to read objects from the bucket using origin access control
+func CloudFrontOAC(bucket, distributionArn string) *policy.Statement {
+	return policy.NewStatement(
+		policy.WithSid("AllowCloudFrontServicePrincipalReadOnly"),
+		policy.WithEffect(policy.Allow),
+		policy.WithServicePrincipals(CloudFrontServicePrincipal),
+		policy.WithActions(actions.S3GetObject),
+		policy.WithResources("arn:aws:s3:::"+bucket+"/*"),
+		policy.WithCondition(policy.ConditionStringEquals, policy.VarSourceArn, distributionArn),
+	)
+}
+
+// DenyUnencryptedUploads returns a bucket policy statement denying uploads
+// that do not request server-side encryption with the given algorithm, e.g.
+// "aws:kms". When algorithm is empty uploads are only required to request
+// some form of server-side encryption.
+func DenyUnencryptedUploads(bucket, algorithm string) *policy.Statement {
+	stmt := policy.NewStatement(
+		policy.WithSid("DenyUnencryptedUploads"),
+		policy.WithEffect(policy.Deny),
+		policy.WithPrincipals("*"),
+		policy.WithActions(actions.S3PutObject),
+		policy.WithResources("arn:aws:s3:::"+bucket+"/*"),
+	)
+	if algorithm == "" {
+		stmt.AddCondition(policy.ConditionNull, conditionkeys.S3XAmzServerSideEncryption, "true")
+	} else {
+		// Also matches uploads without the header, the key is absent then
+		stmt.AddCondition(policy.ConditionStringNotEquals, conditionkeys.S3XAmzServerSideEncryption, algorithm)
+	}
+	return stmt
+}
@@ -0,0 +1,34 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package s3policy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestBucketPolicyStatements(t *testing.T) {
+	p := policy.NewPolicy()
+	p.Append(
+		DenyInsecureTransport("bucket"),
+		DenyOutsideVpcEndpoints("bucket", "vpce-1a2b3c4d"),
+		DenyUnencryptedUploads("bucket", ""),
+		DenyUnencryptedUploads("bucket", "aws:kms"),
+	)
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"DenyInsecureTransport","Effect":"Deny","Principal":{"AWS":["*"]},"Action":["s3:*"],"Resource":["arn:aws:s3:::bucket","arn:aws:s3:::bucket/*"],"Condition":{"Bool":{"aws:SecureTransport":["false"]}}},` +
+		`{"Sid":"DenyOutsideVpcEndpoints","Effect":"Deny","Principal":{"AWS":["*"]},"Action":["s3:*"],"Resource":["arn:aws:s3:::bucket","arn:aws:s3:::bucket/*"],"Condition":{"StringNotEquals":{"aws:SourceVpce":["vpce-1a2b3c4d"]}}},` +
+		`{"Sid":"DenyUnencryptedUploads","Effect":"Deny","Principal":{"AWS":["*"]},"Action":["s3:PutObject"],"Resource":["arn:aws:s3:::bucket/*"],"Condition":{"Null":{"s3:x-amz-server-side-encryption":["true"]}}},` +
+		`{"Sid":"DenyUnencryptedUploads","Effect":"Deny","Principal":{"AWS":["*"]},"Action":["s3:PutObject"],"Resource":["arn:aws:s3:::bucket/*"],"Condition":{"StringNotEquals":{"s3:x-amz-server-side-encryption":["aws:kms"]}}}]}`
+
+	assertPolicy(t, p, expected)
+	if err := p.Validate(); err != nil {
+		t.Errorf("Expected valid statements got %s", err)
+	}
+}
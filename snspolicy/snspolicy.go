@@ -0,0 +1,75 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package snspolicy provides helpers to generate SNS topic policy statements
+package snspolicy
+
+import (
+	"fmt"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// S3ServicePrincipal is the service principal S3 publishes event
+// notifications as
+const S3ServicePrincipal = "s3.amazonaws.com"
+
+// TopicArn returns the ARN of an SNS topic
+func TopicArn(region, account, topic string) string {
+	return fmt.Sprintf("arn:aws:sns:%s:%s:%s", region, account, topic)
+}
+
+func accountRoot(account string) string {
+	return fmt.Sprintf("arn:aws:iam::%s:root", account)
+}
+
+// AllowS3Notifications returns a topic policy statement allowing S3 to
+// publish event notifications of the bucket to the topic. Bucket names are
+// global and can be taken over when deleted, so the bucket owner's account is
+// required as well.
+func AllowS3Notifications(topicArn, bucket, bucketAccount string) *policy.Statement {
+	return policy.NewStatement(
+		policy.WithSid("AllowS3Notifications"),
+		policy.WithEffect(policy.Allow),
+		policy.WithServicePrincipals(S3ServicePrincipal),
+		policy.WithActions(actions.SNSPublish),
+		policy.WithResources(topicArn),
+		policy.WithCondition(policy.ConditionArnLike, policy.VarSourceArn, "arn:aws:s3:::"+bucket),
+		policy.WithCondition(policy.ConditionStringEquals, policy.VarSourceAccount, bucketAccount),
+	)
+}
+
+// AllowPublish returns a topic policy statement allowing principals of the
+// account to publish to the topic
+func AllowPublish(topicArn, account string) *policy.Statement {
+	return policy.NewStatement(
+		policy.WithSid("AllowPublish"),
+		policy.WithEffect(policy.Allow),
+		policy.WithPrincipals(accountRoot(account)),
+		policy.WithActions(actions.SNSPublish),
+		policy.WithResources(topicArn),
+	)
+}
+
+// AllowSubscribe returns a topic policy statement allowing principals of the
+// account to subscribe to the topic. When protocols are given, e.g. "sqs",
+// only subscriptions using those protocols are allowed.
+func AllowSubscribe(topicArn, account string, protocols ...string) *policy.Statement {
+	stmt := policy.NewStatement(
+		policy.WithSid("AllowSubscribe"),
+		policy.WithEffect(policy.Allow),
+		policy.WithPrincipals(accountRoot(account)),
+		policy.WithActions(actions.SNSSubscribe),
+		policy.WithResources(topicArn),
+	)
+	if len(protocols) > 0 {
+		stmt.AddConditionValues(policy.ConditionStringEquals, conditionkeys.SNSProtocol, protocols...)
+	}
+	return stmt
+}
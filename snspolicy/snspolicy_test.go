@@ -0,0 +1,39 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package snspolicy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestTopicPolicy(t *testing.T) {
+	topic := TopicArn("eu-west-1", "111122223333", "uploads")
+	p := policy.NewPolicy()
+	p.Append(
+		AllowS3Notifications(topic, "uploads", "111122223333"),
+		AllowPublish(topic, "444455556666"),
+		AllowSubscribe(topic, "444455556666", "sqs", "lambda"),
+	)
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"AllowS3Notifications","Effect":"Allow","Principal":{"Service":["s3.amazonaws.com"]},"Action":["sns:Publish"],"Resource":["arn:aws:sns:eu-west-1:111122223333:uploads"],"Condition":{"ArnLike":{"aws:SourceArn":["arn:aws:s3:::uploads"]},"StringEquals":{"aws:SourceAccount":["111122223333"]}}},` +
+		`{"Sid":"AllowPublish","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::444455556666:root"]},"Action":["sns:Publish"],"Resource":["arn:aws:sns:eu-west-1:111122223333:uploads"]},` +
+		`{"Sid":"AllowSubscribe","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::444455556666:root"]},"Action":["sns:Subscribe"],"Resource":["arn:aws:sns:eu-west-1:111122223333:uploads"],"Condition":{"StringEquals":{"sns:Protocol":["sqs","lambda"]}}}]}`
+
+	got, err := p.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Expected a valid policy got %s", err)
+	}
+}
@@ -0,0 +1,56 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package sqspolicy provides helpers to generate SQS queue policy statements
+// allowing AWS services to deliver messages to a queue
+package sqspolicy
+
+import (
+	"fmt"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// Service principals of the services delivering messages to queues
+const (
+	SNSServicePrincipal         = "sns.amazonaws.com"
+	EventBridgeServicePrincipal = "events.amazonaws.com"
+)
+
+// QueueArn returns the ARN of an SQS queue
+func QueueArn(region, account, queue string) string {
+	return fmt.Sprintf("arn:aws:sqs:%s:%s:%s", region, account, queue)
+}
+
+// sendFrom returns a statement allowing the service to send messages to the
+// queue on behalf of the given sources only. Without the aws:SourceArn
+// condition any topic or rule, in any account, could send to the queue, so
+// the helpers take at least one source.
+func sendFrom(sid, service, queueArn string, sourceArns []string) *policy.Statement {
+	stmt := policy.NewStatement(
+		policy.WithSid(sid),
+		policy.WithEffect(policy.Allow),
+		policy.WithServicePrincipals(service),
+		policy.WithActions(actions.SQSSendMessage),
+		policy.WithResources(queueArn),
+	)
+	stmt.AddConditionValues(policy.ConditionArnEquals, policy.VarSourceArn, sourceArns...)
+	return stmt
+}
+
+// AllowSNSTopics returns a queue policy statement allowing the SNS topics to
+// deliver messages to the queue
+func AllowSNSTopics(queueArn, topicArn string, topicArns ...string) *policy.Statement {
+	return sendFrom("AllowSNSTopics", SNSServicePrincipal, queueArn, append([]string{topicArn}, topicArns...))
+}
+
+// AllowEventBridgeRules returns a queue policy statement allowing the
+// EventBridge rules to send events to the queue
+func AllowEventBridgeRules(queueArn, ruleArn string, ruleArns ...string) *policy.Statement {
+	return sendFrom("AllowEventBridgeRules", EventBridgeServicePrincipal, queueArn, append([]string{ruleArn}, ruleArns...))
+}
@@ -0,0 +1,37 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package sqspolicy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestQueuePolicy(t *testing.T) {
+	queue := QueueArn("eu-west-1", "111122223333", "orders")
+	p := policy.NewPolicy()
+	p.Append(
+		AllowSNSTopics(queue, "arn:aws:sns:eu-west-1:111122223333:orders"),
+		AllowEventBridgeRules(queue, "arn:aws:events:eu-west-1:111122223333:rule/orders", "arn:aws:events:eu-west-1:111122223333:rule/refunds"),
+	)
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"AllowSNSTopics","Effect":"Allow","Principal":{"Service":["sns.amazonaws.com"]},"Action":["sqs:SendMessage"],"Resource":["arn:aws:sqs:eu-west-1:111122223333:orders"],"Condition":{"ArnEquals":{"aws:SourceArn":["arn:aws:sns:eu-west-1:111122223333:orders"]}}},` +
+		`{"Sid":"AllowEventBridgeRules","Effect":"Allow","Principal":{"Service":["events.amazonaws.com"]},"Action":["sqs:SendMessage"],"Resource":["arn:aws:sqs:eu-west-1:111122223333:orders"],"Condition":{"ArnEquals":{"aws:SourceArn":["arn:aws:events:eu-west-1:111122223333:rule/orders","arn:aws:events:eu-west-1:111122223333:rule/refunds"]}}}]}`
+
+	got, err := p.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Expected a valid policy got %s", err)
+	}
+}
@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package stspolicy
+
+import (
+	"fmt"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+// The maximum number of managed policy ARNs STS accepts as session policies
+const MaxSessionPolicyArns = 10
+
+// Error when more managed session policies are given than STS accepts
+type TooManyPolicyArnsError int
+
+func (e TooManyPolicyArnsError) Error() string {
+	return fmt.Sprintf("%d managed session policies given, at most %d are allowed", int(e), MaxSessionPolicyArns)
+}
+
+// SessionPolicy is passed to AssumeRole, AssumeRoleWithSAML,
+// AssumeRoleWithWebIdentity or GetFederationToken to limit the permissions of
+// the temporary credentials. The session can do what both the role (or
+// federated user) policies and the session policies allow, session policies
+// never grant more. Resource-based policies naming the session ARN are not
+// limited by session policies.
+type SessionPolicy struct {
+	// The inline session policy, passed as the Policy parameter
+	*policy.Policy
+	// ARNs of managed policies, passed as the PolicyArns parameter
+	PolicyArns []string
+}
+
+// Create a new empty session policy
+func NewSessionPolicy() *SessionPolicy {
+	return &SessionPolicy{Policy: policy.NewPolicy()}
+}
+
+// Create a session policy from the JSON inline policy, the policy is
+// validated
+func LoadSessionPolicy(b []byte) (*SessionPolicy, error) {
+	p, err := policy.LoadPolicy(b)
+	if err != nil {
+		return nil, err
+	}
+	s := &SessionPolicy{Policy: p}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add a new (empty) Statement to the inline policy, returns the new Statement
+func (s *SessionPolicy) AddStatement() *policy.Statement {
+	return s.Policy.AddIdentityStatement()
+}
+
+// AddPolicyArn adds a managed policy to the session policies
+func (s *SessionPolicy) AddPolicyArn(arn string) {
+	s.PolicyArns = append(s.PolicyArns, arn)
+}
+
+// Validate checks that the inline policy only contains identity statements,
+// does not exceed the inline session policy size limit, and that no more
+// managed policies are given than STS accepts
+func (s *SessionPolicy) Validate() error {
+	if len(s.PolicyArns) > MaxSessionPolicyArns {
+		return TooManyPolicyArnsError(len(s.PolicyArns))
+	}
+	for _, stmt := range s.Statement {
+		if stmt.Kind != policy.IdentityStatement {
+			return policy.InvalidStatementError("statement is not a session policy statement")
+		}
+	}
+	if err := s.Policy.Validate(); err != nil {
+		return err
+	}
+	_, err := s.Policy.GetWithLimits(policy.InlineSessionPolicy)
+	return err
+}
+
+// Retrieve the inline policy as a JSON encoded string for the Policy
+// parameter, returns an error if the session policy is not valid
+func (s *SessionPolicy) Get() ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s.Policy.Get()
+}
+
+// EffectivePermissions returns what the session can do given the policies of
+// the role or federated user, see policy.Intersect. Managed session policies
+// are not taken into account, only the inline policy is.
+func (s *SessionPolicy) EffectivePermissions(role *policy.Policy) *policy.Policy {
+	return policy.Intersect(role, s.Policy)
+}
@@ -0,0 +1,71 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package stspolicy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestSessionPolicy(t *testing.T) {
+	s := NewSessionPolicy()
+	stmt := s.AddStatement()
+	stmt.Effect = policy.Allow
+	stmt.AddAction(actions.S3GetObject)
+	stmt.AddResource("arn:aws:s3:::reports/2026/*")
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::reports/2026/*"]}]}`
+
+	got, err := s.Get()
+	if err != nil {
+		t.Fatalf("Failed getting session policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+
+	role, err := policy.LoadPolicy([]byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::reports/*"}]}`))
+	if err != nil {
+		t.Fatalf("Failed loading role policy: %s", err)
+	}
+	got, err = s.EffectivePermissions(role).Get()
+	if err != nil {
+		t.Fatalf("Failed getting effective permissions: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+
+	for i := 0; i <= MaxSessionPolicyArns; i++ {
+		s.AddPolicyArn(fmt.Sprintf("arn:aws:iam::111122223333:policy/session-%d", i))
+	}
+	if _, err := s.Get(); err != TooManyPolicyArnsError(11) {
+		t.Errorf("Expected TooManyPolicyArnsError got %v", err)
+	}
+	s.PolicyArns = nil
+
+	for i := 0; i < 50; i++ {
+		stmt.AddResource(fmt.Sprintf("arn:aws:s3:::reports/%s/*", strings.Repeat("x", 40)))
+	}
+	var sizeErr *policy.PolicySizeError
+	if _, err := s.Get(); !errors.As(err, &sizeErr) || sizeErr.Limit != 2048 {
+		t.Errorf("Expected PolicySizeError got %v", err)
+	}
+}
+
+func TestLoadSessionPolicy(t *testing.T) {
+	_, err := LoadSessionPolicy([]byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:*","Resource":"*"}]}`))
+	var stmtErr policy.InvalidStatementError
+	if !errors.As(err, &stmtErr) {
+		t.Errorf("Expected InvalidStatementError got %v", err)
+	}
+}
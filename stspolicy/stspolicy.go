@@ -0,0 +1,204 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package stspolicy provides helpers to restrict role trust policies by the
+// external id, role session name and source identity passed to STS
+package stspolicy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// Error for an STS condition value that can never match or weakens the
+// restriction it appears to make
+type ConditionError struct {
+	Key    policy.ConditionVariable
+	Value  string
+	Reason string
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("Condition on %s %q: %s", e.Key, e.Value, e.Reason)
+}
+
+// ErrNoSourceIdentity is reported by RequireSourceIdentity for a statement
+// that lets people assume the role without setting a source identity
+var ErrNoSourceIdentity = errors.New("statement allows assuming the role without a source identity")
+
+// valueRule describes the values STS accepts for a key
+type valueRule struct {
+	chars    *regexp.Regexp
+	min, max int
+	// Whether values may be patterns with wildcards and policy variables
+	patterns bool
+}
+
+var (
+	externalIdRule = valueRule{regexp.MustCompile(`^[\w+=,.@:/-]*$`), 2, 1224, false}
+	sessionRule    = valueRule{regexp.MustCompile(`^[\w+=,.@-]*$`), 2, 64, true}
+	// Policy variables and wildcards, removed before checking a pattern
+	variablePattern = regexp.MustCompile(`\$\{[^}]*\}|[*?]`)
+)
+
+// check returns why the value is not accepted, or an empty string if it is.
+// Only the characters of patterns are checked, not their length.
+func (r valueRule) check(value string) string {
+	literal := variablePattern.ReplaceAllString(value, "")
+	switch {
+	case literal != value && !r.patterns:
+		return "must not contain wildcards or policy variables"
+	case !r.chars.MatchString(literal):
+		return "contains characters STS does not accept"
+	case literal == value && (len(value) < r.min || len(value) > r.max):
+		return fmt.Sprintf("must be %d to %d characters long", r.min, r.max)
+	}
+	return ""
+}
+
+// AddExternalId restricts the trust statement to requests passing one of the
+// external ids, protecting against the confused deputy problem when a third
+// party assumes the role
+func AddExternalId(stmt *policy.Statement, ids ...string) {
+	stmt.AddConditionValues(policy.ConditionStringEquals, conditionkeys.STSExternalId, ids...)
+}
+
+// AddRoleSessionName restricts the role session names, e.g. to
+// policy.Var(policy.VarUsername) to make sessions traceable to the user that
+// assumed the role. Patterns may contain wildcards.
+func AddRoleSessionName(stmt *policy.Statement, patterns ...string) {
+	stmt.AddConditionValues(policy.ConditionStringLike, conditionkeys.STSRoleSessionName, patterns...)
+}
+
+// AddSourceIdentity requires a source identity matching one of the patterns
+// and allows setting it, which AWS requires for the role to be assumed with
+// a source identity
+func AddSourceIdentity(stmt *policy.Statement, patterns ...string) {
+	if !contains(stmt.Action, actions.STSSetSourceIdentity) {
+		stmt.AddAction(actions.STSSetSourceIdentity)
+	}
+	stmt.AddConditionValues(policy.ConditionStringLike, conditionkeys.STSSourceIdentity, patterns...)
+}
+
+// Validate checks the STS conditions in the trust policy. External ids must
+// be compared exactly, a wildcard lets anyone guessing the pattern assume
+// the role. Values must be of valid length and only use characters STS
+// accepts, policy variables and wildcards excepted. Statements requiring a
+// source identity must allow sts:SetSourceIdentity. Returns nil or
+// policy.ValidationErrors.
+func Validate(trust *policy.Policy) error {
+	errs := make(policy.ValidationErrors, 0)
+	for _, stmt := range trust.Statement {
+		errs = append(errs, validateStatement(stmt)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateStatement(stmt *policy.Statement) []error {
+	errs := make([]error, 0)
+	check := func(key policy.ConditionVariable, rule valueRule) {
+		for _, t := range conditionTypes(stmt, key) {
+			for k, values := range stmt.Condition[t] {
+				if !strings.EqualFold(string(k), string(key)) {
+					continue
+				}
+				for _, value := range values {
+					if reason := rule.check(value); reason != "" {
+						errs = append(errs, &ConditionError{key, value, reason})
+					}
+				}
+			}
+		}
+	}
+
+	for _, t := range conditionTypes(stmt, conditionkeys.STSExternalId) {
+		// IfExists would let requests without an external id through
+		if t.Operator() != policy.ConditionStringEquals || t.IsIfExists() {
+			errs = append(errs, &ConditionError{conditionkeys.STSExternalId, string(t), "must be compared with StringEquals"})
+		}
+	}
+	check(conditionkeys.STSExternalId, externalIdRule)
+	check(conditionkeys.STSRoleSessionName, sessionRule)
+	check(conditionkeys.STSSourceIdentity, sessionRule)
+
+	if stmt.Effect == policy.Allow && len(conditionTypes(stmt, conditionkeys.STSSourceIdentity)) > 0 && !allows(stmt, actions.STSSetSourceIdentity) {
+		errs = append(errs, &ConditionError{conditionkeys.STSSourceIdentity, "", "statement does not allow sts:SetSourceIdentity"})
+	}
+	return errs
+}
+
+// RequireSourceIdentity checks that people can only assume a human-access
+// role with a source identity, so their actions remain traceable to them
+// across role chaining. Every Allow statement trusting AWS or federated
+// principals must restrict sts:SourceIdentity. Returns nil or
+// policy.ValidationErrors.
+func RequireSourceIdentity(trust *policy.Policy) error {
+	errs := make(policy.ValidationErrors, 0)
+	for i, stmt := range trust.Statement {
+		if stmt.Effect != policy.Allow || stmt.Principal == nil {
+			continue
+		}
+		if len(stmt.Principal.Aws)+len(stmt.Principal.Federated) == 0 {
+			continue
+		}
+		if len(conditionTypes(stmt, conditionkeys.STSSourceIdentity)) == 0 {
+			errs = append(errs, fmt.Errorf("Statement[%d]: %w", i, ErrNoSourceIdentity))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// conditionTypes returns the condition types that compare key, excluding
+// Null checks
+func conditionTypes(stmt *policy.Statement, key policy.ConditionVariable) []policy.ConditionType {
+	result := make([]policy.ConditionType, 0)
+	for t, keys := range stmt.Condition {
+		if t.Operator() == policy.ConditionNull {
+			continue
+		}
+		for k := range keys {
+			if strings.EqualFold(string(k), string(key)) {
+				result = append(result, t)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// allows reports whether the statement's Action list grants the action
+func allows(stmt *policy.Statement, action string) bool {
+	service, _, _ := strings.Cut(action, ":")
+	for _, a := range stmt.Action {
+		if a == "*" || strings.EqualFold(a, service+":*") || strings.EqualFold(a, action) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package stspolicy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestTrustConditions(t *testing.T) {
+	p := policy.NewPolicy()
+	stmt := p.AddStatement()
+	stmt.Effect = policy.Allow
+	stmt.AddPrincipal("arn:aws:iam::111122223333:root")
+	stmt.AddAction(actions.STSAssumeRole)
+	AddExternalId(stmt, "c0ffee-42")
+	AddRoleSessionName(stmt, policy.Var(policy.VarUsername))
+	AddSourceIdentity(stmt, policy.Var(policy.VarUsername))
+
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::111122223333:root"]},"Action":["sts:AssumeRole","sts:SetSourceIdentity"],"Condition":{"StringEquals":{"sts:ExternalId":["c0ffee-42"]},"StringLike":{"sts:RoleSessionName":["${aws:username}"],"sts:SourceIdentity":["${aws:username}"]}}}]}`
+	got, err := p.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+	if err := Validate(p); err != nil {
+		t.Errorf("Expected no error got %s", err)
+	}
+	if err := RequireSourceIdentity(p); err != nil {
+		t.Errorf("Expected no error got %s", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p := policy.NewPolicy()
+	stmt := p.AddStatement()
+	stmt.Effect = policy.Allow
+	stmt.AddPrincipal("arn:aws:iam::111122223333:root")
+	stmt.AddAction(actions.STSAssumeRole)
+	stmt.AddCondition(policy.ConditionStringLike, "sts:ExternalId", "partner-*")
+	stmt.AddCondition(policy.ConditionStringLike, "sts:RoleSessionName", "user name")
+	stmt.AddCondition(policy.ConditionStringEquals, "sts:SourceIdentity", "a")
+
+	expected := []string{
+		`Condition on sts:ExternalId "StringLike": must be compared with StringEquals`,
+		`Condition on sts:ExternalId "partner-*": must not contain wildcards or policy variables`,
+		`Condition on sts:RoleSessionName "user name": contains characters STS does not accept`,
+		`Condition on sts:SourceIdentity "a": must be 2 to 64 characters long`,
+		`Condition on sts:SourceIdentity "": statement does not allow sts:SetSourceIdentity`,
+	}
+	var errs policy.ValidationErrors
+	if !errors.As(Validate(p), &errs) || len(errs) != len(expected) {
+		t.Fatalf("Expected %v got %v", expected, errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected %s got %s", expected[i], err)
+		}
+	}
+}
+
+func TestRequireSourceIdentity(t *testing.T) {
+	p := policy.NewPolicy()
+	service := p.AddStatement()
+	service.Effect = policy.Allow
+	service.AddServicePrincipal("ec2.amazonaws.com")
+	service.AddAction(actions.STSAssumeRole)
+	human := p.AddStatement()
+	human.Effect = policy.Allow
+	human.AddPrincipal("arn:aws:iam::111122223333:root")
+	human.AddAction(actions.STSAssumeRole)
+
+	var errs policy.ValidationErrors
+	if !errors.As(RequireSourceIdentity(p), &errs) || len(errs) != 1 || !errors.Is(errs[0], ErrNoSourceIdentity) {
+		t.Fatalf("Expected ErrNoSourceIdentity for statement 1 got %v", errs)
+	}
+	if errs[0].Error() != "Statement[1]: statement allows assuming the role without a source identity" {
+		t.Errorf("Unexpected error %s", errs[0])
+	}
+}
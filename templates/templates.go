@@ -0,0 +1,77 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package templates provides least-privilege identity statements for common
+// use cases, to compose policies from, e.g.
+//
+//	p := policy.NewPolicy()
+//	p.Append(templates.S3ReadOnly("reports"), templates.KMSDecryptOnly(keyArn))
+//
+// The statements have no Sid, use Policy.EnsureSids to assign them.
+package templates
+
+import (
+	"fmt"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// LogGroupArn returns the ARN of a CloudWatch Logs log group
+func LogGroupArn(region, account, logGroup string) string {
+	return fmt.Sprintf("arn:aws:logs:%s:%s:log-group:%s", region, account, logGroup)
+}
+
+// S3ReadOnly allows listing the bucket and reading its objects
+func S3ReadOnly(bucket string) *policy.Statement {
+	return policy.NewStatement(
+		policy.WithEffect(policy.Allow),
+		policy.WithActions(actions.S3GetBucketLocation, actions.S3ListBucket, actions.S3GetObject),
+		policy.WithResources("arn:aws:s3:::"+bucket, "arn:aws:s3:::"+bucket+"/*"),
+	)
+}
+
+// DynamoDBTableCRUD allows creating, reading, updating and deleting items in
+// the table and querying its indexes. Scans and changes to the table itself
+// are not allowed.
+func DynamoDBTableCRUD(tableArn string) *policy.Statement {
+	return policy.NewStatement(
+		policy.WithEffect(policy.Allow),
+		policy.WithActions(
+			actions.DynamoDBGetItem, actions.DynamoDBBatchGetItem, actions.DynamoDBQuery,
+			actions.DynamoDBPutItem, actions.DynamoDBUpdateItem, actions.DynamoDBDeleteItem,
+			actions.DynamoDBBatchWriteItem, actions.DynamoDBConditionCheckItem,
+		),
+		policy.WithResources(tableArn, tableArn+"/index/*"),
+	)
+}
+
+// KMSDecryptOnly allows decrypting with the key. When services are given,
+// e.g. "s3.eu-west-1.amazonaws.com", the key may only be used through those
+// services.
+func KMSDecryptOnly(keyArn string, viaServices ...string) *policy.Statement {
+	stmt := policy.NewStatement(
+		policy.WithEffect(policy.Allow),
+		policy.WithActions(actions.KMSDecrypt),
+		policy.WithResources(keyArn),
+	)
+	if len(viaServices) > 0 {
+		stmt.AddConditionValues(policy.ConditionStringEquals, conditionkeys.KMSViaService, viaServices...)
+	}
+	return stmt
+}
+
+// LogsWrite allows writing log events to new and existing streams of the log
+// group. The log group itself must already exist.
+func LogsWrite(logGroupArn string) *policy.Statement {
+	return policy.NewStatement(
+		policy.WithEffect(policy.Allow),
+		policy.WithActions(actions.LogsCreateLogStream, actions.LogsPutLogEvents),
+		policy.WithResources(logGroupArn+":*"),
+	)
+}
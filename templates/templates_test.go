@@ -0,0 +1,51 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package templates
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+func TestTemplates(t *testing.T) {
+	tests := []struct {
+		stmt     *policy.Statement
+		expected string
+	}{
+		{
+			S3ReadOnly("reports"),
+			`{"Effect":"Allow","Action":["s3:GetBucketLocation","s3:ListBucket","s3:GetObject"],"Resource":["arn:aws:s3:::reports","arn:aws:s3:::reports/*"]}`,
+		},
+		{
+			DynamoDBTableCRUD("arn:aws:dynamodb:eu-west-1:111122223333:table/Orders"),
+			`{"Effect":"Allow","Action":["dynamodb:GetItem","dynamodb:BatchGetItem","dynamodb:Query","dynamodb:PutItem","dynamodb:UpdateItem","dynamodb:DeleteItem","dynamodb:BatchWriteItem","dynamodb:ConditionCheckItem"],"Resource":["arn:aws:dynamodb:eu-west-1:111122223333:table/Orders","arn:aws:dynamodb:eu-west-1:111122223333:table/Orders/index/*"]}`,
+		},
+		{
+			KMSDecryptOnly("arn:aws:kms:eu-west-1:111122223333:key/1234", "s3.eu-west-1.amazonaws.com"),
+			`{"Effect":"Allow","Action":["kms:Decrypt"],"Resource":["arn:aws:kms:eu-west-1:111122223333:key/1234"],"Condition":{"StringEquals":{"kms:ViaService":["s3.eu-west-1.amazonaws.com"]}}}`,
+		},
+		{
+			LogsWrite(LogGroupArn("eu-west-1", "111122223333", "/app/api")),
+			`{"Effect":"Allow","Action":["logs:CreateLogStream","logs:PutLogEvents"],"Resource":["arn:aws:logs:eu-west-1:111122223333:log-group:/app/api:*"]}`,
+		},
+	}
+
+	for _, test := range tests {
+		if err := test.stmt.Validate(); err != nil {
+			t.Errorf("Expected a valid statement got %s", err)
+		}
+		got, err := test.stmt.MarshalJSON()
+		if err != nil {
+			t.Fatalf("Failed marshaling statement: %s", err)
+		}
+		if string(got) != test.expected {
+			t.Errorf("Expected \n%s got \n%s", test.expected, got)
+		}
+	}
+}
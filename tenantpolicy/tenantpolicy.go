@@ -0,0 +1,269 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+// Package tenantpolicy generates and verifies statements that isolate tenants
+// sharing the same resources, partitioned by S3 key prefix, DynamoDB leading
+// key or resource tag
+package tenantpolicy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gwkunze/goiam/actions"
+	"github.com/gwkunze/goiam/conditionkeys"
+	"github.com/gwkunze/goiam/dynamodbpolicy"
+	"github.com/gwkunze/goiam/policy"
+)
+
+// Validation error when a tenant id can not be used to scope resources
+type InvalidTenantError string
+
+func (e InvalidTenantError) Error() string {
+	return fmt.Sprintf("Invalid tenant id %q", string(e))
+}
+
+// ErrNoPartitions is returned when a Scheme partitions no resources
+var ErrNoPartitions = errors.New("Scheme has no bucket, table or tag key")
+
+// The placeholder in Scheme.Prefix replaced by the tenant id
+const TenantPlaceholder = "{tenant}"
+
+// Scheme describes how resources shared by tenants are partitioned. Any
+// combination of partitions can be used.
+type Scheme struct {
+	// Name of the S3 bucket whose objects are partitioned by key prefix
+	Bucket string
+	// Key prefix of a tenant's objects, TenantPlaceholder is replaced by the
+	// tenant id. Defaults to "{tenant}/".
+	Prefix string
+	// ARN of the DynamoDB table whose items are partitioned by partition key,
+	// which must equal the tenant id
+	Table string
+	// Key of the tag holding the owning tenant id on all other resources
+	TagKey string
+	// Actions allowed on resources tagged with the tenant id
+	TagActions []string
+}
+
+// Violation is a statement that grants access outside a tenant's partition
+type Violation struct {
+	// Index of the statement in the policy
+	Statement int
+	Resource  string
+	Reason    string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("Statement[%d]: %s: %s", v.Statement, v.Resource, v.Reason)
+}
+
+func (s Scheme) bucketArn() string {
+	return "arn:aws:s3:::" + s.Bucket
+}
+
+func (s Scheme) prefix(tenant string) string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = TenantPlaceholder + "/"
+	}
+	return strings.ReplaceAll(prefix, TenantPlaceholder, tenant)
+}
+
+func validTenant(tenant string) error {
+	if tenant == "" || strings.ContainsAny(tenant, "*?/$") {
+		return InvalidTenantError(tenant)
+	}
+	return nil
+}
+
+// Statements returns identity statements granting the tenant access to its
+// own partition of each resource in the scheme
+func (s Scheme) Statements(tenant string) ([]*policy.Statement, error) {
+	if err := validTenant(tenant); err != nil {
+		return nil, err
+	}
+	if s.Bucket == "" && s.Table == "" && s.TagKey == "" {
+		return nil, ErrNoPartitions
+	}
+
+	result := make([]*policy.Statement, 0)
+	if s.Bucket != "" {
+		prefix := s.prefix(tenant)
+		result = append(result, policy.NewStatement(
+			policy.WithSid("TenantObjects"),
+			policy.WithEffect(policy.Allow),
+			policy.WithActions(actions.S3GetObject, actions.S3PutObject, actions.S3DeleteObject),
+			policy.WithResources(s.bucketArn()+"/"+prefix+"*"),
+		), policy.NewStatement(
+			policy.WithSid("TenantListing"),
+			policy.WithEffect(policy.Allow),
+			policy.WithActions(actions.S3ListBucket),
+			policy.WithResources(s.bucketArn()),
+			policy.WithCondition(policy.ConditionStringLike, conditionkeys.S3Prefix, prefix+"*"),
+		))
+	}
+	if s.Table != "" {
+		result = append(result, policy.NewStatement(
+			policy.WithSid("TenantItems"),
+			policy.WithEffect(policy.Allow),
+			policy.WithActions(
+				actions.DynamoDBGetItem, actions.DynamoDBBatchGetItem, actions.DynamoDBQuery,
+				actions.DynamoDBPutItem, actions.DynamoDBUpdateItem, actions.DynamoDBDeleteItem,
+				actions.DynamoDBBatchWriteItem,
+			),
+			policy.WithResources(s.Table, s.Table+"/index/*"),
+			policy.WithCondition(policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues), conditionkeys.DynamoDBLeadingKeys, tenant),
+		))
+	}
+	if s.TagKey != "" && len(s.TagActions) > 0 {
+		result = append(result, policy.NewStatement(
+			policy.WithSid("TenantTaggedResources"),
+			policy.WithEffect(policy.Allow),
+			policy.WithActions(s.TagActions...),
+			policy.WithResources("*"),
+			policy.WithCondition(policy.ConditionStringEquals, policy.ResourceTag(s.TagKey), tenant),
+		))
+	}
+	return result, nil
+}
+
+// Verify checks that no Allow statement in the policy grants the tenant access
+// outside its own partitions. Objects in the bucket must be under the
+// tenant's prefix, listing the bucket must be restricted to that prefix and
+// access to the table to the tenant's leading key. When the scheme has a tag
+// key any other resource must be restricted to those tagged with the tenant
+// id, a tag does not separate the objects or items of tenants so it does not
+// exempt the bucket and the table. Wildcard resources are checked
+// conservatively, a pattern that could match another tenant's resource is
+// reported.
+func (s Scheme) Verify(tenant string, p *policy.Policy) []Violation {
+	result := make([]Violation, 0)
+	for i, stmt := range p.Statement {
+		if stmt.Effect != policy.Allow {
+			continue
+		}
+		for _, resource := range stmt.NotResource {
+			result = append(result, Violation{i, resource, "NotResource grants access to all other resources"})
+		}
+		tagged := s.TagKey != "" && restrictedTo(stmt, policy.ConditionStringEquals, policy.ResourceTag(s.TagKey), tenant)
+		for _, resource := range stmt.ResourceList() {
+			if reason := s.check(tenant, stmt, resource, tagged); reason != "" {
+				result = append(result, Violation{i, resource, reason})
+			}
+		}
+	}
+	return result
+}
+
+// check returns why access to resource crosses the tenant boundary, or an
+// empty string if it doesn't. Tagged tells whether the statement is
+// restricted to resources tagged with the tenant id.
+func (s Scheme) check(tenant string, stmt *policy.Statement, resource string, tagged bool) string {
+	partitioned := false
+	if s.Bucket != "" && grantsService(stmt, "s3") {
+		objects := s.bucketArn() + "/"
+		if overlaps(resource, objects) {
+			partitioned = true
+			if !within(resource, objects+s.prefix(tenant)) {
+				return "grants access to objects outside the tenant prefix"
+			}
+		}
+		if match(resource, s.bucketArn()) {
+			partitioned = true
+			if !restrictedTo(stmt, policy.ConditionStringLike, conditionkeys.S3Prefix, s.prefix(tenant)) {
+				return "grants access to the bucket without restricting s3:prefix to the tenant prefix"
+			}
+		}
+	}
+	if s.Table != "" && grantsService(stmt, "dynamodb") && (match(resource, s.Table) || overlaps(resource, s.Table+"/")) {
+		partitioned = true
+		leadingKeys := policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues)
+		if !restrictedTo(stmt, leadingKeys, conditionkeys.DynamoDBLeadingKeys, tenant) {
+			return "grants access to the table without restricting dynamodb:LeadingKeys to the tenant"
+		}
+		// ForAllValues also matches requests without leading keys, such as
+		// Scan, so the condition does not restrict these actions
+		if allowed := dynamodbpolicy.AllowedUnpartitioned(stmt); len(allowed) > 0 {
+			return fmt.Sprintf("grants %s on the table, which dynamodb:LeadingKeys does not restrict", strings.Join(allowed, ", "))
+		}
+	}
+	if s.TagKey != "" && !partitioned && !tagged {
+		return fmt.Sprintf("grants access without restricting %s to the tenant", policy.ResourceTag(s.TagKey))
+	}
+	return ""
+}
+
+// grantsService reports whether the statement may allow an action of the
+// service
+func grantsService(stmt *policy.Statement, service string) bool {
+	if len(stmt.NotAction) > 0 {
+		return true
+	}
+	for _, action := range stmt.Action {
+		prefix, _, _ := strings.Cut(action, ":")
+		if policy.WildcardMatch(strings.ToLower(prefix), service) {
+			return true
+		}
+	}
+	return false
+}
+
+// restrictedTo reports whether the statement has a condition of type t on key
+// whose values all start with value. For all operators but the Like ones the
+// values must equal value.
+func restrictedTo(stmt *policy.Statement, t policy.ConditionType, key policy.ConditionVariable, value string) bool {
+	values := stmt.Condition[t][key]
+	if len(values) == 0 {
+		return false
+	}
+	for _, v := range values {
+		if t.Operator() == policy.ConditionStringLike {
+			if !within(v, value) {
+				return false
+			}
+		} else if v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// literal returns the part of the pattern before the first wildcard and
+// whether the pattern has wildcards
+func literal(pattern string) (string, bool) {
+	if i := strings.IndexAny(pattern, "*?"); i >= 0 {
+		return pattern[:i], true
+	}
+	return pattern, false
+}
+
+// within reports whether everything the pattern matches starts with prefix
+func within(pattern, prefix string) bool {
+	lit, _ := literal(pattern)
+	return strings.HasPrefix(lit, prefix)
+}
+
+// overlaps reports whether the pattern may match a value starting with prefix
+func overlaps(pattern, prefix string) bool {
+	lit, wildcard := literal(pattern)
+	if !wildcard {
+		return strings.HasPrefix(pattern, prefix)
+	}
+	return strings.HasPrefix(lit, prefix) || strings.HasPrefix(prefix, lit)
+}
+
+// match reports whether the pattern may match the value. Patterns with
+// wildcards after the literal prefix are matched conservatively.
+func match(pattern, value string) bool {
+	lit, wildcard := literal(pattern)
+	if !wildcard {
+		return pattern == value
+	}
+	return strings.HasPrefix(value, lit) && (pattern[len(lit)] == '*' || len(value) > len(lit))
+}
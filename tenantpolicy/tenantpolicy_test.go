@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2013 Gijs Kunze
+//
+// For the full copyright and license information, please view the LICENSE
+// file that was distributed with this source code.
+//
+
+package tenantpolicy
+
+import (
+	"testing"
+
+	"github.com/gwkunze/goiam/policy"
+)
+
+var scheme = Scheme{
+	Bucket:     "data",
+	Prefix:     "tenants/{tenant}/",
+	Table:      "arn:aws:dynamodb:eu-west-1:111122223333:table/Orders",
+	TagKey:     "tenant",
+	TagActions: []string{"sqs:SendMessage"},
+}
+
+func TestStatements(t *testing.T) {
+	stmts, err := scheme.Statements("acme")
+	if err != nil {
+		t.Fatalf("Failed generating statements: %s", err)
+	}
+	p := policy.NewPolicy()
+	p.Append(stmts...)
+	expected := `{"Version":"2012-10-17","Statement":[` +
+		`{"Sid":"TenantObjects","Effect":"Allow","Action":["s3:GetObject","s3:PutObject","s3:DeleteObject"],"Resource":["arn:aws:s3:::data/tenants/acme/*"]},` +
+		`{"Sid":"TenantListing","Effect":"Allow","Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::data"],"Condition":{"StringLike":{"s3:prefix":["tenants/acme/*"]}}},` +
+		`{"Sid":"TenantItems","Effect":"Allow","Action":["dynamodb:GetItem","dynamodb:BatchGetItem","dynamodb:Query","dynamodb:PutItem","dynamodb:UpdateItem","dynamodb:DeleteItem","dynamodb:BatchWriteItem"],"Resource":["arn:aws:dynamodb:eu-west-1:111122223333:table/Orders","arn:aws:dynamodb:eu-west-1:111122223333:table/Orders/index/*"],"Condition":{"ForAllValues:StringEquals":{"dynamodb:LeadingKeys":["acme"]}}},` +
+		`{"Sid":"TenantTaggedResources","Effect":"Allow","Action":["sqs:SendMessage"],"Resource":["*"],"Condition":{"StringEquals":{"aws:ResourceTag/tenant":["acme"]}}}]}`
+	got, err := p.Get()
+	if err != nil {
+		t.Fatalf("Failed marshaling policy: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected \n%s got \n%s", expected, got)
+	}
+	if violations := scheme.Verify("acme", p); len(violations) != 0 {
+		t.Errorf("Expected no violations got %v", violations)
+	}
+	if violations := scheme.Verify("other", p); len(violations) != 5 {
+		t.Errorf("Expected 5 violations for another tenant got %v", violations)
+	}
+
+	if _, err := scheme.Statements("a*"); err != InvalidTenantError("a*") {
+		t.Errorf("Expected InvalidTenantError got %v", err)
+	}
+	if _, err := (Scheme{}).Statements("acme"); err != ErrNoPartitions {
+		t.Errorf("Expected ErrNoPartitions got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		stmt     *policy.Statement
+		expected int
+	}{
+		{policy.NewStatement(policy.WithEffect(policy.Allow), policy.WithActions("s3:GetObject"), policy.WithResources("arn:aws:s3:::data/tenants/acme/reports/*")), 0},
+		{policy.NewStatement(policy.WithEffect(policy.Allow), policy.WithActions("s3:GetObject"), policy.WithResources("arn:aws:s3:::data/tenants/*")), 1},
+		{policy.NewStatement(policy.WithEffect(policy.Allow), policy.WithActions("s3:GetObject"), policy.WithResources("arn:aws:s3:::da*")), 1},
+		{policy.NewStatement(policy.WithEffect(policy.Allow), policy.WithActions("s3:ListBucket"), policy.WithResources("arn:aws:s3:::data")), 1},
+		{policy.NewStatement(policy.WithEffect(policy.Allow), policy.WithActions("dynamodb:Query"), policy.WithResources("arn:aws:dynamodb:eu-west-1:111122223333:table/Orders/index/byDate")), 1},
+		{policy.NewStatement(policy.WithEffect(policy.Allow), policy.WithActions("sqs:SendMessage"), policy.WithResources("arn:aws:sqs:eu-west-1:111122223333:queue")), 1},
+		{policy.NewStatement(policy.WithEffect(policy.Allow), policy.WithActions("s3:GetObject"), policy.WithNotResources("arn:aws:s3:::data/tenants/other/*")), 1},
+		{policy.NewStatement(policy.WithActions("s3:*"), policy.WithResources("*")), 0},
+		{policy.NewStatement(
+			policy.WithEffect(policy.Allow), policy.WithActions("dynamodb:Query"), policy.WithResources("arn:aws:dynamodb:eu-west-1:111122223333:table/Orders"),
+			policy.WithCondition(policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues), "dynamodb:LeadingKeys", "acme"),
+		), 0},
+		{policy.NewStatement(
+			policy.WithEffect(policy.Allow), policy.WithActions("dynamodb:*"), policy.WithResources("arn:aws:dynamodb:eu-west-1:111122223333:table/Orders"),
+			policy.WithCondition(policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues), "dynamodb:LeadingKeys", "acme"),
+		), 1},
+		{policy.NewStatement(
+			policy.WithEffect(policy.Allow), policy.WithActions("dynamodb:PartiQLSelect"), policy.WithResources("arn:aws:dynamodb:eu-west-1:111122223333:table/Orders"),
+			policy.WithCondition(policy.ConditionStringEquals.WithSetOperator(policy.ForAllValues), "dynamodb:LeadingKeys", "acme"),
+		), 1},
+		{policy.NewStatement(
+			policy.WithEffect(policy.Allow), policy.WithActions("sqs:SendMessage"), policy.WithResources("*"),
+			policy.WithCondition(policy.ConditionStringEquals, policy.ResourceTag("tenant"), "acme"),
+		), 0},
+		{policy.NewStatement(
+			policy.WithEffect(policy.Allow), policy.WithActions("dynamodb:*", "s3:GetObject"),
+			policy.WithResources("arn:aws:dynamodb:eu-west-1:111122223333:table/Orders", "arn:aws:s3:::data/*"),
+			policy.WithCondition(policy.ConditionStringEquals, policy.ResourceTag("tenant"), "acme"),
+		), 2},
+	}
+
+	for i, test := range tests {
+		p := policy.NewPolicy()
+		p.Append(test.stmt)
+		if violations := scheme.Verify("acme", p); len(violations) != test.expected {
+			t.Errorf("%d: Expected %d violations got %v", i, test.expected, violations)
+		}
+	}
+}